@@ -0,0 +1,173 @@
+package chaincode
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// TransactionContext is the context every contract's transactions get. It
+// caches the caller's identity and the documents read from the world state.
+// Reads within a transaction never see its own writes, so a cached document
+// is always what the stub would return.
+type TransactionContext struct {
+	contractapi.TransactionContext
+
+	stub        *cachingStub
+	caller      *Caller
+	transaction string
+}
+
+// Caller is the identity that submitted the transaction.
+type Caller struct {
+	MSPID string
+	Admin bool
+}
+
+// TransactionError wraps the errors of the transactions of the namespaced
+// contracts with the name of the transaction, such as
+// "UserContract:CreateUser".
+type TransactionError struct {
+	Transaction string
+	Err         error
+}
+
+func (e *TransactionError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Transaction, e.Err)
+}
+
+func (e *TransactionError) Unwrap() error {
+	return e.Err
+}
+
+func (c *TransactionContext) SetStub(stub shim.ChaincodeStubInterface) {
+	c.TransactionContext.SetStub(stub)
+	c.stub = &cachingStub{ChaincodeStubInterface: stub, states: make(map[string][]byte)}
+}
+
+func (c *TransactionContext) GetStub() shim.ChaincodeStubInterface {
+	return c.stub
+}
+
+// Caller reads the caller's identity once per transaction.
+func (c *TransactionContext) Caller() (*Caller, error) {
+	if c.caller != nil {
+		return c.caller, nil
+	}
+
+	caller, err := readCaller(c)
+	if err != nil {
+		return nil, err
+	}
+
+	c.caller = caller
+	return c.caller, nil
+}
+
+// readCaller reads the caller from the context's client identity.
+// contractapi ignores the error of parsing the creator and sets a nil
+// *cid.ClientID, so the creator is parsed again to return that error.
+func readCaller(ctx contractapi.TransactionContextInterface) (*Caller, error) {
+	identity := ctx.GetClientIdentity()
+	if id, ok := identity.(*cid.ClientID); ok && id == nil {
+		parsed, err := cid.New(ctx.GetStub())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the caller's identity: %v", err)
+		}
+		identity = parsed
+	}
+
+	if identity == nil {
+		return nil, fmt.Errorf("the caller's identity is unknown")
+	}
+
+	mspId, err := identity.GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the caller's msp: %v", err)
+	}
+
+	admin, err := isAdmin(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Caller{MSPID: mspId, Admin: admin}, nil
+}
+
+// Transaction is the namespaced name of the running transaction.
+func (c *TransactionContext) Transaction() string {
+	return c.transaction
+}
+
+func (c *TransactionContext) wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &TransactionError{Transaction: c.transaction, Err: err}
+}
+
+// cachingStub serves repeated reads of a key from memory.
+type cachingStub struct {
+	shim.ChaincodeStubInterface
+
+	states map[string][]byte
+}
+
+func (s *cachingStub) GetState(key string) ([]byte, error) {
+	if value, ok := s.states[key]; ok {
+		return value, nil
+	}
+
+	value, err := s.ChaincodeStubInterface.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+
+	s.states[key] = value
+	return value, nil
+}
+
+// newContract configures a contract with the shared context and hooks. Only
+// admins may call the adminOnly transactions; the transactions check it
+// again themselves, the hook just refuses before anything is read.
+func newContract(name string, adminOnly ...string) contractapi.Contract {
+	return contractapi.Contract{
+		Name:                      name,
+		TransactionContextHandler: new(TransactionContext),
+		BeforeTransaction: func(ctx *TransactionContext) error {
+			return beforeTransaction(ctx, name, adminOnly)
+		},
+		AfterTransaction: func(ctx *TransactionContext, result interface{}) error {
+			log.Printf("[%s] %s done", ctx.GetStub().GetTxID(), ctx.Transaction())
+			return nil
+		},
+		UnknownTransaction: func(ctx *TransactionContext) error {
+			return ctx.wrap(fmt.Errorf("no such transaction in contract %s", name))
+		},
+	}
+}
+
+func beforeTransaction(ctx *TransactionContext, contract string, adminOnly []string) error {
+	function, _ := ctx.GetStub().GetFunctionAndParameters()
+	function = function[strings.LastIndex(function, ":")+1:]
+	ctx.transaction = contract + ":" + function
+
+	caller, err := ctx.Caller()
+	if err != nil {
+		return ctx.wrap(err)
+	}
+
+	log.Printf("[%s] %s called by %s", ctx.GetStub().GetTxID(), ctx.Transaction(), caller.MSPID)
+
+	if slices.Contains(adminOnly, function) && !caller.Admin {
+		return ctx.wrap(fmt.Errorf("the caller is not an admin"))
+	}
+
+	return nil
+}
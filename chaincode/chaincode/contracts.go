@@ -0,0 +1,295 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"reflect"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Contracts returns the contracts of the chaincode. SmartContract is the
+// default contract, it keeps the transactions that aren't namespaced, so
+// they're still called without a contract name. The namespaced contracts
+// are called as "UserContract:CreateUser" and share the SmartContract's
+// implementation.
+func Contracts() []contractapi.ContractInterface {
+	sc := &SmartContract{Contract: newContract("SmartContract")}
+
+	return []contractapi.ContractInterface{
+		sc,
+		&UserContract{Contract: newContract("UserContract", "DepositFunds", "SetSpendingLimits", "FreezeAccount", "UnfreezeAccount"), sc: sc},
+		&ProductContract{Contract: newContract("ProductContract"), sc: sc},
+		&TraderContract{Contract: newContract("TraderContract", "SetTraderVerification", "TransferTraderOwnership", "SetTraderEndorsers", "CompactTraderDeltas"), sc: sc},
+		&ReceiptContract{Contract: newContract("ReceiptContract", "RefundReceipt", "CheckReceiptSequence"), sc: sc},
+	}
+}
+
+// GetIgnoredFunctions hides the transactions of the namespaced contracts
+// from the default contract. The namespaced contracts still call them.
+func (sc *SmartContract) GetIgnoredFunctions() []string {
+	contractInterface := reflect.TypeOf((*contractapi.ContractInterface)(nil)).Elem()
+
+	ignored := make([]string, 0)
+	for _, contract := range []any{&UserContract{}, &ProductContract{}, &TraderContract{}, &ReceiptContract{}} {
+		contractType := reflect.TypeOf(contract)
+		for i := 0; i < contractType.NumMethod(); i++ {
+			name := contractType.Method(i).Name
+			if _, ok := contractInterface.MethodByName(name); !ok {
+				ignored = append(ignored, name)
+			}
+		}
+	}
+
+	return ignored
+}
+
+// UserContract holds the transactions on users and their balances.
+type UserContract struct {
+	contractapi.Contract
+
+	sc *SmartContract
+}
+
+func (c *UserContract) CreateUser(ctx *TransactionContext, user models.User) error {
+	return ctx.wrap(c.sc.CreateUser(ctx, user))
+}
+
+func (c *UserContract) ReadUser(ctx *TransactionContext, id string) (*models.User, error) {
+	result, err := c.sc.ReadUser(ctx, id)
+	return result, ctx.wrap(err)
+}
+
+// UpdateUser validates the user here, SmartContract.UpdateUser also stores
+// the users other transactions change. Only the user's details are taken
+// from the model: the balances, receipts and movements change with the
+// user's transactions, the limits, the freeze and the status with their own.
+func (c *UserContract) UpdateUser(ctx *TransactionContext, id string, model *models.User) error {
+	if err := models.Validate(model); err != nil {
+		return ctx.wrap(err)
+	}
+
+	stored, err := c.sc.ReadUser(ctx, id)
+	if err != nil {
+		return ctx.wrap(err)
+	}
+	model.ID = stored.ID
+	model.ReceiptsID = stored.ReceiptsID
+	model.Balances = stored.Balances
+	model.LockedBalances = stored.LockedBalances
+	model.Movements = stored.Movements
+	model.Limits, model.Spending, model.Freeze = stored.Limits, stored.Spending, stored.Freeze
+	model.Lifecycle = stored.Lifecycle
+
+	return ctx.wrap(c.sc.UpdateUser(ctx, id, model))
+}
+
+func (c *UserContract) DeleteUser(ctx *TransactionContext, id string) error {
+	return ctx.wrap(c.sc.DeleteUser(ctx, id))
+}
+
+func (c *UserContract) GetAllUsers(ctx *TransactionContext) ([]*models.User, error) {
+	result, err := c.sc.GetAllUsers(ctx)
+	return result, ctx.wrap(err)
+}
+
+func (c *UserContract) GetUsersGTEBalance(ctx *TransactionContext, balance models.Money) ([]*models.User, error) {
+	result, err := c.sc.GetUsersGTEBalance(ctx, balance)
+	return result, ctx.wrap(err)
+}
+
+func (c *UserContract) DepositFunds(ctx *TransactionContext, userId string, amount models.Money) error {
+	return ctx.wrap(c.sc.DepositFunds(ctx, userId, amount))
+}
+
+func (c *UserContract) GetUserStatement(ctx *TransactionContext, userId string, currency string, from string, to string) (*models.Statement, error) {
+	result, err := c.sc.GetUserStatement(ctx, userId, currency, from, to)
+	return result, ctx.wrap(err)
+}
+
+func (c *UserContract) SetSpendingLimits(ctx *TransactionContext, userId string, limits models.SpendingLimits) error {
+	return ctx.wrap(c.sc.SetSpendingLimits(ctx, userId, limits))
+}
+
+func (c *UserContract) FreezeAccount(ctx *TransactionContext, userId string, reason string) error {
+	return ctx.wrap(c.sc.FreezeAccount(ctx, userId, reason))
+}
+
+func (c *UserContract) UnfreezeAccount(ctx *TransactionContext, userId string) error {
+	return ctx.wrap(c.sc.UnfreezeAccount(ctx, userId))
+}
+
+// ProductContract holds the transactions on products, including their sale.
+type ProductContract struct {
+	contractapi.Contract
+
+	sc *SmartContract
+}
+
+func (c *ProductContract) CreateProduct(ctx *TransactionContext, product models.Product) error {
+	return ctx.wrap(c.sc.CreateProduct(ctx, product))
+}
+
+func (c *ProductContract) CreateProducts(ctx *TransactionContext, products []models.Product, mode string) (*models.BatchResult, error) {
+	result, err := c.sc.CreateProducts(ctx, products, mode)
+	return result, ctx.wrap(err)
+}
+
+func (c *ProductContract) ReadProduct(ctx *TransactionContext, id string) (*models.Product, error) {
+	result, err := c.sc.ReadProduct(ctx, id)
+	return result, ctx.wrap(err)
+}
+
+// UpdateProduct validates the product here, SmartContract.UpdateProduct also
+// stores the products the sales and reservations change. The reservations
+// and the status are only changed by their own transactions.
+func (c *ProductContract) UpdateProduct(ctx *TransactionContext, id string, model *models.Product) error {
+	if err := models.Validate(model); err != nil {
+		return ctx.wrap(err)
+	}
+
+	stored, err := c.sc.ReadProduct(ctx, id)
+	if err != nil {
+		return ctx.wrap(err)
+	}
+	model.ID = stored.ID
+	model.Reserved, model.Reservations = stored.Reserved, stored.Reservations
+	model.Lifecycle = stored.Lifecycle
+
+	return ctx.wrap(c.sc.UpdateProduct(ctx, id, model))
+}
+
+func (c *ProductContract) UpdateProducts(ctx *TransactionContext, products []models.Product, mode string) (*models.BatchResult, error) {
+	result, err := c.sc.UpdateProducts(ctx, products, mode)
+	return result, ctx.wrap(err)
+}
+
+func (c *ProductContract) DeleteProduct(ctx *TransactionContext, id string) error {
+	return ctx.wrap(c.sc.DeleteProduct(ctx, id))
+}
+
+func (c *ProductContract) GetAllProducts(ctx *TransactionContext) ([]*models.Product, error) {
+	result, err := c.sc.GetAllProducts(ctx)
+	return result, ctx.wrap(err)
+}
+
+func (c *ProductContract) QueryProducts(ctx *TransactionContext, filters map[string]string) ([]*models.Product, error) {
+	result, err := c.sc.QueryProducts(ctx, filters)
+	return result, ctx.wrap(err)
+}
+
+func (c *ProductContract) BuyProduct(ctx *TransactionContext, productId string, userId string) error {
+	return ctx.wrap(c.sc.BuyProduct(ctx, productId, userId))
+}
+
+func (c *ProductContract) BuyProductWithCurrency(ctx *TransactionContext, productId string, userId string, currency string) error {
+	return ctx.wrap(c.sc.BuyProductWithCurrency(ctx, productId, userId, currency))
+}
+
+func (c *ProductContract) BuyProductQuantity(ctx *TransactionContext, productId string, userId string, quantity uint, currency string) error {
+	return ctx.wrap(c.sc.BuyProductQuantity(ctx, productId, userId, quantity, currency))
+}
+
+// TraderContract holds the transactions on traders.
+type TraderContract struct {
+	contractapi.Contract
+
+	sc *SmartContract
+}
+
+func (c *TraderContract) CreateTrader(ctx *TransactionContext, trader models.Trader) error {
+	return ctx.wrap(c.sc.CreateTrader(ctx, trader))
+}
+
+// ReadTrader returns the trader with its pending deltas, SmartContract.ReadTrader
+// returns the stored document the other transactions change.
+func (c *TraderContract) ReadTrader(ctx *TransactionContext, id string) (*models.Trader, error) {
+	result, err := c.sc.ReadTrader(ctx, id)
+	if err != nil {
+		return nil, ctx.wrap(err)
+	}
+
+	return result, ctx.wrap(applyTraderDeltas(ctx, result))
+}
+
+// UpdateTrader validates the trader here, SmartContract.UpdateTrader also
+// stores the product lists the product transactions change. The product
+// list is only changed by the product transactions.
+func (c *TraderContract) UpdateTrader(ctx *TransactionContext, id string, model *models.Trader) error {
+	if err := models.Validate(model); err != nil {
+		return ctx.wrap(err)
+	}
+
+	stored, err := c.sc.ReadTrader(ctx, id)
+	if err != nil {
+		return ctx.wrap(err)
+	}
+	model.Products = stored.Products
+
+	return ctx.wrap(c.sc.UpdateTrader(ctx, id, model))
+}
+
+func (c *TraderContract) DeleteTrader(ctx *TransactionContext, id string) error {
+	return ctx.wrap(c.sc.DeleteTrader(ctx, id))
+}
+
+func (c *TraderContract) GetAllTraders(ctx *TransactionContext) ([]*models.Trader, error) {
+	result, err := c.sc.GetAllTraders(ctx)
+	return result, ctx.wrap(err)
+}
+
+func (c *TraderContract) SetTraderVerification(ctx *TransactionContext, id string, status string) error {
+	return ctx.wrap(c.sc.SetTraderVerification(ctx, id, status))
+}
+
+func (c *TraderContract) TransferTraderOwnership(ctx *TransactionContext, id string, mspId string) error {
+	return ctx.wrap(c.sc.TransferTraderOwnership(ctx, id, mspId))
+}
+
+func (c *TraderContract) SetTraderEndorsers(ctx *TransactionContext, id string, endorsers []string) error {
+	return ctx.wrap(c.sc.SetTraderEndorsers(ctx, id, endorsers))
+}
+
+func (c *TraderContract) CompactTraderDeltas(ctx *TransactionContext, limit int) (*models.CompactionResult, error) {
+	result, err := c.sc.CompactTraderDeltas(ctx, limit)
+	return result, ctx.wrap(err)
+}
+
+func (c *TraderContract) GetTraderSalesReport(ctx *TransactionContext, traderId string, from string, to string) (*models.SalesReport, error) {
+	result, err := c.sc.GetTraderSalesReport(ctx, traderId, from, to)
+	return result, ctx.wrap(err)
+}
+
+// ReceiptContract holds the transactions on receipts.
+type ReceiptContract struct {
+	contractapi.Contract
+
+	sc *SmartContract
+}
+
+func (c *ReceiptContract) ReadReceipt(ctx *TransactionContext, id string) (*models.Receipt, error) {
+	result, err := c.sc.ReadReceipt(ctx, id)
+	return result, ctx.wrap(err)
+}
+
+func (c *ReceiptContract) DeleteReceipt(ctx *TransactionContext, id string) error {
+	return ctx.wrap(c.sc.DeleteReceipt(ctx, id))
+}
+
+func (c *ReceiptContract) GetAllReceips(ctx *TransactionContext) ([]*models.Receipt, error) {
+	result, err := c.sc.GetAllReceips(ctx)
+	return result, ctx.wrap(err)
+}
+
+func (c *ReceiptContract) RefundReceipt(ctx *TransactionContext, id string) error {
+	return ctx.wrap(c.sc.RefundReceipt(ctx, id))
+}
+
+func (c *ReceiptContract) GetReceiptByNumber(ctx *TransactionContext, number string) (*models.Receipt, error) {
+	result, err := c.sc.GetReceiptByNumber(ctx, number)
+	return result, ctx.wrap(err)
+}
+
+func (c *ReceiptContract) CheckReceiptSequence(ctx *TransactionContext, traderId string, year int) (*models.SequenceReport, error) {
+	result, err := c.sc.CheckReceiptSequence(ctx, traderId, year)
+	return result, ctx.wrap(err)
+}
@@ -0,0 +1,242 @@
+package chaincode
+
+import (
+	"chaincode/chaincode/mocks"
+	"chaincode/models"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTransactionContext(function string, ou string) (*TransactionContext, *mocks.ChaincodeStub, *mocks.ClientIdentity) {
+	stub := new(mocks.ChaincodeStub)
+	stub.GetFunctionAndParametersReturns(function, nil)
+	stub.GetTxIDReturns("tx1")
+
+	identity := new(mocks.ClientIdentity)
+	identity.GetMSPIDReturns("Org1MSP", nil)
+	identity.GetX509CertificateReturns(&x509.Certificate{Subject: pkix.Name{OrganizationalUnit: []string{ou}}}, nil)
+
+	ctx := new(TransactionContext)
+	ctx.SetStub(stub)
+	ctx.SetClientIdentity(identity)
+
+	return ctx, stub, identity
+}
+
+// serializedIdentity is the creator of a transaction from the given msp,
+// signed by a self-signed certificate with the given node OU.
+func serializedIdentity(t *testing.T, mspId string, ou string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "user1", OrganizationalUnit: []string{ou}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	creator, err := proto.Marshal(&msp.SerializedIdentity{Mspid: mspId, IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})})
+	require.NoError(t, err)
+
+	return creator
+}
+
+// newStatefulTransactionContext is the context of the namespaced contracts
+// over the state, called by a client from Org1MSP with the given node OU.
+// The context caches its reads, so every call needs a new one.
+func newStatefulTransactionContext(state map[string][]byte, now *time.Time, ou string) *TransactionContext {
+	_, stub := newStatefulContext(state, now)
+
+	identity := new(mocks.ClientIdentity)
+	identity.GetMSPIDReturns("Org1MSP", nil)
+	identity.GetX509CertificateReturns(&x509.Certificate{Subject: pkix.Name{OrganizationalUnit: []string{ou}}}, nil)
+
+	ctx := new(TransactionContext)
+	ctx.SetStub(stub)
+	ctx.SetClientIdentity(identity)
+
+	return ctx
+}
+
+func TestContracts(t *testing.T) {
+	_, err := contractapi.NewChaincode(Contracts()...)
+	require.NoError(t, err)
+
+	ignored := new(SmartContract).GetIgnoredFunctions()
+	require.Contains(t, ignored, "CreateUser")
+	require.Contains(t, ignored, "RefundReceipt")
+	require.NotContains(t, ignored, "GetUserSummary")
+	require.NotContains(t, ignored, "ReadHTLC")
+}
+
+func TestTransactionContext(t *testing.T) {
+	ctx, stub, identity := newTestTransactionContext("UserContract:ReadUser", "client")
+	stub.GetStateReturns([]byte(`{"id":"u1"}`), nil)
+
+	for range 2 {
+		value, err := ctx.GetStub().GetState("USER-u1")
+		require.NoError(t, err)
+		require.Equal(t, `{"id":"u1"}`, string(value))
+	}
+	require.Equal(t, 1, stub.GetStateCallCount())
+
+	for range 2 {
+		caller, err := ctx.Caller()
+		require.NoError(t, err)
+		require.Equal(t, &Caller{MSPID: "Org1MSP", Admin: false}, caller)
+	}
+	require.Equal(t, 1, identity.GetX509CertificateCallCount())
+
+	require.NoError(t, beforeTransaction(ctx, "UserContract", []string{"DepositFunds"}))
+	require.Equal(t, "UserContract:ReadUser", ctx.Transaction())
+
+	err := ctx.wrap(errors.New("the user u1 does not exist"))
+	require.EqualError(t, err, "UserContract:ReadUser: the user u1 does not exist")
+
+	var txErr *TransactionError
+	require.ErrorAs(t, err, &txErr)
+	require.Equal(t, "UserContract:ReadUser", txErr.Transaction)
+	require.NoError(t, ctx.wrap(nil))
+}
+
+func TestBeforeTransactionAdminOnly(t *testing.T) {
+	ctx, stub, _ := newTestTransactionContext("UserContract:DepositFunds", "client")
+	err := beforeTransaction(ctx, "UserContract", []string{"DepositFunds"})
+	require.EqualError(t, err, "UserContract:DepositFunds: the caller is not an admin")
+	require.Equal(t, 0, stub.GetStateCallCount())
+
+	ctx, _, _ = newTestTransactionContext("UserContract:DepositFunds", adminOU)
+	require.NoError(t, beforeTransaction(ctx, "UserContract", []string{"DepositFunds"}))
+}
+
+func TestContractsValidateArguments(t *testing.T) {
+	cc, err := contractapi.NewChaincode(Contracts()...)
+	require.NoError(t, err)
+
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+	putTestModel(t, state, models.Trader{ID: "TRADER-tt1", PIB: "100000008", VerificationStatus: models.VerificationVerified, Currency: "RSD", Products: []string{}, Receipts: []string{}, AccountBalance: rsd(0)})
+	putTestModel(t, state, models.Category{ID: "CATEGORY-MARKET", Name: "Market"})
+	_, stub := newStatefulContext(state, &now)
+	stub.GetTxIDReturns("tx1")
+	stub.GetCreatorReturns(serializedIdentity(t, "Org1MSP", "client"), nil)
+
+	// the documents of the network's test script leave out the optional fields
+	for function, document := range map[string]string{
+		"UserContract:CreateUser":       `{"id":"u1","name":"Alice","last_name":"Alicee","email":"a@gmail.com","receipts_ids":[],"balances":{"RSD":{"amount":10000,"currency":"RSD"}}}`,
+		"ProductContract:CreateProduct": `{"id":"pppp1","name":"p1","expiration_date":"","price":{"amount":200,"currency":"RSD"},"quantity":2,"trader_id":"tt1"}`,
+		"TraderContract:CreateTrader":   `{"id":"tt111","trader_type":"MARKET","pib":"100000032","currency":"RSD","products":["br1"],"receipts":[],"account_balance":{"amount":10000,"currency":"RSD"}}`,
+	} {
+		stub.GetFunctionAndParametersReturns(function, []string{document})
+		response := cc.Invoke(stub)
+		require.Equal(t, int32(shim.OK), response.Status, function+": "+response.Message)
+	}
+
+	require.Equal(t, "Alice", getTestModel[models.User](t, state, "USER-u1").Name)
+	require.Equal(t, "p1", getTestModel[models.Product](t, state, "PRODUCT-pppp1").Name)
+	require.Equal(t, "100000032", getTestModel[models.Trader](t, state, "TRADER-tt111").PIB)
+
+	stub.GetFunctionAndParametersReturns("UserContract:CreateUser", []string{`{"id":"u2","name":"Bob"}`})
+	require.Contains(t, cc.Invoke(stub).Message, "Value did not match schema")
+
+	// receipts are only issued by the sales
+	stub.GetFunctionAndParametersReturns("ReceiptContract:CreateReceipt", []string{`{"id":"r1","user_id":"u1","trader_id":"tt1"}`})
+	require.Contains(t, cc.Invoke(stub).Message, "no such transaction")
+}
+
+func TestUnreadableCreator(t *testing.T) {
+	cc, err := contractapi.NewChaincode(Contracts()...)
+	require.NoError(t, err)
+
+	_, stub := newStatefulContext(map[string][]byte{}, nil)
+	stub.GetFunctionAndParametersReturns("UserContract:ReadUser", []string{"u1"})
+	stub.GetCreatorReturns([]byte("not an identity"), nil)
+
+	response := cc.Invoke(stub)
+	require.Equal(t, int32(shim.ERROR), response.Status)
+	require.Contains(t, response.Message, "failed to read the caller's identity")
+}
+
+func TestUserContractUpdateUser(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+	frozen := &models.AccountFreeze{Reason: "fraud"}
+	putTestModel(t, state, models.User{ID: "USER-u1", Name: "Ana", LastName: "Jovanović", Email: "u1@example.com", ReceiptsID: []string{"tt1-2026-000001"}, Balances: rsdBalances(10), LockedBalances: rsdBalances(2), Movements: 3, Freeze: frozen})
+
+	ctx := newStatefulTransactionContext(state, &now, "client")
+
+	update := models.User{ID: "u1", Name: "Ana", LastName: "Petrović", Email: "ana@example.com", ReceiptsID: []string{}, Balances: rsdBalances(1000000), LockedBalances: models.Balances{}, Movements: 0}
+	update.Archive(now)
+	require.NoError(t, (&UserContract{sc: &SmartContract{}}).UpdateUser(ctx, "u1", &update))
+
+	user := getTestModel[models.User](t, state, "USER-u1")
+	require.Equal(t, "Petrović", user.LastName)
+	require.Equal(t, "ana@example.com", user.Email)
+	require.Equal(t, rsdBalances(10), user.Balances)
+	require.Equal(t, rsdBalances(2), user.LockedBalances)
+	require.Equal(t, []string{"tt1-2026-000001"}, user.ReceiptsID)
+	require.Equal(t, uint64(3), user.Movements)
+	require.Equal(t, frozen.Reason, user.Freeze.Reason)
+	require.False(t, user.IsArchived())
+}
+
+func TestClientUpdatesKeepManagedFields(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+	putTestModel(t, state, models.Trader{ID: "TRADER-tt1", PIB: "100000008", VerificationStatus: models.VerificationVerified, Currency: "RSD", OpeningBalance: rsd(5), AccountBalance: rsd(5), Products: []string{"m1", "b1"}, Receipts: []string{}})
+	putTestModel(t, state, models.Product{ID: "PRODUCT-m1", Name: "Milk", Price: rsd(3), Quantity: 5, Reserved: 2, Reservations: []string{"r1"}, TraderID: "tt1"})
+	sold := models.Product{ID: "PRODUCT-b1", Name: "Bread", Price: rsd(2), TraderID: "tt1"}
+	sold.Archive(now)
+	putTestModel(t, state, sold)
+
+	products := &ProductContract{sc: &SmartContract{}}
+	traders := &TraderContract{sc: &SmartContract{}}
+
+	update := models.Product{ID: "m1", Name: "Fresh milk", Price: rsd(4), Quantity: 6, Reserved: 0, Reservations: []string{}, TraderID: "tt1"}
+	require.NoError(t, products.UpdateProduct(newStatefulTransactionContext(state, &now, "client"), "m1", &update))
+	product := getTestModel[models.Product](t, state, "PRODUCT-m1")
+	require.Equal(t, "Fresh milk", product.Name)
+	require.Equal(t, uint(6), product.Quantity)
+	require.Equal(t, uint(2), product.Reserved)
+	require.Equal(t, []string{"r1"}, product.Reservations)
+
+	// a sold out product isn't restored by an update
+	restock := models.Product{ID: "b1", Name: "Bread", Price: rsd(2), Quantity: 10, TraderID: "tt1"}
+	restock.Restore()
+	require.NoError(t, products.UpdateProduct(newStatefulTransactionContext(state, &now, "client"), "b1", &restock))
+	require.True(t, getTestModel[models.Product](t, state, "PRODUCT-b1").IsArchived())
+
+	batch := []models.Product{{ID: "m1", Name: "Milk", Price: rsd(3), Quantity: 4, Reserved: 9, Reservations: []string{}}}
+	result, err := products.UpdateProducts(newStatefulTransactionContext(state, &now, "client"), batch, string(models.BatchAllOrNothing))
+	require.NoError(t, err)
+	require.True(t, result.Committed)
+	product = getTestModel[models.Product](t, state, "PRODUCT-m1")
+	require.Equal(t, uint(2), product.Reserved)
+	require.Equal(t, []string{"r1"}, product.Reservations)
+
+	trader := models.Trader{ID: "tt1", PIB: "100000008", Currency: "EUR", OpeningBalance: eur(1000), Products: []string{}, Receipts: []string{}}
+	trader.Archive(now)
+	require.NoError(t, traders.UpdateTrader(newStatefulTransactionContext(state, &now, "client"), "tt1", &trader))
+	stored := getTestModel[models.Trader](t, state, "TRADER-tt1")
+	require.Equal(t, "RSD", stored.Currency)
+	require.Equal(t, rsd(5), stored.OpeningBalance)
+	require.Equal(t, []string{"m1", "b1"}, stored.Products)
+	require.False(t, stored.IsArchived())
+}
@@ -0,0 +1,95 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// IdempotentChaincode records the response of every transaction submitted
+// with an idempotency key in its transient data. A transaction submitted
+// again with the same key gets the recorded response and doesn't run a
+// second time, so a client can retry a purchase whose commit it didn't see
+// without charging the user twice. Keys are scoped to the caller, reusing
+// one for a different request is refused.
+type IdempotentChaincode struct {
+	shim.Chaincode
+}
+
+func (c IdempotentChaincode) Invoke(stub shim.ChaincodeStubInterface) peer.Response {
+	transient, err := stub.GetTransient()
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to read the transient data: %v", err))
+	}
+
+	key := string(transient[models.IDEMPOTENCY_KEY_FIELD])
+	if key == "" {
+		return c.Chaincode.Invoke(stub)
+	}
+
+	if err := models.ValidateIdempotencyKey(key); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	creator, err := stub.GetCreator()
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to read the caller: %v", err))
+	}
+
+	id := models.ToIdempotencyRecordID(string(creator), key)
+	requestHash := models.IdempotencyRequestHash(string(creator), stub.GetArgs())
+
+	recordJson, err := stub.GetState(id)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to read from world state: %v", err))
+	}
+
+	if recordJson != nil {
+		var record models.IdempotencyRecord
+		if err := models.DecodeModel(id, recordJson, &record); err != nil {
+			return shim.Error(err.Error())
+		}
+
+		if record.RequestHash != requestHash {
+			return shim.Error(fmt.Sprintf("idempotency key %s was used for another request", key))
+		}
+
+		log.Printf("[%s] %s already processed in %s", stub.GetTxID(), record.Transaction, record.TxID)
+		return shim.Success(record.Payload)
+	}
+
+	response := c.Chaincode.Invoke(stub)
+	if response.Status >= shim.ERRORTHRESHOLD {
+		return response
+	}
+
+	timestamp, err := stub.GetTxTimestamp()
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to read the transaction time: %v", err))
+	}
+
+	function, _ := stub.GetFunctionAndParameters()
+	record := models.IdempotencyRecord{
+		ID:          id,
+		RequestHash: requestHash,
+		Transaction: function,
+		Payload:     response.Payload,
+		TxID:        stub.GetTxID(),
+		RecordedAt:  timestamp.AsTime().UTC().Format(time.RFC3339),
+	}
+
+	recordJson, err = models.EncodeModel(id, record)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	if err := stub.PutState(id, recordJson); err != nil {
+		return shim.Error(fmt.Sprintf("failed to record the idempotency key: %v", err))
+	}
+
+	return response
+}
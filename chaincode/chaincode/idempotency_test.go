@@ -0,0 +1,101 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/require"
+)
+
+// countingChaincode answers every invocation with its count.
+type countingChaincode struct {
+	invocations int
+	fail        bool
+}
+
+func (c *countingChaincode) Init(stub shim.ChaincodeStubInterface) peer.Response {
+	return shim.Success(nil)
+}
+
+func (c *countingChaincode) Invoke(stub shim.ChaincodeStubInterface) peer.Response {
+	c.invocations++
+	if c.fail {
+		return shim.Error("insufficient funds")
+	}
+
+	return shim.Success([]byte{byte(c.invocations)})
+}
+
+func TestIdempotentChaincode(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+	_, stub := newStatefulContext(state, &now)
+
+	inner := &countingChaincode{}
+	cc := IdempotentChaincode{Chaincode: inner}
+
+	invoke := func(creator string, key string, args ...string) peer.Response {
+		byteArgs := make([][]byte, 0, len(args))
+		for _, arg := range args {
+			byteArgs = append(byteArgs, []byte(arg))
+		}
+		stub.GetArgsReturns(byteArgs)
+		stub.GetFunctionAndParametersReturns(args[0], args[1:])
+		stub.GetCreatorReturns([]byte(creator), nil)
+		stub.GetTransientReturns(map[string][]byte{models.IDEMPOTENCY_KEY_FIELD: []byte(key)}, nil)
+		return cc.Invoke(stub)
+	}
+
+	// without a key every submission runs
+	require.Equal(t, []byte{1}, invoke("alice", "", "ProductContract:BuyProduct", "m1", "u1").Payload)
+	require.Equal(t, []byte{2}, invoke("alice", "", "ProductContract:BuyProduct", "m1", "u1").Payload)
+	require.Empty(t, state)
+
+	// a retry gets the recorded response
+	require.Equal(t, []byte{3}, invoke("alice", "k1", "ProductContract:BuyProduct", "m1", "u1").Payload)
+	response := invoke("alice", "k1", "ProductContract:BuyProduct", "m1", "u1")
+	require.Equal(t, int32(shim.OK), response.Status)
+	require.Equal(t, []byte{3}, response.Payload)
+	require.Equal(t, 3, inner.invocations)
+
+	record := getTestModel[models.IdempotencyRecord](t, state, models.ToIdempotencyRecordID("alice", "k1"))
+	require.Equal(t, "ProductContract:BuyProduct", record.Transaction)
+	require.Equal(t, "2026-05-01T10:00:00Z", record.RecordedAt)
+
+	// the key can't be reused for another request, other callers have their own keys
+	require.Contains(t, invoke("alice", "k1", "ProductContract:BuyProduct", "b1", "u1").Message, "was used for another request")
+	require.Equal(t, []byte{4}, invoke("bob", "k1", "ProductContract:BuyProduct", "b1", "u1").Payload)
+
+	// failed transactions aren't recorded
+	inner.fail = true
+	require.Equal(t, "insufficient funds", invoke("alice", "k2", "ProductContract:BuyProduct", "m1", "u1").Message)
+	require.Nil(t, state[models.ToIdempotencyRecordID("alice", "k2")])
+
+	require.Contains(t, invoke("alice", "key with spaces", "ProductContract:BuyProduct", "m1", "u1").Message, "printable")
+}
+
+func TestPurgeIdempotencyRecords(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+	ctx, _ := newStatefulContext(state, &now)
+	setCaller(ctx, "Org1MSP", "admin")
+
+	old := models.IdempotencyRecord{ID: models.ToIdempotencyRecordID("alice", "k1"), RecordedAt: "2026-04-01T10:00:00Z"}
+	recent := models.IdempotencyRecord{ID: models.ToIdempotencyRecordID("alice", "k2"), RecordedAt: "2026-04-30T10:00:00Z"}
+	putTestModel(t, state, old)
+	putTestModel(t, state, recent)
+
+	purged, err := sc.PurgeIdempotencyRecords(ctx, "2026-04-15T00:00:00Z")
+	require.NoError(t, err)
+	require.Equal(t, 1, purged)
+	require.Nil(t, state[old.ID])
+	require.NotNil(t, state[recent.ID])
+
+	setCaller(ctx, "Org1MSP", "client")
+	_, err = sc.PurgeIdempotencyRecords(ctx, "2026-05-01T00:00:00Z")
+	require.Error(t, err)
+}
@@ -0,0 +1,149 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// METADATA_FUNCTION is the system transaction returning the contract metadata.
+const METADATA_FUNCTION = "org.hyperledger.fabric:GetMetadata"
+
+// VALIDATE_EXTENSION is the property of a schema listing its validation rules,
+// as GetValidationRules returns them.
+const VALIDATE_EXTENSION = "x-validate"
+
+// MetadataChaincode adds the validation rules of the models to the contract
+// metadata. contractapi only reads a property's name and whether it's optional
+// from the metadata tag, so the schemas it reflects only list the required
+// properties. The rules are added to the schemas of the models as the JSON
+// schema keywords they map to, and every rule is listed under x-validate, so
+// clients generated from the metadata check what the chaincode checks.
+//
+// Only the returned metadata changes. contractapi still checks the arguments
+// against the schemas it reflected, the rules are checked by models.Validate
+// and break with ValidationErrors.
+type MetadataChaincode struct {
+	shim.Chaincode
+}
+
+func (c MetadataChaincode) Invoke(stub shim.ChaincodeStubInterface) peer.Response {
+	response := c.Chaincode.Invoke(stub)
+
+	function, _ := stub.GetFunctionAndParameters()
+	if function != METADATA_FUNCTION || response.Status >= shim.ERRORTHRESHOLD {
+		return response
+	}
+
+	payload, err := addValidationRules(response.Payload, models.SchemaRules())
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to add the validation rules to the metadata: %v", err))
+	}
+	response.Payload = payload
+
+	return response
+}
+
+// addValidationRules adds the rules to the component schemas of the metadata.
+// The metadata is changed as JSON, so the parts contractapi's types don't
+// carry are kept.
+func addValidationRules(metadataJson []byte, rules []models.TypeRules) ([]byte, error) {
+	var metadata map[string]any
+	if err := json.Unmarshal(metadataJson, &metadata); err != nil {
+		return nil, err
+	}
+
+	components, _ := metadata["components"].(map[string]any)
+	schemas, _ := components["schemas"].(map[string]any)
+
+	for _, typeRules := range rules {
+		schema, ok := schemas[typeRules.Type].(map[string]any)
+		if !ok {
+			continue
+		}
+		properties, _ := schema["properties"].(map[string]any)
+
+		for _, field := range typeRules.Fields {
+			property, ok := properties[field.Field].(map[string]any)
+			if !ok {
+				continue
+			}
+
+			for _, rule := range field.Rules {
+				if rule.Name == "required" {
+					schema["required"] = addRequired(schema["required"], field.Field)
+					continue
+				}
+				addRuleKeywords(property, rule)
+			}
+			property[VALIDATE_EXTENSION] = field.Rules
+		}
+	}
+
+	return json.Marshal(metadata)
+}
+
+func addRequired(required any, field string) []any {
+	fields, _ := required.([]any)
+	if slices.Contains(fields, any(field)) {
+		return fields
+	}
+
+	return append(fields, field)
+}
+
+// addRuleKeywords sets the JSON schema keywords a rule maps to. Rules without
+// one, such as pib with its checksum, are only listed under x-validate.
+func addRuleKeywords(property map[string]any, rule models.Rule) {
+	propertyType, _ := property["type"].(string)
+
+	switch rule.Name {
+	case "email":
+		property["format"] = "email"
+	case "min", "max":
+		bound, err := strconv.ParseInt(rule.Param, 10, 64)
+		if err != nil {
+			return
+		}
+
+		keywords := map[string][2]string{
+			"string":  {"minLength", "maxLength"},
+			"array":   {"minItems", "maxItems"},
+			"object":  {"minProperties", "maxProperties"},
+			"integer": {"minimum", "maximum"},
+			"number":  {"minimum", "maximum"},
+		}
+		names, ok := keywords[propertyType]
+		if !ok {
+			return
+		}
+
+		if rule.Name == "min" {
+			property[names[0]] = bound
+		} else {
+			property[names[1]] = bound
+		}
+	case "oneof":
+		values := make([]any, 0)
+		for _, value := range strings.Fields(rule.Param) {
+			if propertyType == "integer" || propertyType == "number" {
+				number, err := strconv.ParseInt(value, 10, 64)
+				if err != nil {
+					return
+				}
+				values = append(values, number)
+			} else {
+				values = append(values, value)
+			}
+		}
+		property["enum"] = values
+	case "currency":
+		property["pattern"] = "^[A-Z]{3}$"
+	}
+}
@@ -0,0 +1,57 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataChaincode(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	_, stub := newStatefulContext(map[string][]byte{}, &now)
+	stub.GetCreatorReturns(serializedIdentity(t, "Org1MSP", "client"), nil)
+	stub.GetFunctionAndParametersReturns(METADATA_FUNCTION, []string{})
+	stub.GetArgsReturns([][]byte{[]byte(METADATA_FUNCTION)})
+
+	inner, err := contractapi.NewChaincode(Contracts()...)
+	require.NoError(t, err)
+
+	response := MetadataChaincode{Chaincode: inner}.Invoke(stub)
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	var metadata struct {
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]map[string]any `json:"properties"`
+				Required   []string                  `json:"required"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(response.Payload, &metadata))
+	schemas := metadata.Components.Schemas
+
+	user := schemas["User"]
+	require.Contains(t, user.Required, "email")
+	require.Equal(t, "email", user.Properties["email"]["format"])
+	require.EqualValues(t, 100, user.Properties["name"]["maxLength"])
+
+	require.EqualValues(t, 200, schemas["Product"].Properties["name"]["maxLength"])
+	require.EqualValues(t, 2, schemas["PriceTier"].Properties["min_quantity"]["minimum"])
+	require.EqualValues(t, 0, schemas["Money"].Properties["amount"]["minimum"])
+	require.Equal(t, "^[A-Z]{3}$", schemas["Money"].Properties["currency"]["pattern"])
+
+	trader := schemas["Trader"]
+	require.Equal(t, []any{"PENDING", "VERIFIED", "SUSPENDED"}, trader.Properties["verification_status"]["enum"])
+	require.Equal(t, []any{map[string]any{"name": "required"}, map[string]any{"name": "pib"}}, trader.Properties["pib"][VALIDATE_EXTENSION])
+
+	// other transactions are passed through
+	stub.GetFunctionAndParametersReturns("GetValidationRules", []string{})
+	stub.GetArgsReturns([][]byte{[]byte("GetValidationRules")})
+	response = MetadataChaincode{Chaincode: inner}.Invoke(stub)
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	require.Contains(t, string(response.Payload), `"type":"USER"`)
+}
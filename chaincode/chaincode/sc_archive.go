@@ -0,0 +1,314 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// archivable is implemented by the pointers to the models that embed
+// models.Lifecycle.
+type archivable[T models.Model] interface {
+	*T
+	IsArchived() bool
+	ArchivedAt() string
+	Archive(at time.Time)
+	Restore()
+}
+
+// requireActive rejects transactions that would use an archived record.
+func requireActive(record interface{ IsArchived() bool }, id string) error {
+	if record.IsArchived() {
+		return fmt.Errorf("%s is archived", id)
+	}
+
+	return nil
+}
+
+func archiveModel[T models.Model, P archivable[T]](ctx contractapi.TransactionContextInterface, id string) error {
+	model, err := readModel[T](ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if P(model).IsArchived() {
+		return fmt.Errorf("%s is already archived", id)
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	P(model).Archive(now)
+	return updateModel(ctx, id, model)
+}
+
+func restoreModel[T models.Model, P archivable[T]](ctx contractapi.TransactionContextInterface, id string) error {
+	model, err := readModel[T](ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !P(model).IsArchived() {
+		return fmt.Errorf("%s isn't archived", id)
+	}
+
+	P(model).Restore()
+	return updateModel(ctx, id, model)
+}
+
+// RestoreRecord makes an archived product, user, trader or receipt active
+// again.
+func (sc *SmartContract) RestoreRecord(ctx contractapi.TransactionContextInterface, entityType string, id string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	key := models.FormatKey(entityType, id)
+	switch entityType {
+	case models.PRODUCT_TYPE:
+		product, err := sc.ReadProduct(ctx, id)
+		if err != nil {
+			return err
+		}
+		if product.SoldOut() {
+			return fmt.Errorf("%s is sold out", key)
+		}
+		if err := checkProductReferences(ctx, product, true); err != nil {
+			return err
+		}
+		return restoreModel[models.Product](ctx, key)
+	case models.USER_TYPE:
+		return restoreModel[models.User](ctx, key)
+	case models.TRADER_TYPE:
+		return restoreModel[models.Trader](ctx, key)
+	case models.RECEIPT_TYPE:
+		receipt, err := sc.ReadReceipt(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkReceiptReferences(ctx, receipt); err != nil {
+			return err
+		}
+		return restoreModel[models.Receipt](ctx, key)
+	default:
+		return fmt.Errorf("%s records can't be archived", entityType)
+	}
+}
+
+// GetArchivedRecords lists the archived records of the entity type, which
+// the other listings leave out.
+func (sc *SmartContract) GetArchivedRecords(ctx contractapi.TransactionContextInterface, entityType string) ([]*models.ArchivedRecord, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	switch entityType {
+	case models.PRODUCT_TYPE:
+		return archivedRecords[models.Product](ctx, entityType)
+	case models.USER_TYPE:
+		return archivedRecords[models.User](ctx, entityType)
+	case models.TRADER_TYPE:
+		return archivedRecords[models.Trader](ctx, entityType)
+	case models.RECEIPT_TYPE:
+		return archivedRecords[models.Receipt](ctx, entityType)
+	default:
+		return nil, fmt.Errorf("%s records can't be archived", entityType)
+	}
+}
+
+func archivedRecords[T models.Model, P archivable[T]](ctx contractapi.TransactionContextInterface, entityType string) ([]*models.ArchivedRecord, error) {
+	all, err := getAllOfType[T](ctx, entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*models.ArchivedRecord, 0)
+	for _, model := range all {
+		if !P(model).IsArchived() {
+			continue
+		}
+
+		records = append(records, &models.ArchivedRecord{
+			ID:         (*model).GetID(),
+			EntityType: entityType,
+			DeletedAt:  P(model).ArchivedAt(),
+		})
+	}
+
+	return records, nil
+}
+
+// PurgeRecord removes an archived record from the world state for good. It
+// fails with a RestrictedDeleteError while any record still refers to it,
+// whatever the relation's delete action; receipts are only purged once
+// their user and trader are archived or purged, and before them.
+func (sc *SmartContract) PurgeRecord(ctx contractapi.TransactionContextInterface, entityType string, id string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	key := models.FormatKey(entityType, id)
+	switch entityType {
+	case models.PRODUCT_TYPE:
+		return sc.purgeProduct(ctx, id, key)
+	case models.USER_TYPE:
+		return sc.purgeUser(ctx, id, key)
+	case models.TRADER_TYPE:
+		return sc.purgeTrader(ctx, id, key)
+	case models.RECEIPT_TYPE:
+		return sc.purgeReceipt(ctx, key)
+	default:
+		return fmt.Errorf("%s records can't be archived", entityType)
+	}
+}
+
+func readArchived[T models.Model, P archivable[T]](ctx contractapi.TransactionContextInterface, key string) (*T, error) {
+	model, err := readModel[T](ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if !P(model).IsArchived() {
+		return nil, fmt.Errorf("%s must be archived before it's purged", key)
+	}
+
+	return model, nil
+}
+
+func (sc *SmartContract) purgeProduct(ctx contractapi.TransactionContextInterface, id string, key string) error {
+	product, err := readArchived[models.Product](ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if err := restrictPurge(ctx, models.PRODUCT_TYPE, id); err != nil {
+		return err
+	}
+
+	// the trader's product list is owned by the trader, so the product is
+	// taken off it instead of blocking the purge
+	trader, err := sc.ReadTrader(ctx, product.TraderID)
+	if err == nil {
+		trader.RemoveProduct(id)
+		if err := sc.UpdateTrader(ctx, product.TraderID, trader); err != nil {
+			return err
+		}
+	}
+
+	return deleteModel(ctx, key)
+}
+
+// purgeUser pays out the user's balances before deleting the user and the
+// movements. The journal keeps the user's entries, whose account then
+// balances, so a user created again with the same id starts from zero.
+func (sc *SmartContract) purgeUser(ctx contractapi.TransactionContextInterface, id string, key string) error {
+	user, err := readArchived[models.User](ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if err := restrictPurge(ctx, models.USER_TYPE, id); err != nil {
+		return err
+	}
+
+	if err := withdrawUser(ctx, user); err != nil {
+		return err
+	}
+
+	if err := deleteModel(ctx, key); err != nil {
+		return err
+	}
+
+	return deleteMovements(ctx, id)
+}
+
+// purgeTrader deletes the trader with its pending deltas, which the
+// compaction couldn't fold into a trader that no longer exists.
+func (sc *SmartContract) purgeTrader(ctx contractapi.TransactionContextInterface, id string, key string) error {
+	trader, err := readArchived[models.Trader](ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if err := restrictPurge(ctx, models.TRADER_TYPE, id); err != nil {
+		return err
+	}
+
+	if err := foldPendingDeltas(ctx, trader); err != nil {
+		return err
+	}
+
+	return deleteModel(ctx, key)
+}
+
+// purgeReceipt keeps the receipt while its user or trader is active. The
+// archived owners drop it from their receipt lists, so that they can be
+// purged once their receipts are.
+func (sc *SmartContract) purgeReceipt(ctx contractapi.TransactionContextInterface, key string) error {
+	receipt, err := readArchived[models.Receipt](ctx, key)
+	if err != nil {
+		return err
+	}
+
+	userId, traderId := models.ToUserID(receipt.UserID), models.ToTraderID(receipt.TraderID)
+	user, err := archivedOwner[models.User](ctx, key, userId)
+	if err != nil {
+		return err
+	}
+
+	trader, err := archivedOwner[models.Trader](ctx, key, traderId)
+	if err != nil {
+		return err
+	}
+
+	number := models.TrimKeyPrefix(models.RECEIPT_TYPE, key)
+	listed := func(id string) bool {
+		return models.TrimKeyPrefix(models.RECEIPT_TYPE, id) == number
+	}
+
+	if user != nil {
+		user.ReceiptsID = slices.DeleteFunc(user.ReceiptsID, listed)
+		if err := updateModel(ctx, userId, user); err != nil {
+			return err
+		}
+	}
+
+	if trader != nil {
+		// the pending deltas would list the receipt again
+		if err := foldPendingDeltas(ctx, trader); err != nil {
+			return err
+		}
+
+		trader.Receipts = slices.DeleteFunc(trader.Receipts, listed)
+		if err := updateModel(ctx, traderId, trader); err != nil {
+			return err
+		}
+	}
+
+	return deleteModel(ctx, key)
+}
+
+// archivedOwner reads the owner of the receipt, which is nil once it's
+// purged. An active owner keeps its receipt.
+func archivedOwner[T models.Model, P archivable[T]](ctx contractapi.TransactionContextInterface, key string, owner string) (*T, error) {
+	exists, err := modelExists(ctx, owner)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	model, err := readModel[T](ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	if !P(model).IsArchived() {
+		return nil, &models.RestrictedDeleteError{Key: key, Relation: models.EntityType(owner) + ".receipts", Referrers: []string{owner}}
+	}
+
+	return model, nil
+}
@@ -0,0 +1,172 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveRestoreAndPurge(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	ctx, _ := newStatefulContext(state, &now)
+	setCaller(ctx, "Org1MSP", "admin")
+
+	require.NoError(t, sc.CreateTrader(ctx, models.Trader{ID: "tt1", PIB: "100000008", Currency: "RSD"}))
+	require.NoError(t, sc.SetTraderVerification(ctx, "tt1", string(models.VerificationVerified)))
+	require.NoError(t, sc.CreateProduct(ctx, models.Product{ID: "m1", Name: "Milk", Price: rsd(3), Quantity: 5, TraderID: "tt1"}))
+	require.NoError(t, sc.CreateProduct(ctx, models.Product{ID: "b1", Name: "Bread", Price: rsd(2), Quantity: 1, TraderID: "tt1"}))
+	require.NoError(t, sc.CreateUser(ctx, models.User{ID: "u1", Name: "Ana", LastName: "Jovanović", Email: "u1@example.com", Balances: rsdBalances(20)}))
+	require.NoError(t, sc.BuyProduct(ctx, "m1", "u1"))
+
+	require.NoError(t, sc.DeleteProduct(ctx, "m1"))
+	require.Error(t, sc.DeleteProduct(ctx, "m1"), "archived twice")
+	require.Error(t, sc.BuyProduct(ctx, "m1", "u1"))
+
+	product := getTestModel[models.Product](t, state, "PRODUCT-m1")
+	require.Equal(t, models.RecordArchived, product.Status)
+	require.Equal(t, "2026-05-01T10:00:00Z", product.DeletedAt)
+
+	archived, err := sc.GetArchivedRecords(ctx, models.PRODUCT_TYPE)
+	require.NoError(t, err)
+	require.Len(t, archived, 1)
+	require.Equal(t, "PRODUCT-m1", archived[0].ID)
+
+	// the receipt of the first purchase still refers to the product
+	require.ErrorContains(t, sc.PurgeRecord(ctx, models.PRODUCT_TYPE, "m1"), "still referenced")
+
+	require.NoError(t, sc.RestoreRecord(ctx, models.PRODUCT_TYPE, "m1"))
+	require.NoError(t, sc.BuyProduct(ctx, "m1", "u1"))
+
+	require.NoError(t, sc.DeleteProduct(ctx, "b1"))
+	require.NoError(t, sc.PurgeRecord(ctx, models.PRODUCT_TYPE, "b1"))
+	require.NotContains(t, state, "PRODUCT-b1")
+	require.Equal(t, []string{"m1"}, getTestModel[models.Trader](t, state, "TRADER-tt1").Products)
+
+	require.ErrorContains(t, sc.PurgeRecord(ctx, models.USER_TYPE, "u1"), "must be archived")
+	require.NoError(t, sc.DeleteTrader(ctx, "tt1"))
+	require.ErrorContains(t, sc.PurgeRecord(ctx, models.TRADER_TYPE, "tt1"), "still referenced")
+	require.Error(t, sc.BuyProduct(ctx, "m1", "u1"), "archived trader")
+
+	setCaller(ctx, "Org1MSP", "client")
+	require.Error(t, sc.RestoreRecord(ctx, models.TRADER_TYPE, "tt1"))
+	require.Error(t, sc.PurgeRecord(ctx, models.USER_TYPE, "u1"))
+}
+
+func TestPurgeReceiptBeforeItsOwners(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	ctx, _ := newStatefulContext(state, &now)
+	setCaller(ctx, "Org1MSP", "admin")
+
+	require.NoError(t, sc.CreateTrader(ctx, models.Trader{ID: "tt1", PIB: "100000008", Currency: "RSD"}))
+	require.NoError(t, sc.SetTraderVerification(ctx, "tt1", string(models.VerificationVerified)))
+	require.NoError(t, sc.CreateProduct(ctx, models.Product{ID: "m1", Name: "Milk", Price: rsd(3), Quantity: 5, TraderID: "tt1"}))
+	require.NoError(t, sc.CreateUser(ctx, models.User{ID: "u1", Name: "Ana", LastName: "Jovanović", Email: "u1@example.com", Balances: rsdBalances(20)}))
+	require.NoError(t, sc.BuyProduct(ctx, "m1", "u1"))
+	_, err := sc.CompactTraderDeltas(ctx, 10)
+	require.NoError(t, err)
+
+	number := getTestModel[models.User](t, state, "USER-u1").ReceiptsID[0]
+	require.Len(t, getTestModel[models.Trader](t, state, "TRADER-tt1").Receipts, 1)
+	require.NoError(t, sc.DeleteReceipt(ctx, number))
+
+	// the receipt is kept while its user or trader is active
+	require.ErrorContains(t, sc.PurgeRecord(ctx, models.RECEIPT_TYPE, number), "still referenced")
+	require.NoError(t, sc.DeleteUser(ctx, "u1"))
+	require.ErrorContains(t, sc.PurgeRecord(ctx, models.RECEIPT_TYPE, number), "still referenced")
+	require.NoError(t, sc.DeleteTrader(ctx, "tt1"))
+
+	require.ErrorContains(t, sc.PurgeRecord(ctx, models.USER_TYPE, "u1"), "still referenced")
+	require.NoError(t, sc.PurgeRecord(ctx, models.RECEIPT_TYPE, number))
+	require.NotContains(t, state, models.ToReceiptID(number))
+	require.Empty(t, getTestModel[models.User](t, state, "USER-u1").ReceiptsID)
+	require.Empty(t, getTestModel[models.Trader](t, state, "TRADER-tt1").Receipts)
+
+	require.NoError(t, sc.PurgeRecord(ctx, models.PRODUCT_TYPE, "m1"))
+	require.NoError(t, sc.PurgeRecord(ctx, models.USER_TYPE, "u1"))
+	require.NoError(t, sc.PurgeRecord(ctx, models.TRADER_TYPE, "tt1"))
+}
+
+func TestPurgeWithPendingDeltas(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	ctx, _ := newStatefulContext(state, &now)
+	setCaller(ctx, "Org1MSP", "admin")
+
+	require.NoError(t, sc.CreateTrader(ctx, models.Trader{ID: "tt1", PIB: "100000008", Currency: "RSD"}))
+	require.NoError(t, sc.SetTraderVerification(ctx, "tt1", string(models.VerificationVerified)))
+	require.NoError(t, sc.CreateProduct(ctx, models.Product{ID: "m1", Name: "Milk", Price: rsd(3), Quantity: 5, TraderID: "tt1"}))
+	require.NoError(t, sc.CreateUser(ctx, models.User{ID: "u1", Name: "Ana", LastName: "Jovanović", Email: "u1@example.com", Balances: rsdBalances(20)}))
+	require.NoError(t, sc.BuyProduct(ctx, "m1", "u1"))
+	require.NoError(t, sc.BuyProduct(ctx, "m1", "u1"))
+
+	receipts := getTestModel[models.User](t, state, "USER-u1").ReceiptsID
+	require.NoError(t, sc.RefundReceipt(ctx, receipts[1]))
+	require.NoError(t, sc.DeleteUser(ctx, "u1"))
+	require.NoError(t, sc.DeleteTrader(ctx, "tt1"))
+
+	// the first receipt is purged before the deltas are compacted
+	require.NoError(t, sc.DeleteReceipt(ctx, receipts[0]))
+	require.NoError(t, sc.PurgeRecord(ctx, models.RECEIPT_TYPE, receipts[0]))
+	trader := getTestModel[models.Trader](t, state, "TRADER-tt1")
+	require.Equal(t, []string{receipts[1]}, trader.Receipts)
+	require.Equal(t, rsd(3), trader.AccountBalance)
+
+	deltas, err := getTraderDeltas(ctx, "TRADER-tt1")
+	require.NoError(t, err)
+	require.Empty(t, deltas)
+
+	require.NoError(t, sc.DeleteReceipt(ctx, receipts[1]))
+	require.NoError(t, sc.PurgeRecord(ctx, models.RECEIPT_TYPE, receipts[1]))
+	require.NoError(t, sc.PurgeRecord(ctx, models.PRODUCT_TYPE, "m1"))
+	require.NoError(t, sc.PurgeRecord(ctx, models.USER_TYPE, "u1"))
+	require.NoError(t, sc.PurgeRecord(ctx, models.TRADER_TYPE, "tt1"))
+
+	// the compaction doesn't trip over deltas of the purged trader
+	result, err := sc.CompactTraderDeltas(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, 0, result.Deltas)
+	require.True(t, result.Done)
+}
+
+func TestRecreatePurgedUser(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	ctx, _ := newStatefulContext(state, &now)
+	setCaller(ctx, "Org1MSP", "admin")
+
+	require.NoError(t, sc.CreateUser(ctx, models.User{ID: "u1", Name: "Ana", LastName: "Jovanović", Email: "u1@example.com", Balances: rsdBalances(20)}))
+	require.NoError(t, sc.DepositFunds(ctx, "u1", eur(5)))
+	require.NoError(t, sc.CreateUser(ctx, models.User{ID: "u1-2", Name: "Ana", LastName: "Petrović", Email: "u2@example.com", Balances: rsdBalances(1)}))
+	require.NoError(t, sc.DeleteUser(ctx, "u1"))
+	require.NoError(t, sc.PurgeRecord(ctx, models.USER_TYPE, "u1"))
+
+	// the purge pays out the balances, so the purged user's account balances
+	withdrawal := getTestModel[models.JournalEntry](t, state, models.ToJournalID(models.ToMovementID("u1", 3)))
+	require.Equal(t, models.MovementWithdrawal, withdrawal.Kind)
+	require.NotContains(t, state, models.ToMovementID("u1", 0))
+
+	// the journal keeps the purged user's entries, the new user continues after them
+	require.NoError(t, sc.CreateUser(ctx, models.User{ID: "u1", Name: "Ana", LastName: "Marković", Email: "u1@example.com", Balances: rsdBalances(7)}))
+	require.Contains(t, state, models.ToJournalID(models.ToMovementID("u1", 4)))
+	require.Equal(t, uint64(5), getTestModel[models.User](t, state, "USER-u1").Movements)
+
+	statement, err := sc.GetUserStatement(ctx, "u1", "RSD", "2026-01-01", "2026-12-31")
+	require.NoError(t, err)
+	require.Len(t, statement.Entries, 1)
+
+	report, err := sc.AuditLedger(ctx)
+	require.NoError(t, err)
+	require.Empty(t, report.Violations)
+}
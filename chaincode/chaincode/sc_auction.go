@@ -0,0 +1,516 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const sealedBidTransientKey = "bid"
+
+func parseAuctionTime(value string, field string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid auction %s: %v", field, err)
+	}
+
+	return t, nil
+}
+
+func (sc *SmartContract) CreateAuction(ctx contractapi.TransactionContextInterface, auction models.Auction) error {
+	product, err := sc.ReadProduct(ctx, auction.ProductID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireActive(product, product.ID); err != nil {
+		return err
+	}
+
+	if product.TraderID != auction.TraderID {
+		return fmt.Errorf("product %s doesn't belong to the trader %s", auction.ProductID, auction.TraderID)
+	}
+
+	trader, err := sc.ReadTrader(ctx, auction.TraderID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireVerified(trader); err != nil {
+		return err
+	}
+
+	if product.Quantity == 0 {
+		return fmt.Errorf("product %s is out of stock", auction.ProductID)
+	}
+
+	start, err := parseAuctionTime(auction.StartTime, "start time")
+	if err != nil {
+		return err
+	}
+
+	end, err := parseAuctionTime(auction.EndTime, "end time")
+	if err != nil {
+		return err
+	}
+
+	if !end.After(start) {
+		return fmt.Errorf("auction must end after it starts")
+	}
+
+	if auction.Sealed {
+		revealEnd, err := parseAuctionTime(auction.RevealEndTime, "reveal end time")
+		if err != nil {
+			return err
+		}
+
+		if !revealEnd.After(end) {
+			return fmt.Errorf("reveal phase must end after the auction ends")
+		}
+	} else {
+		auction.RevealEndTime = ""
+	}
+
+	if err := auction.ReservePrice.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := auction.ReservePrice.Compare(product.Price); err != nil {
+		return fmt.Errorf("reserve price must be in the product currency: %v", err)
+	}
+
+	auction.ID = models.ToAuctionID(auction.ID)
+	auction.Status = models.AuctionOpen
+	auction.HighestBid = models.Money{}
+	auction.WinningBidID = ""
+	auction.Bids = make([]string, 0)
+
+	return createModel(ctx, auction)
+}
+
+func (sc *SmartContract) ReadAuction(ctx contractapi.TransactionContextInterface, id string) (*models.Auction, error) {
+	return readModel[models.Auction](ctx, models.ToAuctionID(id))
+}
+
+func (sc *SmartContract) ReadBid(ctx contractapi.TransactionContextInterface, id string) (*models.Bid, error) {
+	return readModel[models.Bid](ctx, models.ToBidID(id))
+}
+
+func (sc *SmartContract) GetAllAuctions(ctx contractapi.TransactionContextInterface) ([]*models.Auction, error) {
+	return getQueryResult[models.Auction](ctx, models.BuildQueryIdStartsWith(models.AUCTION_TYPE))
+}
+
+// checkBiddingOpen verifies that the auction accepts bids at the time of the
+// current transaction.
+func checkBiddingOpen(ctx contractapi.TransactionContextInterface, auction *models.Auction) error {
+	if auction.Status != models.AuctionOpen {
+		return fmt.Errorf("auction %s is not open", auction.ID)
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	start, err := parseAuctionTime(auction.StartTime, "start time")
+	if err != nil {
+		return err
+	}
+
+	end, err := parseAuctionTime(auction.EndTime, "end time")
+	if err != nil {
+		return err
+	}
+
+	if now.Before(start) || !now.Before(end) {
+		return fmt.Errorf("auction %s is not accepting bids", auction.ID)
+	}
+
+	return nil
+}
+
+// lockBid moves the bid deposit out of the bidder's spendable balance and
+// stores the bid on the auction.
+func (sc *SmartContract) lockBid(ctx contractapi.TransactionContextInterface, auctionId string, auction *models.Auction, bid models.Bid) error {
+	user, err := sc.ReadUser(ctx, bid.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err := user.CheckDebit(); err != nil {
+		return err
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the winning bid is paid when the auction is settled, a bid the user's
+	// limits don't allow now is refused right away
+	if err := user.CheckSpend(bid.Amount, now); err != nil {
+		return err
+	}
+
+	if err := user.Lock(bid.Deposit); err != nil {
+		return fmt.Errorf("failed to lock the bid deposit: %v", err)
+	}
+
+	bid.ID = models.ToBidID(fmt.Sprintf("%s-%d", auctionId, len(auction.Bids)))
+	if err := recordMovement(ctx, user, models.MovementBidDeposit, models.Debit, bid.Deposit, models.TrimKeyPrefix(models.BID_TYPE, bid.ID), lockedLine(user, models.Credit, bid.Deposit)); err != nil {
+		return err
+	}
+
+	bid.AuctionID = auctionId
+	bid.Status = models.BidLocked
+	auction.Bids = append(auction.Bids, bid.ID)
+
+	if err := createModel(ctx, bid); err != nil {
+		return err
+	}
+
+	if err := sc.UpdateUser(ctx, bid.UserID, user); err != nil {
+		return err
+	}
+
+	return updateModel(ctx, auction.ID, auction)
+}
+
+func (sc *SmartContract) PlaceBid(ctx contractapi.TransactionContextInterface, auctionId string, userId string, amount models.Money) error {
+	auction, err := sc.ReadAuction(ctx, auctionId)
+	if err != nil {
+		return err
+	}
+
+	if auction.Sealed {
+		return fmt.Errorf("auction %s accepts sealed bids only", auctionId)
+	}
+
+	if err := checkBiddingOpen(ctx, auction); err != nil {
+		return err
+	}
+
+	if err := amount.Validate(); err != nil {
+		return err
+	}
+
+	if cmp, err := amount.Compare(auction.ReservePrice); err != nil || cmp < 0 {
+		return fmt.Errorf("bid is below the reserve price of %s", auction.ReservePrice)
+	}
+
+	if cmp, err := amount.Compare(auction.HighestBid); err != nil || cmp <= 0 {
+		return fmt.Errorf("bid must be higher than the current highest bid of %s", auction.HighestBid)
+	}
+
+	auction.HighestBid = amount
+
+	return sc.lockBid(ctx, auctionId, auction, models.Bid{UserID: userId, Amount: amount, Deposit: amount, Revealed: true})
+}
+
+// CommitBid places a sealed bid. Only the commitment and the locked deposit
+// are public; the deposit must cover the bid that is revealed later. The
+// bidder may also pass the plain bid in the transient field "bid" to keep a
+// copy in its organization's implicit private data collection.
+func (sc *SmartContract) CommitBid(ctx contractapi.TransactionContextInterface, auctionId string, userId string, commitment string, deposit models.Money) error {
+	auction, err := sc.ReadAuction(ctx, auctionId)
+	if err != nil {
+		return err
+	}
+
+	if !auction.Sealed {
+		return fmt.Errorf("auction %s accepts open bids only", auctionId)
+	}
+
+	if err := checkBiddingOpen(ctx, auction); err != nil {
+		return err
+	}
+
+	if commitment == "" {
+		return fmt.Errorf("bid commitment is required")
+	}
+
+	if err := deposit.Validate(); err != nil {
+		return err
+	}
+
+	if cmp, err := deposit.Compare(auction.ReservePrice); err != nil || cmp < 0 {
+		return fmt.Errorf("deposit is below the reserve price of %s", auction.ReservePrice)
+	}
+
+	bid := models.Bid{UserID: userId, Deposit: deposit, Commitment: commitment}
+	if err := sc.lockBid(ctx, auctionId, auction, bid); err != nil {
+		return err
+	}
+
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("failed to read the transient data: %v", err)
+	}
+
+	if privateBid, ok := transient[sealedBidTransientKey]; ok {
+		mspId, err := ctx.GetClientIdentity().GetMSPID()
+		if err != nil {
+			return fmt.Errorf("failed to read the client msp id: %v", err)
+		}
+
+		bidId := auction.Bids[len(auction.Bids)-1]
+		if err := ctx.GetStub().PutPrivateData("_implicit_org_"+mspId, bidId, privateBid); err != nil {
+			return fmt.Errorf("failed to store the private bid: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (sc *SmartContract) RevealBid(ctx contractapi.TransactionContextInterface, bidId string, amount models.Money, salt string) error {
+	bid, err := sc.ReadBid(ctx, bidId)
+	if err != nil {
+		return err
+	}
+
+	auction, err := sc.ReadAuction(ctx, bid.AuctionID)
+	if err != nil {
+		return err
+	}
+
+	if auction.Status != models.AuctionOpen {
+		return fmt.Errorf("auction %s is not open", auction.ID)
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	end, err := parseAuctionTime(auction.EndTime, "end time")
+	if err != nil {
+		return err
+	}
+
+	revealEnd, err := parseAuctionTime(auction.RevealEndTime, "reveal end time")
+	if err != nil {
+		return err
+	}
+
+	if now.Before(end) || !now.Before(revealEnd) {
+		return fmt.Errorf("auction %s is not in the reveal phase", auction.ID)
+	}
+
+	if bid.Revealed {
+		return fmt.Errorf("bid %s is already revealed", bidId)
+	}
+
+	if models.BidCommitment(amount, salt) != bid.Commitment {
+		return fmt.Errorf("revealed bid doesn't match the commitment")
+	}
+
+	if cmp, err := amount.Compare(bid.Deposit); err != nil || cmp > 0 {
+		return fmt.Errorf("revealed bid exceeds the locked deposit")
+	}
+
+	bid.Amount = amount
+	bid.Revealed = true
+
+	return updateModel(ctx, bid.ID, bid)
+}
+
+// SettleAuction closes the auction once bidding (or the reveal phase for
+// sealed auctions) is over. The highest bid at or above the reserve price
+// wins the product; every other deposit is released back to its bidder.
+func (sc *SmartContract) SettleAuction(ctx contractapi.TransactionContextInterface, auctionId string) error {
+	auction, err := sc.ReadAuction(ctx, auctionId)
+	if err != nil {
+		return err
+	}
+
+	if auction.Status != models.AuctionOpen {
+		return fmt.Errorf("auction %s is already settled", auctionId)
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	closing := auction.EndTime
+	if auction.Sealed {
+		closing = auction.RevealEndTime
+	}
+
+	closeTime, err := parseAuctionTime(closing, "closing time")
+	if err != nil {
+		return err
+	}
+
+	if now.Before(closeTime) {
+		return fmt.Errorf("auction %s can't be settled before %s", auctionId, closing)
+	}
+
+	bids := make([]*models.Bid, 0, len(auction.Bids))
+	var winner *models.Bid
+
+	for _, bidKey := range auction.Bids {
+		bid, err := readModel[models.Bid](ctx, bidKey)
+		if err != nil {
+			return err
+		}
+		bids = append(bids, bid)
+
+		if !bid.Revealed {
+			continue
+		}
+
+		if cmp, err := bid.Amount.Compare(auction.ReservePrice); err != nil || cmp < 0 {
+			continue
+		}
+
+		if winner == nil {
+			winner = bid
+		} else if cmp, err := bid.Amount.Compare(winner.Amount); err == nil && cmp > 0 {
+			winner = bid
+		}
+	}
+
+	if winner != nil {
+		inStock, err := sc.productInStock(ctx, auction.ProductID)
+		if err != nil {
+			return err
+		}
+
+		trader, err := sc.ReadTrader(ctx, auction.TraderID)
+		if err != nil {
+			return err
+		}
+
+		// the product sold out or the trader was suspended during the
+		// auction, so every bid is released
+		if !inStock || !trader.IsVerified() {
+			winner = nil
+		}
+	}
+
+	users := make(map[string]*models.User)
+	if winner != nil {
+		user, err := sc.ReadUser(ctx, winner.UserID)
+		if err != nil {
+			return err
+		}
+		users[winner.UserID] = user
+
+		// a winner whose account was frozen or whose limits were used up
+		// since bidding can't pay, so every bid is released
+		if user.CheckDebit() != nil || user.Spend(winner.Amount, now) != nil {
+			winner = nil
+		}
+	}
+
+	for _, bid := range bids {
+		user, ok := users[bid.UserID]
+		if !ok {
+			user, err = sc.ReadUser(ctx, bid.UserID)
+			if err != nil {
+				return err
+			}
+			users[bid.UserID] = user
+		}
+
+		bidId := models.TrimKeyPrefix(models.BID_TYPE, bid.ID)
+		if err := user.Unlock(bid.Deposit); err != nil {
+			return err
+		}
+		if err := recordMovement(ctx, user, models.MovementBidRelease, models.Credit, bid.Deposit, bidId, lockedLine(user, models.Debit, bid.Deposit)); err != nil {
+			return err
+		}
+		bid.Status = models.BidReleased
+
+		if bid == winner {
+			if err := debitUser(ctx, user, bid.Amount, models.MovementPurchase, bidId, traderLine(auction.TraderID, models.Credit, bid.Amount)); err != nil {
+				return err
+			}
+			bid.Status = models.BidWon
+		}
+
+		if err := updateModel(ctx, bid.ID, bid); err != nil {
+			return err
+		}
+	}
+
+	auction.Status = models.AuctionSettled
+
+	if winner != nil {
+		if err := sc.transferAuctionedProduct(ctx, auction, winner, users[winner.UserID], now); err != nil {
+			return err
+		}
+		auction.WinningBidID = winner.ID
+		auction.HighestBid = winner.Amount
+	}
+
+	for userId, user := range users {
+		if err := sc.UpdateUser(ctx, userId, user); err != nil {
+			return err
+		}
+	}
+
+	return updateModel(ctx, auction.ID, auction)
+}
+
+func (sc *SmartContract) productInStock(ctx contractapi.TransactionContextInterface, productId string) (bool, error) {
+	exists, err := modelExists(ctx, models.ToProductID(productId))
+	if err != nil || !exists {
+		return false, err
+	}
+
+	product, err := sc.ReadProduct(ctx, productId)
+	if err != nil {
+		return false, err
+	}
+
+	return !product.IsArchived() && product.Quantity > 0, nil
+}
+
+func (sc *SmartContract) transferAuctionedProduct(ctx contractapi.TransactionContextInterface, auction *models.Auction, winner *models.Bid, user *models.User, now time.Time) error {
+	product, err := sc.ReadProduct(ctx, auction.ProductID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := sc.ReadTrader(ctx, auction.TraderID); err != nil {
+		return err
+	}
+
+	product.Quantity -= 1
+
+	receiptId, err := nextReceiptNumber(ctx, auction.TraderID, now)
+	if err != nil {
+		return err
+	}
+
+	receipt := models.Receipt{
+		ID:        receiptId,
+		TraderID:  product.TraderID,
+		UserID:    winner.UserID,
+		ProductID: auction.ProductID,
+		Price:     winner.Amount,
+		Quantity:  1,
+		Paid:      winner.Amount,
+		Date:      now.Format(models.ReceiptDateLayout),
+	}
+
+	user.ReceiptsID = append(user.ReceiptsID, receipt.ID)
+
+	if err := storeReceipt(ctx, receipt); err != nil {
+		return err
+	}
+
+	if err := creditTrader(ctx, auction.TraderID, winner.Amount, receipt.ID); err != nil {
+		return err
+	}
+
+	if product.SoldOut() {
+		product.Archive(now)
+	}
+
+	return sc.UpdateProduct(ctx, auction.ProductID, product)
+}
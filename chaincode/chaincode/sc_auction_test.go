@@ -0,0 +1,184 @@
+package chaincode
+
+import (
+	"chaincode/chaincode/mocks"
+	"chaincode/models"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/stretchr/testify/require"
+)
+
+// newStatefulContext returns a transaction context whose stub keeps the world
+// state in the given map and reports the given transaction time.
+func newStatefulContext(state map[string][]byte, now *time.Time) (*mocks.TransactionContext, *mocks.ChaincodeStub) {
+	stub := new(mocks.ChaincodeStub)
+	ctx := new(mocks.TransactionContext)
+	ctx.GetStubReturns(stub)
+	setCaller(ctx, "Org1MSP", "client")
+
+	stub.GetStateStub = func(key string) ([]byte, error) {
+		return state[key], nil
+	}
+	stub.PutStateStub = func(key string, value []byte) error {
+		state[key] = value
+		return nil
+	}
+	stub.DelStateStub = func(key string) error {
+		delete(state, key)
+		return nil
+	}
+	stub.GetStateByRangeStub = func(startKey string, endKey string) (shim.StateQueryIteratorInterface, error) {
+		return rangeIterator(state, startKey, endKey), nil
+	}
+	stub.GetTxTimestampStub = func() (*timestamp.Timestamp, error) {
+		return &timestamp.Timestamp{Seconds: now.Unix(), Nanos: int32(now.Nanosecond())}, nil
+	}
+	stub.CreateCompositeKeyStub = shim.CreateCompositeKey
+	stub.GetStateByPartialCompositeKeyStub = func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		prefix, err := shim.CreateCompositeKey(objectType, attributes)
+		if err != nil {
+			return nil, err
+		}
+		return rangeIterator(state, prefix, prefix+string(utf8.MaxRune)), nil
+	}
+	stub.SplitCompositeKeyStub = func(key string) (string, []string, error) {
+		parts := strings.Split(strings.Trim(key, "\x00"), "\x00")
+		return parts[0], parts[1:], nil
+	}
+
+	return ctx, stub
+}
+
+// putTestModel stores the model with its index entries, as the writes of the
+// chaincode do.
+func putTestModel[T models.Model](t *testing.T, state map[string][]byte, model T) {
+	bytes, err := models.EncodeModel(model.GetID(), model)
+	require.NoError(t, err)
+
+	ctx, _ := newStatefulContext(state, &time.Time{})
+	_, err = reindex(ctx, model.GetID(), state[model.GetID()], bytes)
+	require.NoError(t, err)
+	state[model.GetID()] = bytes
+}
+
+func getTestModel[T models.Model](t *testing.T, state map[string][]byte, id string) T {
+	var model T
+	require.NoError(t, json.Unmarshal(state[id], &model))
+	return model
+}
+
+// getTestTrader returns the stored trader with its pending deltas.
+func getTestTrader(t *testing.T, ctx contractapi.TransactionContextInterface, state map[string][]byte, id string) models.Trader {
+	trader := getTestModel[models.Trader](t, state, id)
+	require.NoError(t, applyTraderDeltas(ctx, &trader))
+	return trader
+}
+
+func setupAuctionState(t *testing.T, sealed bool) (map[string][]byte, time.Time) {
+	start := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	putTestModel(t, state, models.Product{ID: "PRODUCT-ge1", Name: "Gearbox", Price: rsd(20), Quantity: 1, TraderID: "tt2"})
+	putTestModel(t, state, models.Trader{ID: "TRADER-tt2", PIB: "100000008", VerificationStatus: models.VerificationVerified, Products: []string{"PRODUCT-ge1"}, Receipts: []string{}})
+	putTestModel(t, state, models.User{ID: "USER-u1", Name: "Ana", LastName: "Jovanović", Email: "USER-u1@example.com", Balances: rsdBalances(100), ReceiptsID: []string{}})
+	putTestModel(t, state, models.User{ID: "USER-u2", Name: "Ana", LastName: "Jovanović", Email: "USER-u2@example.com", Balances: rsdBalances(100), ReceiptsID: []string{}})
+
+	auction := models.Auction{
+		ID:           "a1",
+		ProductID:    "ge1",
+		TraderID:     "tt2",
+		ReservePrice: rsd(30),
+		StartTime:    start.Format(time.RFC3339),
+		EndTime:      start.Add(time.Hour).Format(time.RFC3339),
+		Sealed:       sealed,
+	}
+	if sealed {
+		auction.RevealEndTime = start.Add(2 * time.Hour).Format(time.RFC3339)
+	}
+
+	now := start
+	ctx, _ := newStatefulContext(state, &now)
+	require.NoError(t, (&SmartContract{}).CreateAuction(ctx, auction))
+
+	return state, start
+}
+
+func TestOpenAuction(t *testing.T) {
+	sc := SmartContract{}
+	state, start := setupAuctionState(t, false)
+
+	now := start.Add(10 * time.Minute)
+	ctx, _ := newStatefulContext(state, &now)
+
+	require.Error(t, sc.PlaceBid(ctx, "a1", "u1", rsd(20)), "bid below the reserve price")
+	require.NoError(t, sc.PlaceBid(ctx, "a1", "u1", rsd(40)))
+	require.Error(t, sc.PlaceBid(ctx, "a1", "u2", rsd(40)), "bid not above the highest bid")
+	require.NoError(t, sc.PlaceBid(ctx, "a1", "u2", rsd(50)))
+	require.Error(t, sc.PlaceBid(ctx, "a1", "u1", rsd(200)), "bid above the balance")
+
+	u1 := getTestModel[models.User](t, state, "USER-u1")
+	require.Equal(t, rsd(60), u1.Balances.Get(models.DefaultCurrency))
+	require.Equal(t, rsd(40), u1.LockedBalances.Get(models.DefaultCurrency))
+
+	require.Error(t, sc.SettleAuction(ctx, "a1"), "settled before the end")
+
+	now = start.Add(time.Hour)
+	require.Error(t, sc.PlaceBid(ctx, "a1", "u1", rsd(60)), "bid after the end")
+	require.NoError(t, sc.SettleAuction(ctx, "a1"))
+
+	u1 = getTestModel[models.User](t, state, "USER-u1")
+	u2 := getTestModel[models.User](t, state, "USER-u2")
+	trader := getTestTrader(t, ctx, state, "TRADER-tt2")
+	auction := getTestModel[models.Auction](t, state, "AUCTION-a1")
+
+	require.Equal(t, rsd(100), u1.Balances.Get(models.DefaultCurrency))
+	require.Equal(t, rsd(0), u1.LockedBalances.Get(models.DefaultCurrency))
+	require.Equal(t, rsd(50), u2.Balances.Get(models.DefaultCurrency))
+	require.Equal(t, rsd(0), u2.LockedBalances.Get(models.DefaultCurrency))
+	require.Len(t, u2.ReceiptsID, 1)
+	require.Equal(t, rsd(50), trader.AccountBalance)
+	require.Equal(t, models.AuctionSettled, auction.Status)
+	require.Equal(t, "BID-a1-1", auction.WinningBidID)
+	require.True(t, getTestModel[models.Product](t, state, "PRODUCT-ge1").IsArchived())
+
+	require.Error(t, sc.SettleAuction(ctx, "a1"), "settled twice")
+}
+
+func TestSealedAuction(t *testing.T) {
+	sc := SmartContract{}
+	state, start := setupAuctionState(t, true)
+
+	now := start.Add(10 * time.Minute)
+	ctx, _ := newStatefulContext(state, &now)
+
+	require.Error(t, sc.PlaceBid(ctx, "a1", "u1", rsd(40)), "open bid on a sealed auction")
+	require.NoError(t, sc.CommitBid(ctx, "a1", "u1", models.BidCommitment(rsd(45), "s1"), rsd(80)))
+	require.NoError(t, sc.CommitBid(ctx, "a1", "u2", models.BidCommitment(rsd(60), "s2"), rsd(60)))
+	require.Error(t, sc.RevealBid(ctx, "a1-0", rsd(45), "s1"), "reveal before the end")
+
+	now = start.Add(90 * time.Minute)
+	require.Error(t, sc.RevealBid(ctx, "a1-0", rsd(46), "s1"), "reveal not matching the commitment")
+	require.NoError(t, sc.RevealBid(ctx, "a1-0", rsd(45), "s1"))
+	require.Error(t, sc.SettleAuction(ctx, "a1"), "settled during the reveal phase")
+
+	// u2 never reveals, so u1 wins with the only revealed bid
+	now = start.Add(2 * time.Hour)
+	require.NoError(t, sc.SettleAuction(ctx, "a1"))
+
+	u1 := getTestModel[models.User](t, state, "USER-u1")
+	u2 := getTestModel[models.User](t, state, "USER-u2")
+	trader := getTestTrader(t, ctx, state, "TRADER-tt2")
+
+	require.Equal(t, rsd(55), u1.Balances.Get(models.DefaultCurrency))
+	require.Equal(t, rsd(0), u1.LockedBalances.Get(models.DefaultCurrency))
+	require.Equal(t, rsd(100), u2.Balances.Get(models.DefaultCurrency))
+	require.Equal(t, rsd(0), u2.LockedBalances.Get(models.DefaultCurrency))
+	require.Equal(t, rsd(45), trader.AccountBalance)
+}
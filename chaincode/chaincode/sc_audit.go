@@ -0,0 +1,352 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"slices"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+type auditState struct {
+	users        map[string]*models.User
+	traders      map[string]*models.Trader
+	products     map[string]*models.Product
+	receipts     []*models.Receipt
+	movements    []*models.Movement
+	bids         []*models.Bid
+	reservations map[string]*models.Reservation
+	journal      []*models.JournalEntry
+}
+
+// byId indexes the entities by their id without the type prefix.
+func byId[T models.Model](entities []*T, entityType string) map[string]*T {
+	index := make(map[string]*T, len(entities))
+	for _, entity := range entities {
+		index[models.TrimKeyPrefix(entityType, (*entity).GetID())] = entity
+	}
+
+	return index
+}
+
+func loadAuditState(ctx contractapi.TransactionContextInterface) (*auditState, error) {
+	users, err := getAllOfType[models.User](ctx, models.USER_TYPE)
+	if err != nil {
+		return nil, err
+	}
+
+	traders, err := getAllOfType[models.Trader](ctx, models.TRADER_TYPE)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, trader := range traders {
+		if err := applyTraderDeltas(ctx, trader); err != nil {
+			return nil, err
+		}
+	}
+
+	products, err := getAllOfType[models.Product](ctx, models.PRODUCT_TYPE)
+	if err != nil {
+		return nil, err
+	}
+
+	receipts, err := getAllOfType[models.Receipt](ctx, models.RECEIPT_TYPE)
+	if err != nil {
+		return nil, err
+	}
+
+	movements, err := getAllOfType[models.Movement](ctx, models.MOVEMENT_TYPE)
+	if err != nil {
+		return nil, err
+	}
+
+	bids, err := getAllOfType[models.Bid](ctx, models.BID_TYPE)
+	if err != nil {
+		return nil, err
+	}
+
+	reservations, err := getAllOfType[models.Reservation](ctx, models.RESERVATION_TYPE)
+	if err != nil {
+		return nil, err
+	}
+
+	journal, err := getAllOfType[models.JournalEntry](ctx, models.JOURNAL_TYPE)
+	if err != nil {
+		return nil, err
+	}
+
+	return &auditState{
+		users:        byId(users, models.USER_TYPE),
+		traders:      byId(traders, models.TRADER_TYPE),
+		products:     byId(products, models.PRODUCT_TYPE),
+		receipts:     receipts,
+		movements:    movements,
+		bids:         bids,
+		reservations: byId(reservations, models.RESERVATION_TYPE),
+		journal:      journal,
+	}, nil
+}
+
+// AuditLedger checks the invariants of the world state and reports every
+// violation it finds. It only reads the state.
+func (sc *SmartContract) AuditLedger(ctx contractapi.TransactionContextInterface) (*models.AuditReport, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	state, err := loadAuditState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.AuditReport{
+		Checked: map[string]int{
+			models.USER_TYPE:     len(state.users),
+			models.TRADER_TYPE:   len(state.traders),
+			models.PRODUCT_TYPE:  len(state.products),
+			models.RECEIPT_TYPE:  len(state.receipts),
+			models.MOVEMENT_TYPE: len(state.movements),
+			models.JOURNAL_TYPE:  len(state.journal),
+		},
+		Violations: make([]*models.Violation, 0),
+	}
+
+	auditReceipts(state, report)
+	auditProducts(state, report)
+	auditUserBalances(state, report)
+	auditTraderBalances(state, report)
+	if err := auditJournal(state, report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// auditReceipts checks that every receipt belongs to an existing user and
+// trader and that their receipt lists hold exactly their receipts.
+func auditReceipts(state *auditState, report *models.AuditReport) {
+	userReceipts := make(map[string][]string)
+	traderReceipts := make(map[string][]string)
+
+	for _, receipt := range state.receipts {
+		receiptId := models.TrimKeyPrefix(models.RECEIPT_TYPE, receipt.ID)
+
+		if state.users[receipt.UserID] == nil {
+			report.Add(models.RuleReceiptUserMissing, receipt.ID, "user %s doesn't exist", receipt.UserID)
+		}
+
+		if state.traders[receipt.TraderID] == nil {
+			report.Add(models.RuleReceiptTraderMissing, receipt.ID, "trader %s doesn't exist", receipt.TraderID)
+		}
+
+		userReceipts[receipt.UserID] = append(userReceipts[receipt.UserID], receiptId)
+		traderReceipts[receipt.TraderID] = append(traderReceipts[receipt.TraderID], receiptId)
+	}
+
+	for userId, user := range state.users {
+		compareLists(report, models.RuleUserReceiptsMismatch, user.ID, user.ReceiptsID, userReceipts[userId])
+	}
+
+	for traderId, trader := range state.traders {
+		compareLists(report, models.RuleTraderReceiptsMismatch, trader.ID, trader.Receipts, traderReceipts[traderId])
+	}
+}
+
+// compareLists reports the ids missing from listed and the listed ids that
+// aren't expected.
+func compareLists(report *models.AuditReport, rule models.AuditRule, entityId string, listed []string, expected []string) {
+	for _, id := range expected {
+		if !slices.Contains(listed, id) {
+			report.Add(rule, entityId, "%s is missing from the list", id)
+		}
+	}
+
+	for _, id := range listed {
+		if !slices.Contains(expected, id) {
+			report.Add(rule, entityId, "%s is listed but doesn't belong to it", id)
+		}
+	}
+}
+
+// auditProducts checks that every product's trader exists and lists it, that
+// traders only list their own existing products, and that the reserved
+// quantity matches the product's active reservations.
+func auditProducts(state *auditState, report *models.AuditReport) {
+	for productId, product := range state.products {
+		trader := state.traders[product.TraderID]
+		if trader == nil {
+			report.Add(models.RuleProductOwnerMissing, product.ID, "trader %s doesn't exist", product.TraderID)
+		} else if !slices.ContainsFunc(trader.Products, func(id string) bool { return models.TrimKeyPrefix(models.PRODUCT_TYPE, id) == productId }) {
+			report.Add(models.RuleProductNotListed, product.ID, "trader %s doesn't list the product", product.TraderID)
+		}
+
+		var reserved uint
+		for _, reservationId := range product.Reservations {
+			if reservation := state.reservations[reservationId]; reservation != nil && reservation.Status == models.ReservationActive {
+				reserved += reservation.Quantity
+			}
+		}
+
+		if reserved != product.Reserved {
+			report.Add(models.RuleReservationCountInvalid, product.ID, "%d units are reserved but the active reservations hold %d", product.Reserved, reserved)
+		}
+	}
+
+	for traderId, trader := range state.traders {
+		for _, productId := range trader.Products {
+			product := state.products[models.TrimKeyPrefix(models.PRODUCT_TYPE, productId)]
+			if product == nil {
+				report.Add(models.RuleTraderProductMissing, trader.ID, "product %s doesn't exist", productId)
+			} else if product.TraderID != traderId {
+				report.Add(models.RuleTraderProductMissing, trader.ID, "product %s belongs to trader %s", productId, product.TraderID)
+			}
+		}
+	}
+}
+
+// auditUserBalances replays every user's recorded movements, which start at
+// a zero balance, and compares the result with the stored balances. Locked
+// balances must match the deposits of the user's locked bids.
+func auditUserBalances(state *auditState, report *models.AuditReport) {
+	replayed := make(map[string]models.Balances)
+	last := make(map[string]*models.Movement)
+
+	// movement keys sort in the order the movements were made
+	for _, movement := range state.movements {
+		if state.users[movement.UserID] == nil {
+			continue
+		}
+
+		balances := replayed[movement.UserID]
+		var err error
+		if movement.Direction == models.Credit {
+			err = balances.Credit(movement.Amount)
+		} else {
+			err = balances.Debit(movement.Amount)
+		}
+		replayed[movement.UserID] = balances
+
+		if err != nil {
+			report.Add(models.RuleMovementChainBroken, movement.ID, "can't be applied to the recorded balance: %v", err)
+			continue
+		}
+
+		if balances.Get(movement.Amount.Currency) != movement.Balance {
+			report.Add(models.RuleMovementChainBroken, movement.ID, "records the balance %s but the movements add up to %s", movement.Balance, balances.Get(movement.Amount.Currency))
+		}
+		last[movement.UserID] = movement
+	}
+
+	locked := make(map[string]models.Balances)
+	for _, bid := range state.bids {
+		if bid.Status == models.BidLocked {
+			balances := locked[bid.UserID]
+			_ = balances.Credit(bid.Deposit)
+			locked[bid.UserID] = balances
+		}
+	}
+
+	for userId, user := range state.users {
+		currencies := unionOf(user.Balances.Currencies(), replayed[userId].Currencies())
+		for _, currency := range currencies {
+			if user.Balances.Get(currency) != replayed[userId].Get(currency) {
+				report.Add(models.RuleUserBalanceUnexplained, user.ID, "holds %s but the recorded movements add up to %s", user.Balances.Get(currency), replayed[userId].Get(currency))
+			}
+		}
+
+		currencies = unionOf(user.LockedBalances.Currencies(), locked[userId].Currencies())
+		for _, currency := range currencies {
+			if user.LockedBalances.Get(currency) != locked[userId].Get(currency) {
+				report.Add(models.RuleUserLockedMismatch, user.ID, "has %s locked but its locked bids hold %s", user.LockedBalances.Get(currency), locked[userId].Get(currency))
+			}
+		}
+	}
+}
+
+func unionOf(a []string, b []string) []string {
+	union := append(slices.Clone(a), b...)
+	slices.Sort(union)
+	return slices.Compact(union)
+}
+
+// auditTraderBalances checks that every trader's balance is its opening
+// balance plus the prices of its receipts that weren't refunded.
+func auditTraderBalances(state *auditState, report *models.AuditReport) {
+	expected := make(map[string]models.Money)
+	for traderId, trader := range state.traders {
+		expected[traderId] = trader.OpeningBalance
+	}
+
+	for _, receipt := range state.receipts {
+		balance, ok := expected[receipt.TraderID]
+		if !ok || receipt.RefundedAt != "" {
+			continue
+		}
+
+		sum, err := balance.Add(receipt.Price)
+		if err != nil {
+			report.Add(models.RuleTraderBalanceMismatch, receipt.ID, "can't be added to the trader's balance: %v", err)
+			continue
+		}
+		expected[receipt.TraderID] = sum
+	}
+
+	for traderId, trader := range state.traders {
+		if cmp, err := trader.AccountBalance.Compare(expected[traderId]); err != nil || cmp != 0 {
+			report.Add(models.RuleTraderBalanceMismatch, trader.ID, "holds %s but its opening balance and receipts add up to %s", trader.AccountBalance, expected[traderId])
+		}
+	}
+}
+
+// auditJournal checks that every journal entry balances and that the stored
+// balances of the users and traders are what the journal posted to their
+// accounts.
+func auditJournal(state *auditState, report *models.AuditReport) error {
+	for _, entry := range state.journal {
+		if err := entry.Validate(); err != nil {
+			report.Add(models.RuleJournalUnbalanced, entry.ID, "%v", err)
+		}
+	}
+
+	totals, err := accountTotals(state.journal)
+	if err != nil {
+		return err
+	}
+
+	for userId, user := range state.users {
+		reconcileAccount(report, user.ID, totals[models.UserAccount(userId)], user.Balances)
+		reconcileAccount(report, user.ID, totals[models.LockedAccount(userId)], user.LockedBalances)
+	}
+
+	for traderId, trader := range state.traders {
+		var balances models.Balances
+		if err := balances.Credit(trader.AccountBalance); err != nil {
+			return err
+		}
+		reconcileAccount(report, trader.ID, totals[models.TraderAccount(traderId)], balances)
+	}
+
+	return nil
+}
+
+// reconcileAccount reports the currencies in which the account's credits
+// aren't its debits plus the stored balance.
+func reconcileAccount(report *models.AuditReport, entityId string, account map[string]*models.AccountTotals, balances models.Balances) {
+	currencies := balances.Currencies()
+	for currency := range account {
+		currencies = append(currencies, currency)
+	}
+	slices.Sort(currencies)
+
+	for _, currency := range slices.Compact(currencies) {
+		debits, credits := models.NewMoney(0, currency), models.NewMoney(0, currency)
+		if totals := account[currency]; totals != nil {
+			debits, credits = totals.Debits, totals.Credits
+		}
+
+		expected, err := debits.Add(balances.Get(currency))
+		if err != nil || expected != credits {
+			report.Add(models.RuleAccountUnreconciled, entityId, "holds %s but the journal credits %s and debits %s", balances.Get(currency), credits, debits)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func auditRules(report *models.AuditReport) []models.AuditRule {
+	rules := make([]models.AuditRule, 0, len(report.Violations))
+	for _, violation := range report.Violations {
+		rules = append(rules, violation.Rule)
+	}
+	return rules
+}
+
+func TestAuditLedger(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	ctx, _ := newStatefulContext(state, &now)
+	setCaller(ctx, "Org1MSP", "admin")
+
+	require.NoError(t, sc.CreateTrader(ctx, models.Trader{ID: "tt1", PIB: "100000008", Currency: "RSD", AccountBalance: rsd(5)}))
+	require.NoError(t, sc.SetTraderVerification(ctx, "tt1", string(models.VerificationVerified)))
+	require.NoError(t, sc.CreateProduct(ctx, models.Product{ID: "m1", Name: "Milk", Price: rsd(3), Quantity: 2, TraderID: "tt1"}))
+	require.NoError(t, sc.CreateProduct(ctx, models.Product{ID: "b1", Name: "Bread", Price: rsd(2), Quantity: 1, TraderID: "tt1"}))
+	require.NoError(t, sc.CreateUser(ctx, models.User{ID: "u1", Name: "Ana", LastName: "Jovanović", Email: "u1@example.com", Balances: rsdBalances(20)}))
+
+	require.NoError(t, sc.BuyProduct(ctx, "m1", "u1"))
+	require.NoError(t, sc.BuyProduct(ctx, "b1", "u1"))
+	receipt := getTestModel[models.User](t, state, "USER-u1").ReceiptsID[0]
+	require.NoError(t, sc.RefundReceipt(ctx, receipt))
+
+	report, err := sc.AuditLedger(ctx)
+	require.NoError(t, err)
+	require.Empty(t, report.Violations)
+	require.Equal(t, 2, report.Checked[models.RECEIPT_TYPE])
+
+	user := getTestModel[models.User](t, state, "USER-u1")
+	require.NoError(t, user.Balances.Credit(rsd(1)))
+	putTestModel(t, state, user)
+
+	trader := getTestModel[models.Trader](t, state, "TRADER-tt1")
+	trader.Products = append(trader.Products, "gone")
+	putTestModel(t, state, trader)
+
+	delete(state, models.ToReceiptID(receipt))
+
+	report, err = sc.AuditLedger(ctx)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []models.AuditRule{
+		models.RuleUserBalanceUnexplained,
+		models.RuleUserReceiptsMismatch,
+		models.RuleTraderReceiptsMismatch,
+		models.RuleTraderProductMissing,
+		models.RuleAccountUnreconciled,
+	}, auditRules(report))
+
+	setCaller(ctx, "Org1MSP", "client")
+	_, err = sc.AuditLedger(ctx)
+	require.Error(t, err)
+}
@@ -0,0 +1,251 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"fmt"
+	"slices"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// batchTraders reads each trader of a batch once. Reads within a
+// transaction don't see its own writes, so the traders are updated once,
+// after every item was handled.
+type batchTraders struct {
+	traders map[string]*models.Trader
+	changed []string
+}
+
+func newBatchTraders() *batchTraders {
+	return &batchTraders{traders: make(map[string]*models.Trader)}
+}
+
+func (b *batchTraders) get(ctx contractapi.TransactionContextInterface, sc *SmartContract, product *models.Product) (*models.Trader, error) {
+	if trader, ok := b.traders[product.TraderID]; ok {
+		return trader, nil
+	}
+
+	if err := checkProductReferences(ctx, product, true); err != nil {
+		return nil, err
+	}
+
+	trader, err := sc.ReadTrader(ctx, product.TraderID)
+	if err != nil {
+		return nil, err
+	}
+
+	b.traders[product.TraderID] = trader
+	return trader, nil
+}
+
+func (b *batchTraders) markChanged(traderId string) {
+	if !slices.Contains(b.changed, traderId) {
+		b.changed = append(b.changed, traderId)
+	}
+}
+
+func (b *batchTraders) update(ctx contractapi.TransactionContextInterface, sc *SmartContract) error {
+	for _, traderId := range b.changed {
+		if err := sc.UpdateTrader(ctx, traderId, b.traders[traderId]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkBatchSize(size int) error {
+	if size == 0 {
+		return fmt.Errorf("the batch is empty")
+	}
+
+	if size > models.MAX_BATCH_SIZE {
+		return fmt.Errorf("the batch has %d items, at most %d are allowed", size, models.MAX_BATCH_SIZE)
+	}
+
+	return nil
+}
+
+// CreateProducts creates the products in one transaction and reports each of
+// them. In ALL_OR_NOTHING mode nothing is created unless every product is
+// valid, in BEST_EFFORT mode the invalid products are skipped.
+func (sc *SmartContract) CreateProducts(ctx contractapi.TransactionContextInterface, products []models.Product, mode string) (*models.BatchResult, error) {
+	batchMode, err := models.ParseBatchMode(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkBatchSize(len(products)); err != nil {
+		return nil, err
+	}
+
+	result := models.NewBatchResult(batchMode, len(products))
+	traders := newBatchTraders()
+	valid := make([]models.Product, 0, len(products))
+
+	for i := range products {
+		product := products[i]
+		err := sc.prepareBatchProduct(ctx, &product, traders, valid)
+		result.Add(i, products[i].ID, err)
+		if err == nil {
+			valid = append(valid, product)
+		}
+	}
+
+	if !result.ShouldCommit() {
+		return result, nil
+	}
+
+	for _, product := range valid {
+		if err := createModel(ctx, product); err != nil {
+			return nil, err
+		}
+
+		trader := traders.traders[product.TraderID]
+		if err := setEndorsementPolicy(ctx, product.ID, trader.EndorsingOrgs()); err != nil {
+			return nil, err
+		}
+
+		productId := models.TrimKeyPrefix(models.PRODUCT_TYPE, product.ID)
+		if !slices.Contains(trader.Products, productId) {
+			trader.Products = append(trader.Products, productId)
+		}
+		traders.markChanged(product.TraderID)
+	}
+
+	if err := traders.update(ctx, sc); err != nil {
+		return nil, err
+	}
+
+	result.Committed = true
+	return result, nil
+}
+
+func (sc *SmartContract) prepareBatchProduct(ctx contractapi.TransactionContextInterface, product *models.Product, traders *batchTraders, valid []models.Product) error {
+	if err := models.Validate(product); err != nil {
+		return err
+	}
+
+	key := models.ToProductID(product.ID)
+	if slices.ContainsFunc(valid, func(p models.Product) bool { return p.ID == key }) {
+		return fmt.Errorf("product %s is in the batch more than once", product.ID)
+	}
+
+	exists, err := modelExists(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return fmt.Errorf("product %s already exists", product.ID)
+	}
+
+	if err := product.ValidatePricing(); err != nil {
+		return err
+	}
+
+	trader, err := traders.get(ctx, sc, product)
+	if err != nil {
+		return err
+	}
+
+	return sc.prepareProduct(ctx, product, trader)
+}
+
+// UpdateProducts updates the name, expiration date, pricing, stock and
+// attributes of the products in one transaction. Products stay with their
+// trader and keep their reservations. The modes are those of CreateProducts.
+func (sc *SmartContract) UpdateProducts(ctx contractapi.TransactionContextInterface, products []models.Product, mode string) (*models.BatchResult, error) {
+	batchMode, err := models.ParseBatchMode(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkBatchSize(len(products)); err != nil {
+		return nil, err
+	}
+
+	result := models.NewBatchResult(batchMode, len(products))
+	traders := newBatchTraders()
+	valid := make([]models.Product, 0, len(products))
+
+	for i := range products {
+		product, err := sc.prepareBatchUpdate(ctx, products[i], traders, valid)
+		result.Add(i, products[i].ID, err)
+		if err == nil {
+			valid = append(valid, *product)
+		}
+	}
+
+	if !result.ShouldCommit() {
+		return result, nil
+	}
+
+	for i := range valid {
+		if err := updateModel(ctx, valid[i].ID, &valid[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Committed = true
+	return result, nil
+}
+
+func (sc *SmartContract) prepareBatchUpdate(ctx contractapi.TransactionContextInterface, update models.Product, traders *batchTraders, valid []models.Product) (*models.Product, error) {
+	if update.ID == "" {
+		return nil, fmt.Errorf("product id is required")
+	}
+
+	key := models.ToProductID(update.ID)
+	if slices.ContainsFunc(valid, func(p models.Product) bool { return p.ID == key }) {
+		return nil, fmt.Errorf("product %s is in the batch more than once", update.ID)
+	}
+
+	product, err := readModel[models.Product](ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := requireActive(product, product.ID); err != nil {
+		return nil, err
+	}
+
+	if update.TraderID != "" && update.TraderID != product.TraderID {
+		return nil, fmt.Errorf("product %s belongs to trader %s and can't be moved", update.ID, product.TraderID)
+	}
+
+	update.TraderID = product.TraderID
+	if err := models.Validate(update); err != nil {
+		return nil, err
+	}
+
+	if err := update.ValidatePricing(); err != nil {
+		return nil, err
+	}
+
+	trader, err := traders.get(ctx, sc, product)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Price.Currency != trader.SettlementCurrency() {
+		return nil, fmt.Errorf("the product must be priced in the trader's currency %s", trader.SettlementCurrency())
+	}
+
+	if update.Category == "" {
+		update.Category = product.Category
+	}
+	if err := sc.checkProductAttributes(ctx, &update, trader); err != nil {
+		return nil, err
+	}
+
+	product.Name = update.Name
+	product.ExpirationDate = update.ExpirationDate
+	product.Price = update.Price
+	product.Tiers = update.Tiers
+	product.Quantity = update.Quantity
+	product.Category = update.Category
+	product.Attributes = update.Attributes
+
+	return product, nil
+}
@@ -0,0 +1,93 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateProducts(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	ctx, _ := newStatefulContext(state, &now)
+	putTestModel(t, state, models.Trader{ID: "TRADER-tt1", PIB: "100000008", VerificationStatus: models.VerificationVerified, Currency: "RSD", Products: []string{"m1"}, Receipts: []string{}})
+	putTestModel(t, state, models.Product{ID: "PRODUCT-m1", Name: "Milk", Price: rsd(3), Quantity: 1, TraderID: "tt1"})
+
+	products := []models.Product{
+		{ID: "b1", Name: "Bread", Price: rsd(2), Quantity: 5, TraderID: "tt1"},
+		{ID: "m1", Name: "Milk", Price: rsd(3), Quantity: 5, TraderID: "tt1"},
+		{ID: "e1", Name: "Eggs", Price: eur(2), Quantity: 5, TraderID: "tt1"},
+		{ID: "b1", Name: "Bread", Price: rsd(2), Quantity: 5, TraderID: "tt1"},
+		{ID: "c1", Name: "Cheese", Price: rsd(9), Quantity: 5, TraderID: "tt9"},
+		{ID: "t1", Name: "Tomato", Price: rsd(1), Quantity: 5, TraderID: "tt1"},
+	}
+
+	_, err := sc.CreateProducts(ctx, products, "SOME")
+	require.ErrorContains(t, err, "unknown batch mode")
+
+	result, err := sc.CreateProducts(ctx, products, string(models.BatchAllOrNothing))
+	require.NoError(t, err)
+	require.False(t, result.Committed)
+	require.Equal(t, 2, result.Valid)
+	require.Equal(t, 4, result.Failed)
+	require.NotContains(t, state, "PRODUCT-b1")
+
+	require.Equal(t, []bool{true, false, false, false, false, true}, []bool{result.Items[0].Valid, result.Items[1].Valid, result.Items[2].Valid, result.Items[3].Valid, result.Items[4].Valid, result.Items[5].Valid})
+	require.Contains(t, result.Items[1].Error, "already exists")
+	require.Contains(t, result.Items[2].Error, "trader's currency")
+	require.Contains(t, result.Items[3].Error, "more than once")
+	require.Contains(t, result.Items[4].Error, "doesn't exist")
+
+	result, err = sc.CreateProducts(ctx, products, string(models.BatchBestEffort))
+	require.NoError(t, err)
+	require.True(t, result.Committed)
+	require.Equal(t, 2, result.Valid)
+
+	require.Equal(t, uint(5), getTestModel[models.Product](t, state, "PRODUCT-b1").Quantity)
+	require.Equal(t, uint(5), getTestModel[models.Product](t, state, "PRODUCT-t1").Quantity)
+	require.Equal(t, []string{"m1", "b1", "t1"}, getTestModel[models.Trader](t, state, "TRADER-tt1").Products)
+	require.NotContains(t, state, "PRODUCT-e1")
+
+	_, err = sc.CreateProducts(ctx, make([]models.Product, models.MAX_BATCH_SIZE+1), string(models.BatchBestEffort))
+	require.ErrorContains(t, err, "at most")
+}
+
+func TestUpdateProducts(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	ctx, _ := newStatefulContext(state, &now)
+	putTestModel(t, state, models.Trader{ID: "TRADER-tt1", PIB: "100000008", VerificationStatus: models.VerificationVerified, Currency: "RSD", Products: []string{"m1", "b1"}, Receipts: []string{}})
+	putTestModel(t, state, models.Product{ID: "PRODUCT-m1", Name: "Milk", Price: rsd(3), Quantity: 1, Reserved: 2, Reservations: []string{"RESERVATION-r1"}, TraderID: "tt1"})
+	putTestModel(t, state, models.Product{ID: "PRODUCT-b1", Name: "Bread", Price: rsd(2), Quantity: 1, TraderID: "tt1"})
+
+	updates := []models.Product{
+		{ID: "m1", Name: "Milk 1l", Price: rsd(4), Quantity: 10},
+		{ID: "b1", Name: "Bread", Price: rsd(2), Quantity: 3, TraderID: "tt2"},
+		{ID: "x1", Name: "Unknown", Price: rsd(2), Quantity: 3},
+	}
+
+	result, err := sc.UpdateProducts(ctx, updates, string(models.BatchAllOrNothing))
+	require.NoError(t, err)
+	require.False(t, result.Committed)
+	require.Contains(t, result.Items[1].Error, "can't be moved")
+	require.Equal(t, "Milk", getTestModel[models.Product](t, state, "PRODUCT-m1").Name)
+
+	result, err = sc.UpdateProducts(ctx, updates, string(models.BatchBestEffort))
+	require.NoError(t, err)
+	require.True(t, result.Committed)
+	require.Equal(t, 1, result.Valid)
+
+	milk := getTestModel[models.Product](t, state, "PRODUCT-m1")
+	require.Equal(t, "Milk 1l", milk.Name)
+	require.Equal(t, rsd(4), milk.Price)
+	require.Equal(t, uint(10), milk.Quantity)
+	require.Equal(t, uint(2), milk.Reserved, "reservations are kept")
+	require.Equal(t, "tt1", milk.TraderID)
+	require.Equal(t, uint(1), getTestModel[models.Product](t, state, "PRODUCT-b1").Quantity)
+}
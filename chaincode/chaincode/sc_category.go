@@ -0,0 +1,82 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func (sc *SmartContract) ReadCategory(ctx contractapi.TransactionContextInterface, id string) (*models.Category, error) {
+	return readModel[models.Category](ctx, models.ToCategoryID(id))
+}
+
+func (sc *SmartContract) GetAllCategories(ctx contractapi.TransactionContextInterface) ([]*models.Category, error) {
+	return getAllOfType[models.Category](ctx, models.CATEGORY_TYPE)
+}
+
+// CreateCategory registers a trader category with the schema of the
+// attributes of its products.
+func (sc *SmartContract) CreateCategory(ctx contractapi.TransactionContextInterface, category models.Category) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	if err := models.Validate(category); err != nil {
+		return err
+	}
+
+	category.ID = models.ToCategoryID(category.ID)
+	if err := category.ValidateSchema(); err != nil {
+		return err
+	}
+
+	return createModel(ctx, category)
+}
+
+// UpdateCategory replaces the category's name, description and schema. The
+// new schema applies to products created from then on, stored products keep
+// their attributes.
+func (sc *SmartContract) UpdateCategory(ctx contractapi.TransactionContextInterface, id string, category models.Category) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	category.ID = models.ToCategoryID(id)
+	if err := models.Validate(category); err != nil {
+		return err
+	}
+
+	if err := category.ValidateSchema(); err != nil {
+		return err
+	}
+
+	return updateModel(ctx, category.ID, &category)
+}
+
+// checkProductAttributes files the product under the category of its trader
+// and validates its attributes against the category's schema. Products of
+// traders without a category can't have attributes.
+func (sc *SmartContract) checkProductAttributes(ctx contractapi.TransactionContextInterface, product *models.Product, trader *models.Trader) error {
+	if product.Category == "" {
+		product.Category = string(trader.TraderType)
+	}
+
+	if product.Category != string(trader.TraderType) {
+		return fmt.Errorf("product category %s doesn't match the category %s of trader %s", product.Category, trader.TraderType, trader.ID)
+	}
+
+	if product.Category == "" {
+		if len(product.Attributes) > 0 {
+			return fmt.Errorf("trader %s has no category, so its products can't have attributes", trader.ID)
+		}
+		return nil
+	}
+
+	category, err := sc.ReadCategory(ctx, product.Category)
+	if err != nil {
+		return err
+	}
+
+	return category.ValidateAttributes(product.Attributes)
+}
@@ -0,0 +1,66 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategoryAttributes(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	ctx, _ := newStatefulContext(state, &now)
+	setCaller(ctx, "Org1MSP", "admin")
+
+	books := models.Category{ID: "BOOKS", Name: "Books", AttributesSchema: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"isbn":  map[string]any{"type": "string", "pattern": "^[0-9]{13}$"},
+			"pages": map[string]any{"type": "integer", "minimum": 1},
+		},
+		"required": []any{"isbn"},
+	}}
+
+	require.ErrorContains(t, sc.CreateCategory(ctx, models.Category{ID: "REMOTE", Name: "Remote", AttributesSchema: map[string]any{"$ref": "https://example.com/schema.json"}}), "only references within the schema")
+	require.Error(t, sc.CreateCategory(ctx, models.Category{ID: "BROKEN", Name: "Broken", AttributesSchema: map[string]any{"type": 5}}))
+	require.NoError(t, sc.CreateCategory(ctx, books))
+	require.Error(t, sc.CreateCategory(ctx, books), "registered twice")
+
+	require.ErrorContains(t, sc.CreateTrader(ctx, models.Trader{ID: "tt1", TraderType: "TOYS", PIB: "100000008", Currency: "RSD"}), "unknown trader category")
+	require.NoError(t, sc.CreateTrader(ctx, models.Trader{ID: "tt1", TraderType: "BOOKS", PIB: "100000008", Currency: "RSD"}))
+	require.NoError(t, sc.CreateTrader(ctx, models.Trader{ID: "tt2", PIB: "100000016", Currency: "RSD"}))
+
+	book := func(id string, attributes map[string]any) models.Product {
+		return models.Product{ID: id, Name: "Book", Price: rsd(10), Quantity: 1, TraderID: "tt1", Attributes: attributes}
+	}
+
+	require.ErrorContains(t, sc.CreateProduct(ctx, book("b1", nil)), "isbn is required")
+	require.ErrorContains(t, sc.CreateProduct(ctx, book("b1", map[string]any{"isbn": "97886", "pages": 0.5})), "pages")
+	require.NoError(t, sc.CreateProduct(ctx, book("b1", map[string]any{"isbn": "9788652135881", "pages": 320})))
+	require.Equal(t, "BOOKS", getTestModel[models.Product](t, state, "PRODUCT-b1").Category)
+
+	mislabeled := book("b2", map[string]any{"isbn": "9788652135881"})
+	mislabeled.Category = string(models.Market)
+	require.ErrorContains(t, sc.CreateProduct(ctx, mislabeled), "doesn't match the category")
+
+	// traders without a category sell products without attributes
+	require.NoError(t, sc.CreateProduct(ctx, models.Product{ID: "m1", Name: "Milk", Price: rsd(3), Quantity: 1, TraderID: "tt2"}))
+	require.ErrorContains(t, sc.CreateProduct(ctx, models.Product{ID: "m2", Name: "Milk", Price: rsd(3), Quantity: 1, TraderID: "tt2", Attributes: map[string]any{"weight_unit": "l"}}), "no category")
+
+	// a looser schema applies to the next products
+	books.AttributesSchema = map[string]any{"type": "object"}
+	require.NoError(t, sc.UpdateCategory(ctx, "BOOKS", books))
+	require.NoError(t, sc.CreateProduct(ctx, book("b3", nil)))
+
+	categories, err := sc.GetAllCategories(ctx)
+	require.NoError(t, err)
+	require.Len(t, categories, 1)
+
+	setCaller(ctx, "Org1MSP", "client")
+	require.Error(t, sc.CreateCategory(ctx, models.Category{ID: "TOYS", Name: "Toys"}))
+	require.Error(t, sc.UpdateCategory(ctx, "BOOKS", books))
+}
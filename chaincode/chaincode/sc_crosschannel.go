@@ -0,0 +1,145 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// invokeRemote calls a function of the chaincode installed on another
+// channel. Fabric only allows reads across channels, so anything the remote
+// function writes is discarded.
+func invokeRemote(ctx contractapi.TransactionContextInterface, chaincodeName string, channel string, function string, args ...string) ([]byte, error) {
+	invokeArgs := [][]byte{[]byte(function)}
+	for _, arg := range args {
+		invokeArgs = append(invokeArgs, []byte(arg))
+	}
+
+	response := ctx.GetStub().InvokeChaincode(chaincodeName, invokeArgs, channel)
+	if response.Status != shim.OK {
+		return nil, fmt.Errorf("failed to invoke %s on %s/%s: %s", function, channel, chaincodeName, response.Message)
+	}
+
+	return response.Payload, nil
+}
+
+// GetUserSummary returns the user with its receipts and the products it
+// bought, each listed once.
+func (sc *SmartContract) GetUserSummary(ctx contractapi.TransactionContextInterface, userId string) (*models.UserSummary, error) {
+	user, err := sc.ReadUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.UserSummary{
+		Channel:  ctx.GetStub().GetChannelID(),
+		User:     user,
+		Receipts: make([]*models.Receipt, 0, len(user.ReceiptsID)),
+		Products: make([]*models.Product, 0),
+	}
+
+	seenProducts := make(map[string]bool)
+	for _, receiptId := range user.ReceiptsID {
+		receipt, err := sc.ReadReceipt(ctx, receiptId)
+		if err != nil {
+			return nil, err
+		}
+		summary.Receipts = append(summary.Receipts, receipt)
+
+		if seenProducts[receipt.ProductID] {
+			continue
+		}
+		seenProducts[receipt.ProductID] = true
+
+		// sold out products are archived and still listed, with their
+		// archived status; only purged products are gone from the ledger
+		exists, err := modelExists(ctx, models.ToProductID(receipt.ProductID))
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+
+		product, err := sc.ReadProduct(ctx, receipt.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		summary.Products = append(summary.Products, product)
+	}
+
+	return summary, nil
+}
+
+func (sc *SmartContract) GetRemoteUserSummary(ctx contractapi.TransactionContextInterface, chaincodeName string, channel string, userId string) (*models.UserSummary, error) {
+	payload, err := invokeRemote(ctx, chaincodeName, channel, "GetUserSummary", userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var summary models.UserSummary
+	if err := json.Unmarshal(payload, &summary); err != nil {
+		return nil, fmt.Errorf("failed to deserialize the remote summary: %v", err)
+	}
+
+	return &summary, nil
+}
+
+func (sc *SmartContract) GetRemoteProducts(ctx contractapi.TransactionContextInterface, chaincodeName string, channel string) ([]*models.Product, error) {
+	payload, err := invokeRemote(ctx, chaincodeName, channel, "ProductContract:GetAllProducts")
+	if err != nil {
+		return nil, err
+	}
+
+	var products []*models.Product
+	if err := json.Unmarshal(payload, &products); err != nil {
+		return nil, fmt.Errorf("failed to deserialize the remote products: %v", err)
+	}
+
+	return products, nil
+}
+
+// GetUserOverview aggregates the user's summary on this channel with the
+// summaries read from the remote channels, given as channel -> chaincode name.
+func (sc *SmartContract) GetUserOverview(ctx contractapi.TransactionContextInterface, userId string, remotes map[string]string) (*models.UserOverview, error) {
+	local, err := sc.GetUserSummary(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	overview := &models.UserOverview{UserID: userId, ChannelSummary: []*models.UserSummary{local}}
+
+	channels := make([]string, 0, len(remotes))
+	for channel := range remotes {
+		if channel != local.Channel {
+			channels = append(channels, channel)
+		}
+	}
+	sort.Strings(channels)
+
+	for _, channel := range channels {
+		summary, err := sc.GetRemoteUserSummary(ctx, remotes[channel], channel, userId)
+		if err != nil {
+			return nil, err
+		}
+		overview.ChannelSummary = append(overview.ChannelSummary, summary)
+	}
+
+	for _, summary := range overview.ChannelSummary {
+		if overview.TotalBalance, err = overview.TotalBalance.Add(summary.User.Balances); err != nil {
+			return nil, err
+		}
+
+		if overview.TotalLocked, err = overview.TotalLocked.Add(summary.User.LockedBalances); err != nil {
+			return nil, err
+		}
+
+		overview.TotalReceipts += len(summary.Receipts)
+	}
+
+	return overview, nil
+}
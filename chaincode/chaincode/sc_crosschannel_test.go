@@ -0,0 +1,65 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUserOverview(t *testing.T) {
+	sc := SmartContract{}
+	state := map[string][]byte{}
+	now := time.Now()
+
+	putTestModel(t, state, models.User{ID: "USER-u1", Name: "Ana", LastName: "Jovanović", Email: "USER-u1@example.com", Balances: rsdBalances(40), ReceiptsID: []string{"r1", "r4", "r5", "r6"}})
+	putTestModel(t, state, models.Receipt{ID: "RECEIPT-r1", UserID: "u1", TraderID: "tt1", ProductID: "t1"})
+	putTestModel(t, state, models.Receipt{ID: "RECEIPT-r4", UserID: "u1", TraderID: "tt1", ProductID: "t1"})
+	putTestModel(t, state, models.Receipt{ID: "RECEIPT-r5", UserID: "u1", TraderID: "tt1", ProductID: "b1"})
+	putTestModel(t, state, models.Receipt{ID: "RECEIPT-r6", UserID: "u1", TraderID: "tt1", ProductID: "m1"})
+	putTestModel(t, state, models.Product{ID: "PRODUCT-t1", Name: "Tomato", Price: rsd(2), Quantity: 9, TraderID: "tt1"})
+	// b1 sold out and was archived, m1 was purged
+	soldOut := models.Product{ID: "PRODUCT-b1", Name: "Bread", Price: rsd(1), TraderID: "tt1"}
+	soldOut.Archive(now)
+	putTestModel(t, state, soldOut)
+
+	ctx, stub := newStatefulContext(state, &now)
+	stub.GetChannelIDReturns("tradechannel1")
+
+	remote := models.UserSummary{
+		Channel:  "tradechannel2",
+		User:     &models.User{ID: "USER-u1", Name: "Ana", LastName: "Jovanović", Email: "USER-u1@example.com", Balances: rsdBalances(60), LockedBalances: rsdBalances(5)},
+		Receipts: []*models.Receipt{{ID: "RECEIPT-r2"}, {ID: "RECEIPT-r3"}},
+	}
+	payload, err := json.Marshal(remote)
+	require.NoError(t, err)
+	stub.InvokeChaincodeReturns(peer.Response{Status: 200, Payload: payload})
+
+	overview, err := sc.GetUserOverview(ctx, "u1", map[string]string{
+		"tradechannel1": "traderchaincode1",
+		"tradechannel2": "traderchaincode2",
+	})
+	require.NoError(t, err)
+	require.Len(t, overview.ChannelSummary, 2)
+	products := overview.ChannelSummary[0].Products
+	require.Len(t, products, 2)
+	require.Equal(t, "PRODUCT-t1", products[0].ID)
+	require.Equal(t, "PRODUCT-b1", products[1].ID)
+	require.True(t, products[1].IsArchived())
+	require.Equal(t, rsdBalances(100), overview.TotalBalance)
+	require.Equal(t, rsdBalances(5), overview.TotalLocked)
+	require.Equal(t, 6, overview.TotalReceipts)
+
+	require.Equal(t, 1, stub.InvokeChaincodeCallCount())
+	chaincodeName, args, channel := stub.InvokeChaincodeArgsForCall(0)
+	require.Equal(t, "traderchaincode2", chaincodeName)
+	require.Equal(t, "tradechannel2", channel)
+	require.Equal(t, [][]byte{[]byte("GetUserSummary"), []byte("u1")}, args)
+
+	stub.InvokeChaincodeReturns(peer.Response{Status: 500, Message: "no such user"})
+	_, err = sc.GetUserOverview(ctx, "u1", map[string]string{"tradechannel2": "traderchaincode2"})
+	require.Error(t, err)
+}
@@ -0,0 +1,237 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"fmt"
+	"slices"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// creditTrader records the sale of the receipt in a delta of the trader
+// instead of writing the trader's document. The trader's concurrent sales
+// still conflict on its receipt counter, see TraderDelta.
+func creditTrader(ctx contractapi.TransactionContextInterface, traderId string, amount models.Money, receiptId string) error {
+	return putTraderDelta(ctx, models.TraderDelta{
+		TraderID:  models.ToTraderID(traderId),
+		Direction: models.Credit,
+		Amount:    amount,
+		ReceiptID: receiptId,
+	})
+}
+
+// debitTrader checks that the trader's balance, deltas included, covers the
+// refund of the receipt and records it in a delta.
+func debitTrader(ctx contractapi.TransactionContextInterface, trader *models.Trader, amount models.Money, receiptId string) error {
+	current := *trader
+	current.Receipts = slices.Clone(trader.Receipts)
+	if err := applyTraderDeltas(ctx, &current); err != nil {
+		return err
+	}
+
+	if err := current.Debit(amount); err != nil {
+		return err
+	}
+
+	return putTraderDelta(ctx, models.TraderDelta{
+		TraderID:  trader.ID,
+		Direction: models.Debit,
+		Amount:    amount,
+		ReceiptID: receiptId,
+	})
+}
+
+func putTraderDelta(ctx contractapi.TransactionContextInterface, delta models.TraderDelta) error {
+	stub := ctx.GetStub()
+	delta.TxID = stub.GetTxID()
+
+	key, err := stub.CreateCompositeKey(models.TRADER_DELTA_TYPE, []string{delta.TraderID, delta.ReceiptID, string(delta.Direction)})
+	if err != nil {
+		return fmt.Errorf("failed to create the key of the delta of %s: %v", delta.TraderID, err)
+	}
+	delta.ID = key
+
+	deltaJson, err := models.EncodeModel(key, delta)
+	if err != nil {
+		return err
+	}
+
+	if err := stub.PutState(key, deltaJson); err != nil {
+		return fmt.Errorf("failed to put to world state: %v", err)
+	}
+
+	return nil
+}
+
+// getTraderDeltas returns the pending deltas of the trader, or of every
+// trader when traderKey is empty, ordered by trader.
+func getTraderDeltas(ctx contractapi.TransactionContextInterface, traderKey string) ([]models.TraderDelta, error) {
+	attributes := []string{}
+	if traderKey != "" {
+		attributes = append(attributes, traderKey)
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(models.TRADER_DELTA_TYPE, attributes)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	deltas := make([]models.TraderDelta, 0)
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var delta models.TraderDelta
+		if err := models.DecodeModel(queryResponse.Key, queryResponse.Value, &delta); err != nil {
+			return nil, fmt.Errorf("failed to deserialize %s: %v", queryResponse.Key, err)
+		}
+		deltas = append(deltas, delta)
+	}
+
+	return deltas, nil
+}
+
+// applyTraderDeltas adds the trader's pending deltas to its balance and
+// receipts.
+func applyTraderDeltas(ctx contractapi.TransactionContextInterface, trader *models.Trader) error {
+	deltas, err := getTraderDeltas(ctx, trader.ID)
+	if err != nil {
+		return err
+	}
+
+	return applyDeltas(trader, deltas)
+}
+
+// applyDeltas applies the credits before the debits, so that a refund never
+// comes before the sale it refunds.
+func applyDeltas(trader *models.Trader, deltas []models.TraderDelta) error {
+	for _, direction := range []models.MovementDirection{models.Credit, models.Debit} {
+		for _, delta := range deltas {
+			if delta.Direction != direction {
+				continue
+			}
+
+			if err := trader.Apply(delta); err != nil {
+				return fmt.Errorf("failed to apply the %s of receipt %s to %s: %v", delta.Direction, delta.ReceiptID, trader.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// CompactTraderDeltas folds the pending deltas into their traders' documents
+// and deletes them. It's meant to run periodically, a trader written by the
+// compaction conflicts with its sales in the same block. It reads at most
+// limit deltas, so a trader's deltas can be split between two runs. The
+// deltas of a receipt are keyed so its sale comes before its refund, a run
+// never folds a refund without its sale.
+func (sc *SmartContract) CompactTraderDeltas(ctx contractapi.TransactionContextInterface, limit int) (*models.CompactionResult, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+
+	// writes aren't allowed after a paginated query, so the scan stops at
+	// the limit itself
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(models.TRADER_DELTA_TYPE, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	result := &models.CompactionResult{Done: true}
+	fold := func(deltas []models.TraderDelta) error {
+		if len(deltas) == 0 {
+			return nil
+		}
+
+		if err := sc.foldTraderDeltas(ctx, deltas); err != nil {
+			return err
+		}
+
+		result.Deltas += len(deltas)
+		result.Traders++
+		return nil
+	}
+
+	deltas := make([]models.TraderDelta, 0)
+	for resultsIterator.HasNext() {
+		if result.Deltas+len(deltas) == limit {
+			result.Done = false
+			break
+		}
+
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var delta models.TraderDelta
+		if err := models.DecodeModel(queryResponse.Key, queryResponse.Value, &delta); err != nil {
+			return nil, fmt.Errorf("failed to deserialize %s: %v", queryResponse.Key, err)
+		}
+
+		if len(deltas) > 0 && deltas[0].TraderID != delta.TraderID {
+			if err := fold(deltas); err != nil {
+				return nil, err
+			}
+			deltas = make([]models.TraderDelta, 0)
+		}
+		deltas = append(deltas, delta)
+	}
+
+	if err := fold(deltas); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (sc *SmartContract) foldTraderDeltas(ctx contractapi.TransactionContextInterface, deltas []models.TraderDelta) error {
+	trader, err := readModel[models.Trader](ctx, deltas[0].TraderID)
+	if err != nil {
+		return err
+	}
+
+	if err := applyDeltas(trader, deltas); err != nil {
+		return err
+	}
+
+	if err := updateModel(ctx, trader.ID, trader); err != nil {
+		return err
+	}
+
+	return deleteTraderDeltas(ctx, deltas)
+}
+
+// foldPendingDeltas applies the trader's pending deltas to the trader and
+// deletes them; the caller stores the trader.
+func foldPendingDeltas(ctx contractapi.TransactionContextInterface, trader *models.Trader) error {
+	deltas, err := getTraderDeltas(ctx, trader.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := applyDeltas(trader, deltas); err != nil {
+		return err
+	}
+
+	return deleteTraderDeltas(ctx, deltas)
+}
+
+func deleteTraderDeltas(ctx contractapi.TransactionContextInterface, deltas []models.TraderDelta) error {
+	for _, delta := range deltas {
+		if err := ctx.GetStub().DelState(delta.ID); err != nil {
+			return fmt.Errorf("failed to delete %s: %v", delta.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package chaincode
+
+import (
+	"chaincode/chaincode/mocks"
+	"chaincode/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraderDeltas(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	putTestModel(t, state, models.User{ID: "USER-u1", Name: "Ana", LastName: "Jovanović", Email: "USER-u1@example.com", Balances: rsdBalances(100), ReceiptsID: []string{}})
+	putTestModel(t, state, models.Trader{ID: "TRADER-tt1", PIB: "100000008", VerificationStatus: models.VerificationVerified, Currency: "RSD", AccountBalance: rsd(1), Products: []string{"m1", "b1"}, Receipts: []string{}})
+	putTestModel(t, state, models.Trader{ID: "TRADER-tt2", PIB: "100000016", VerificationStatus: models.VerificationVerified, Currency: "RSD", Products: []string{"t1"}, Receipts: []string{}})
+	putTestModel(t, state, models.Product{ID: "PRODUCT-m1", Name: "Milk", Price: rsd(3), Quantity: 10, TraderID: "tt1"})
+	putTestModel(t, state, models.Product{ID: "PRODUCT-b1", Name: "Bread", Price: rsd(2), Quantity: 10, TraderID: "tt1"})
+	putTestModel(t, state, models.Product{ID: "PRODUCT-t1", Name: "Tomato", Price: rsd(4), Quantity: 10, TraderID: "tt2"})
+
+	ctx, stub := newStatefulContext(state, &now)
+	setCaller(ctx, "Org1MSP", adminOU)
+
+	require.NoError(t, sc.BuyProduct(ctx, "m1", "u1"))
+	require.NoError(t, sc.BuyProduct(ctx, "b1", "u1"))
+	require.NoError(t, sc.BuyProduct(ctx, "t1", "u1"))
+
+	for i := 0; i < stub.PutStateCallCount(); i++ {
+		key, value := stub.PutStateArgsForCall(i)
+		require.False(t, strings.HasPrefix(key, models.TRADER_TYPE+"-"), "a sale wrote %s", key)
+
+		// the deltas and the journal entries are stamped like the models
+		if entityType := models.EntityType(key); entityType == models.TRADER_DELTA_TYPE || entityType == models.JOURNAL_TYPE {
+			require.Contains(t, string(value), `"schema_version":1`, key)
+		}
+	}
+
+	stored := getTestModel[models.Trader](t, state, "TRADER-tt1")
+	require.Equal(t, rsd(1), stored.AccountBalance)
+	require.Empty(t, stored.Receipts)
+
+	trader := getTestTrader(t, ctx, state, "TRADER-tt1")
+	require.Equal(t, rsd(6), trader.AccountBalance)
+	require.Len(t, trader.Receipts, 2)
+
+	receipts := getTestModel[models.User](t, state, "USER-u1").ReceiptsID
+	require.NoError(t, sc.RefundReceipt(ctx, receipts[0]))
+	require.Equal(t, rsd(3), getTestTrader(t, ctx, state, "TRADER-tt1").AccountBalance)
+
+	// the trader can't refund more than its balance and deltas hold
+	require.NoError(t, sc.BuyProduct(ctx, "t1", "u1"))
+	tt2 := getTestModel[models.Trader](t, state, "TRADER-tt2")
+	tt2.AccountBalance = rsd(0)
+	putTestModel(t, state, tt2)
+	require.NoError(t, sc.RefundReceipt(ctx, receipts[2]))
+
+	receiptId := getTestModel[models.User](t, state, "USER-u1").ReceiptsID[3]
+	receipt := getTestModel[models.Receipt](t, state, models.ToReceiptID(receiptId))
+	receipt.Price, receipt.Paid = rsd(5), rsd(5)
+	putTestModel(t, state, receipt)
+	require.ErrorContains(t, sc.RefundReceipt(ctx, receiptId), "can't refund")
+	receipt.Price, receipt.Paid = rsd(4), rsd(4)
+	putTestModel(t, state, receipt)
+
+	_, err := sc.CompactTraderDeltas(ctx, 0)
+	require.Error(t, err)
+
+	var scan *mocks.StateQueryIterator
+	partialKeyQuery := stub.GetStateByPartialCompositeKeyStub
+	stub.GetStateByPartialCompositeKeyStub = func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		iterator, err := partialKeyQuery(objectType, attributes)
+		if len(attributes) == 0 {
+			scan = iterator.(*mocks.StateQueryIterator)
+		}
+		return iterator, err
+	}
+
+	// the limit bounds the deltas read and can split a trader's deltas, the
+	// rest stay pending
+	result, err := sc.CompactTraderDeltas(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, &models.CompactionResult{Deltas: 1, Traders: 1, Done: false}, result)
+	require.Equal(t, 1, scan.NextCallCount())
+
+	stored = getTestModel[models.Trader](t, state, "TRADER-tt1")
+	require.Equal(t, rsd(4), stored.AccountBalance)
+	require.Len(t, stored.Receipts, 1)
+	require.Equal(t, rsd(3), getTestTrader(t, ctx, state, "TRADER-tt1").AccountBalance)
+
+	result, err = sc.CompactTraderDeltas(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, &models.CompactionResult{Deltas: 2, Traders: 1, Done: false}, result)
+
+	stored = getTestModel[models.Trader](t, state, "TRADER-tt1")
+	require.Equal(t, rsd(3), stored.AccountBalance)
+	require.Len(t, stored.Receipts, 2)
+	require.Equal(t, stored, getTestTrader(t, ctx, state, "TRADER-tt1"))
+
+	result, err = sc.CompactTraderDeltas(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, &models.CompactionResult{Deltas: 3, Traders: 1, Done: true}, result)
+	require.Equal(t, rsd(4), getTestModel[models.Trader](t, state, "TRADER-tt2").AccountBalance)
+
+	for key := range state {
+		require.False(t, strings.Contains(key, models.TRADER_DELTA_TYPE), "%q wasn't compacted", key)
+	}
+
+	report, err := sc.AuditLedger(ctx)
+	require.NoError(t, err)
+	require.NotContains(t, auditRules(report), models.RuleTraderReceiptsMismatch)
+}
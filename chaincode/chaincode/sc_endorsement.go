@@ -0,0 +1,122 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/statebased"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// TransferTraderOwnership makes the organization the owner of the trader, so
+// changes to the trader and its products need the endorsement of its peers.
+// Traders stored before they had owners get one the same way. The transfer
+// itself is validated against the policy it replaces, so the peers of the
+// previous owner have to endorse it.
+func (sc *SmartContract) TransferTraderOwnership(ctx contractapi.TransactionContextInterface, id string, mspId string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(mspId) == "" {
+		return fmt.Errorf("the new owner's msp is required")
+	}
+
+	trader, err := sc.ReadTrader(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	trader.OwnerMSP = mspId
+	trader.Endorsers = slices.DeleteFunc(trader.Endorsers, func(org string) bool {
+		return org == mspId
+	})
+
+	if err := updateModel(ctx, trader.ID, trader); err != nil {
+		return err
+	}
+
+	return setTraderEndorsement(ctx, trader)
+}
+
+// SetTraderEndorsers replaces the organizations that have to endorse changes
+// to the trader's assets along with its owner.
+func (sc *SmartContract) SetTraderEndorsers(ctx contractapi.TransactionContextInterface, id string, endorsers []string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	for _, org := range endorsers {
+		if strings.TrimSpace(org) == "" {
+			return fmt.Errorf("the endorsers' msps must not be blank")
+		}
+	}
+
+	trader, err := sc.ReadTrader(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if trader.OwnerMSP == "" {
+		return fmt.Errorf("trader %s has no owner, transfer it to an organization first", id)
+	}
+
+	trader.Endorsers = slices.DeleteFunc(slices.Clone(endorsers), func(org string) bool {
+		return org == trader.OwnerMSP
+	})
+
+	if err := updateModel(ctx, trader.ID, trader); err != nil {
+		return err
+	}
+
+	return setTraderEndorsement(ctx, trader)
+}
+
+// setTraderEndorsement sets the trader's endorsement policy on it and on its
+// products.
+func setTraderEndorsement(ctx contractapi.TransactionContextInterface, trader *models.Trader) error {
+	orgs := trader.EndorsingOrgs()
+
+	if err := setEndorsementPolicy(ctx, trader.ID, orgs); err != nil {
+		return err
+	}
+
+	for _, productId := range trader.Products {
+		if err := setEndorsementPolicy(ctx, models.ToProductID(productId), orgs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setEndorsementPolicy requires the peers of every one of the organizations
+// to endorse changes to the key. Keys without organizations keep the
+// chaincode's endorsement policy.
+func setEndorsementPolicy(ctx contractapi.TransactionContextInterface, key string, orgs []string) error {
+	if len(orgs) == 0 {
+		return nil
+	}
+
+	endorsement, err := statebased.NewStateEP(nil)
+	if err != nil {
+		return err
+	}
+
+	if err := endorsement.AddOrgs(statebased.RoleTypePeer, orgs...); err != nil {
+		return err
+	}
+
+	policy, err := endorsement.Policy()
+	if err != nil {
+		return fmt.Errorf("failed to create the endorsement policy of %s: %v", key, err)
+	}
+
+	if err := ctx.GetStub().SetStateValidationParameter(key, policy); err != nil {
+		return fmt.Errorf("failed to set the endorsement policy of %s: %v", key, err)
+	}
+
+	return nil
+}
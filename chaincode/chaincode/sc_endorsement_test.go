@@ -0,0 +1,83 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/statebased"
+	"github.com/stretchr/testify/require"
+)
+
+func endorsingOrgs(t *testing.T, policies map[string][]byte, key string) []string {
+	endorsement, err := statebased.NewStateEP(policies[key])
+	require.NoError(t, err)
+
+	orgs := endorsement.ListOrgs()
+	slices.Sort(orgs)
+	return orgs
+}
+
+func TestTraderEndorsement(t *testing.T) {
+	now := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+	ctx, stub := newStatefulContext(state, &now)
+	sc := SmartContract{}
+
+	policies := map[string][]byte{}
+	stub.SetStateValidationParameterStub = func(key string, policy []byte) error {
+		policies[key] = policy
+		return nil
+	}
+
+	err := sc.CreateTrader(ctx, models.Trader{ID: "tt1", PIB: "100000008", Currency: "RSD", OwnerMSP: "Org2MSP"})
+	require.ErrorContains(t, err, "only admins")
+
+	require.NoError(t, sc.CreateTrader(ctx, models.Trader{ID: "tt1", PIB: "100000008", Currency: "RSD", Endorsers: []string{"Org3MSP"}}))
+	require.NoError(t, sc.CreateProduct(ctx, models.Product{ID: "m1", Name: "Milk", Price: rsd(3), Quantity: 5, TraderID: "tt1"}))
+
+	trader := getTestModel[models.Trader](t, state, "TRADER-tt1")
+	require.Equal(t, "Org1MSP", trader.OwnerMSP)
+	require.Empty(t, trader.Endorsers)
+	require.Equal(t, []string{"Org1MSP"}, endorsingOrgs(t, policies, "TRADER-tt1"))
+	require.Equal(t, []string{"Org1MSP"}, endorsingOrgs(t, policies, "PRODUCT-m1"))
+
+	require.ErrorContains(t, sc.TransferTraderOwnership(ctx, "tt1", "Org2MSP"), "not an admin")
+
+	setCaller(ctx, "Org1MSP", adminOU)
+	require.NoError(t, sc.TransferTraderOwnership(ctx, "tt1", "Org2MSP"))
+	require.Equal(t, []string{"Org2MSP"}, endorsingOrgs(t, policies, "TRADER-tt1"))
+	require.Equal(t, []string{"Org2MSP"}, endorsingOrgs(t, policies, "PRODUCT-m1"))
+
+	require.NoError(t, sc.SetTraderEndorsers(ctx, "tt1", []string{"Org3MSP", "Org2MSP"}))
+	trader = getTestModel[models.Trader](t, state, "TRADER-tt1")
+	require.Equal(t, []string{"Org3MSP"}, trader.Endorsers)
+	require.Equal(t, []string{"Org2MSP", "Org3MSP"}, endorsingOrgs(t, policies, "PRODUCT-m1"))
+
+	trader.OwnerMSP = "Org1MSP"
+	trader.Endorsers = nil
+	require.NoError(t, sc.UpdateTrader(ctx, "tt1", &trader))
+	trader = getTestModel[models.Trader](t, state, "TRADER-tt1")
+	require.Equal(t, "Org2MSP", trader.OwnerMSP)
+	require.Equal(t, []string{"Org3MSP"}, trader.Endorsers)
+
+	require.NoError(t, sc.CreateTrader(ctx, models.Trader{ID: "tt2", PIB: "100000016", Currency: "RSD", OwnerMSP: "Org2MSP"}))
+	require.Equal(t, []string{"Org2MSP"}, endorsingOrgs(t, policies, "TRADER-tt2"))
+}
+
+func TestTraderEndorsementWithoutOwner(t *testing.T) {
+	now := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+	ctx, stub := newStatefulContext(state, &now)
+	sc := SmartContract{}
+
+	putTestModel(t, state, models.Trader{ID: "TRADER-tt1", PIB: "100000008", Currency: "RSD", Products: []string{}, Receipts: []string{}})
+	require.NoError(t, sc.CreateProduct(ctx, models.Product{ID: "m1", Name: "Milk", Price: rsd(3), Quantity: 5, TraderID: "tt1"}))
+	require.Equal(t, 0, stub.SetStateValidationParameterCallCount())
+
+	setCaller(ctx, "Org1MSP", adminOU)
+	require.ErrorContains(t, sc.SetTraderEndorsers(ctx, "tt1", []string{"Org3MSP"}), "has no owner")
+	require.NoError(t, sc.TransferTraderOwnership(ctx, "tt1", "Org1MSP"))
+	require.Equal(t, 2, stub.SetStateValidationParameterCallCount())
+}
@@ -0,0 +1,154 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func (sc *SmartContract) ReadExchangeRate(ctx contractapi.TransactionContextInterface, base string, quote string) (*models.ExchangeRate, error) {
+	return readModel[models.ExchangeRate](ctx, models.ToExchangeRateID(base, quote))
+}
+
+// SetExchangeRate stores the price of one unit of base in quote, given as a
+// decimal such as "117.2". The rate may be used for maxAge seconds.
+func (sc *SmartContract) SetExchangeRate(ctx contractapi.TransactionContextInterface, base string, quote string, rate string, maxAge int64) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	for _, currency := range []string{base, quote} {
+		if err := models.NewMoney(0, currency).Validate(); err != nil {
+			return err
+		}
+	}
+
+	if base == quote {
+		return fmt.Errorf("base and quote currency must differ")
+	}
+
+	if maxAge <= 0 {
+		return fmt.Errorf("max age must be greater than zero")
+	}
+
+	value, err := models.ParseRate(rate)
+	if err != nil {
+		return err
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	exchangeRate := models.ExchangeRate{
+		ID:        models.ToExchangeRateID(base, quote),
+		Base:      base,
+		Quote:     quote,
+		Rate:      value,
+		Version:   1,
+		UpdatedAt: now.Format(time.RFC3339),
+		MaxAge:    maxAge,
+	}
+
+	exists, err := modelExists(ctx, exchangeRate.ID)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return createModel(ctx, exchangeRate)
+	}
+
+	current, err := sc.ReadExchangeRate(ctx, base, quote)
+	if err != nil {
+		return err
+	}
+	exchangeRate.Version = current.Version + 1
+
+	return updateModel(ctx, exchangeRate.ID, &exchangeRate)
+}
+
+// GetExchangeRateHistory returns every version of the rate, oldest first.
+func (sc *SmartContract) GetExchangeRateHistory(ctx contractapi.TransactionContextInterface, base string, quote string) ([]*models.ExchangeRate, error) {
+	id := models.ToExchangeRateID(base, quote)
+	historyIterator, err := ctx.GetStub().GetHistoryForKey(id)
+	if err != nil {
+		return nil, err
+	}
+	defer historyIterator.Close()
+
+	rates := make([]*models.ExchangeRate, 0)
+	for historyIterator.HasNext() {
+		modification, err := historyIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		if modification.IsDelete {
+			continue
+		}
+
+		var rate models.ExchangeRate
+		if err := models.DecodeModel(id, modification.Value, &rate); err != nil {
+			return nil, err
+		}
+		rates = append(rates, &rate)
+	}
+
+	return rates, nil
+}
+
+// applicableRate returns the rate that converts from one currency into the
+// other. The inverse of a stored rate is used when only that one exists.
+// Rates older than their max age are refused.
+func (sc *SmartContract) applicableRate(ctx contractapi.TransactionContextInterface, from string, to string) (*models.AppliedRate, error) {
+	rate, err := sc.ReadExchangeRate(ctx, from, to)
+	if err != nil {
+		inverse, inverseErr := sc.ReadExchangeRate(ctx, to, from)
+		if inverseErr != nil {
+			return nil, fmt.Errorf("no exchange rate between %s and %s", from, to)
+		}
+		rate = inverse
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedAt, err := time.Parse(time.RFC3339, rate.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exchange rate update time: %v", err)
+	}
+
+	if now.Sub(updatedAt) > time.Duration(rate.MaxAge)*time.Second {
+		return nil, fmt.Errorf("the %s/%s exchange rate is stale, it was updated at %s", rate.Base, rate.Quote, rate.UpdatedAt)
+	}
+
+	return &models.AppliedRate{Base: rate.Base, Quote: rate.Quote, Rate: rate.Rate, Version: rate.Version}, nil
+}
+
+// convert returns the amount in the currency together with the rate that was
+// applied, which is nil when no conversion was needed.
+func (sc *SmartContract) convert(ctx contractapi.TransactionContextInterface, amount models.Money, currency string) (models.Money, *models.AppliedRate, error) {
+	if amount.Currency == currency {
+		return amount, nil, nil
+	}
+
+	rate, err := sc.applicableRate(ctx, amount.Currency, currency)
+	if err != nil {
+		return models.Money{}, nil, err
+	}
+
+	var converted models.Money
+	if rate.Base == amount.Currency {
+		converted, err = rate.Convert(amount)
+	} else {
+		converted, err = rate.ConvertBack(amount)
+	}
+
+	return converted, rate, err
+}
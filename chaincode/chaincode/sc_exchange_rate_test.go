@@ -0,0 +1,81 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func eur(amount int64) models.Money {
+	return models.FromMajorUnits(amount, "EUR")
+}
+
+func TestSetExchangeRate(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+	ctx, _ := newStatefulContext(state, &now)
+
+	setCaller(ctx, "Org1MSP", "client")
+	require.Error(t, sc.SetExchangeRate(ctx, "EUR", "RSD", "117.2", 3600), "set by a non admin")
+
+	setCaller(ctx, "Org1MSP", "admin")
+	require.Error(t, sc.SetExchangeRate(ctx, "EUR", "EUR", "1", 3600), "same currencies")
+	require.Error(t, sc.SetExchangeRate(ctx, "EUR", "RSD", "0", 3600), "zero rate")
+	require.Error(t, sc.SetExchangeRate(ctx, "EUR", "RSD", "117.2", 0), "no max age")
+
+	require.NoError(t, sc.SetExchangeRate(ctx, "EUR", "RSD", "117.2", 3600))
+	require.NoError(t, sc.SetExchangeRate(ctx, "EUR", "RSD", "117.25", 3600))
+
+	rate, err := sc.ReadExchangeRate(ctx, "EUR", "RSD")
+	require.NoError(t, err)
+	require.Equal(t, int64(117_250_000), rate.Rate)
+	require.Equal(t, uint64(2), rate.Version)
+	require.Equal(t, now.Format(time.RFC3339), rate.UpdatedAt)
+}
+
+func TestBuyProductWithCurrency(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	putTestModel(t, state, models.User{ID: "USER-u1", Name: "Ana", LastName: "Jovanović", Email: "USER-u1@example.com", Balances: models.Balances{"RSD": rsd(1000), "EUR": eur(1)}, ReceiptsID: []string{}})
+	putTestModel(t, state, models.Trader{ID: "TRADER-tt2", PIB: "100000008", VerificationStatus: models.VerificationVerified, Currency: "EUR", Products: []string{"sw1"}, Receipts: []string{}})
+	putTestModel(t, state, models.Product{ID: "PRODUCT-sw1", Name: "Steering Wheel", Price: eur(5), Quantity: 10, TraderID: "tt2"})
+
+	ctx, _ := newStatefulContext(state, &now)
+	setCaller(ctx, "Org1MSP", "admin")
+
+	require.Error(t, sc.BuyProduct(ctx, "sw1", "u1"), "not enough euros")
+	require.Error(t, sc.BuyProductWithCurrency(ctx, "sw1", "u1", "RSD"), "no exchange rate")
+
+	require.NoError(t, sc.SetExchangeRate(ctx, "EUR", "RSD", "117.2", 3600))
+	require.NoError(t, sc.BuyProductWithCurrency(ctx, "sw1", "u1", "RSD"))
+
+	user := getTestModel[models.User](t, state, "USER-u1")
+	require.Equal(t, rsd(414), user.Balances.Get("RSD"))
+	require.Equal(t, eur(1), user.Balances.Get("EUR"))
+	require.Equal(t, eur(5), getTestTrader(t, ctx, state, "TRADER-tt2").AccountBalance)
+
+	receipt := getTestModel[models.Receipt](t, state, models.ToReceiptID(user.ReceiptsID[0]))
+	require.Equal(t, eur(5), receipt.Price)
+	require.Equal(t, rsd(586), receipt.Paid)
+	require.Equal(t, &models.AppliedRate{Base: "EUR", Quote: "RSD", Rate: 117_200_000, Version: 1}, receipt.ExchangeRate)
+
+	now = now.Add(2 * time.Hour)
+	require.Error(t, sc.BuyProductWithCurrency(ctx, "sw1", "u1", "RSD"), "stale exchange rate")
+}
+
+func TestCreateProductInTraderCurrency(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Now()
+	state := map[string][]byte{}
+	putTestModel(t, state, models.Trader{ID: "TRADER-tt2", PIB: "100000008", VerificationStatus: models.VerificationVerified, Currency: "EUR", Products: []string{}, Receipts: []string{}})
+
+	ctx, _ := newStatefulContext(state, &now)
+
+	require.Error(t, sc.CreateProduct(ctx, models.Product{ID: "ti1", Name: "Product ti1", Price: rsd(8), Quantity: 1, TraderID: "tt2"}))
+	require.NoError(t, sc.CreateProduct(ctx, models.Product{ID: "ti1", Name: "Product ti1", Price: eur(8), Quantity: 1, TraderID: "tt2"}))
+}
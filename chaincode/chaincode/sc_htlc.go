@@ -0,0 +1,297 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// claimSafetyMargin is how much earlier a claim expires than its source lock.
+// It leaves the coordinator time to complete the lock with the revealed
+// preimage before the sender becomes able to refund it.
+const claimSafetyMargin = 10 * time.Minute
+
+var hashLockPattern = regexp.MustCompile("^[0-9a-f]{64}$")
+
+func (sc *SmartContract) ReadHTLC(ctx contractapi.TransactionContextInterface, id string) (*models.HTLC, error) {
+	return readModel[models.HTLC](ctx, models.ToHTLCID(id))
+}
+
+func (sc *SmartContract) ReadClaim(ctx contractapi.TransactionContextInterface, id string) (*models.Claim, error) {
+	return readModel[models.Claim](ctx, models.ToClaimID(id))
+}
+
+// LockFunds is the first phase of a cross-channel transfer. The amount is
+// taken from the user on this channel and held until the lock is completed
+// with the preimage of hashLock or refunded after the timeout.
+func (sc *SmartContract) LockFunds(ctx contractapi.TransactionContextInterface, lockId string, userId string, recipientId string, amount models.Money, hashLock string, timeout string, targetChannel string, targetChaincode string) error {
+	if err := amount.Validate(); err != nil {
+		return err
+	}
+
+	if amount.IsZero() {
+		return fmt.Errorf("amount must be greater than zero")
+	}
+
+	if !hashLockPattern.MatchString(hashLock) {
+		return fmt.Errorf("hash lock must be a hex encoded sha256 hash")
+	}
+
+	if targetChannel == ctx.GetStub().GetChannelID() {
+		return fmt.Errorf("target channel must differ from the source channel")
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	timeoutTime, err := time.Parse(time.RFC3339, timeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout: %v", err)
+	}
+
+	if !timeoutTime.After(now.Add(claimSafetyMargin)) {
+		return fmt.Errorf("timeout must be more than %v in the future", claimSafetyMargin)
+	}
+
+	user, err := sc.ReadUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if err := debitUser(ctx, user, amount, models.MovementTransferOut, lockId, transfersLine(models.Credit, amount)); err != nil {
+		return fmt.Errorf("failed to lock the funds: %v", err)
+	}
+
+	lock := models.HTLC{
+		ID:              models.ToHTLCID(lockId),
+		UserID:          userId,
+		RecipientID:     recipientId,
+		Amount:          amount,
+		HashLock:        hashLock,
+		Timeout:         timeout,
+		TargetChannel:   targetChannel,
+		TargetChaincode: targetChaincode,
+		Status:          models.HTLCLocked,
+	}
+
+	if err := createModel(ctx, lock); err != nil {
+		return err
+	}
+
+	return sc.UpdateUser(ctx, userId, user)
+}
+
+// CompleteLock finishes the transfer on the source channel. The locked funds
+// stay out of circulation here because they were minted on the target channel.
+func (sc *SmartContract) CompleteLock(ctx contractapi.TransactionContextInterface, lockId string, preimage string) error {
+	lock, err := sc.ReadHTLC(ctx, lockId)
+	if err != nil {
+		return err
+	}
+
+	if lock.Status != models.HTLCLocked {
+		return fmt.Errorf("lock %s is %s", lockId, lock.Status)
+	}
+
+	if models.HashPreimage(preimage) != lock.HashLock {
+		return fmt.Errorf("preimage doesn't match the hash lock")
+	}
+
+	lock.Status = models.HTLCClaimed
+	lock.Preimage = preimage
+
+	return updateModel(ctx, lock.ID, lock)
+}
+
+// RefundLock returns the locked funds to the sender once the lock timed out.
+// The claim on the target channel is checked first; if it was redeemed the
+// lock is completed with the revealed preimage instead of being refunded.
+func (sc *SmartContract) RefundLock(ctx contractapi.TransactionContextInterface, lockId string) error {
+	lock, err := sc.ReadHTLC(ctx, lockId)
+	if err != nil {
+		return err
+	}
+
+	if lock.Status != models.HTLCLocked {
+		return fmt.Errorf("lock %s is %s", lockId, lock.Status)
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	timeout, err := time.Parse(time.RFC3339, lock.Timeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout: %v", err)
+	}
+
+	if now.Before(timeout) {
+		return fmt.Errorf("lock %s can't be refunded before %s", lockId, lock.Timeout)
+	}
+
+	claim, err := sc.readRemoteClaim(ctx, lock)
+	if err != nil {
+		return err
+	}
+
+	if claim != nil && claim.Status == models.ClaimRedeemed {
+		return sc.CompleteLock(ctx, lockId, claim.Preimage)
+	}
+
+	user, err := sc.ReadUser(ctx, lock.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err := creditUser(ctx, user, lock.Amount, models.MovementTransferReturn, lockId, transfersLine(models.Debit, lock.Amount)); err != nil {
+		return err
+	}
+	lock.Status = models.HTLCRefunded
+
+	if err := updateModel(ctx, lock.ID, lock); err != nil {
+		return err
+	}
+
+	return sc.UpdateUser(ctx, lock.UserID, user)
+}
+
+// readRemoteClaim reads the claim minted for the lock on the target channel.
+// It returns nil when no claim was minted.
+func (sc *SmartContract) readRemoteClaim(ctx contractapi.TransactionContextInterface, lock *models.HTLC) (*models.Claim, error) {
+	lockId := models.TrimKeyPrefix(models.HTLC_TYPE, lock.ID)
+
+	payload, err := invokeRemote(ctx, lock.TargetChaincode, lock.TargetChannel, "ClaimExists", lockId)
+	if err != nil {
+		return nil, err
+	}
+
+	if string(payload) != "true" {
+		return nil, nil
+	}
+
+	payload, err = invokeRemote(ctx, lock.TargetChaincode, lock.TargetChannel, "ReadClaim", lockId)
+	if err != nil {
+		return nil, err
+	}
+
+	var claim models.Claim
+	if err := json.Unmarshal(payload, &claim); err != nil {
+		return nil, fmt.Errorf("failed to deserialize the remote claim: %v", err)
+	}
+
+	return &claim, nil
+}
+
+func (sc *SmartContract) ClaimExists(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
+	return modelExists(ctx, models.ToClaimID(id))
+}
+
+// MintClaim is the second phase of a cross-channel transfer. It reads the
+// lock from the source channel and creates a matching claim on this one.
+func (sc *SmartContract) MintClaim(ctx contractapi.TransactionContextInterface, lockId string, sourceChannel string, sourceChaincode string) error {
+	payload, err := invokeRemote(ctx, sourceChaincode, sourceChannel, "ReadHTLC", lockId)
+	if err != nil {
+		return err
+	}
+
+	var lock models.HTLC
+	if err := json.Unmarshal(payload, &lock); err != nil {
+		return fmt.Errorf("failed to deserialize the remote lock: %v", err)
+	}
+
+	if lock.Status != models.HTLCLocked {
+		return fmt.Errorf("lock %s is %s", lockId, lock.Status)
+	}
+
+	if lock.TargetChannel != ctx.GetStub().GetChannelID() {
+		return fmt.Errorf("lock %s targets the channel %s", lockId, lock.TargetChannel)
+	}
+
+	if _, err := sc.ReadUser(ctx, lock.RecipientID); err != nil {
+		return err
+	}
+
+	lockTimeout, err := time.Parse(time.RFC3339, lock.Timeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout: %v", err)
+	}
+
+	claimTimeout := lockTimeout.Add(-claimSafetyMargin)
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !now.Before(claimTimeout) {
+		return fmt.Errorf("lock %s expires too soon to be claimed", lockId)
+	}
+
+	claim := models.Claim{
+		ID:              models.ToClaimID(lockId),
+		SourceChannel:   sourceChannel,
+		SourceChaincode: sourceChaincode,
+		RecipientID:     lock.RecipientID,
+		Amount:          lock.Amount,
+		HashLock:        lock.HashLock,
+		Timeout:         claimTimeout.Format(time.RFC3339),
+		Status:          models.ClaimPending,
+	}
+
+	return createModel(ctx, claim)
+}
+
+// RedeemClaim credits the recipient when the preimage of the hash lock is
+// revealed before the claim expires.
+func (sc *SmartContract) RedeemClaim(ctx contractapi.TransactionContextInterface, lockId string, preimage string) error {
+	claim, err := sc.ReadClaim(ctx, lockId)
+	if err != nil {
+		return err
+	}
+
+	if claim.Status != models.ClaimPending {
+		return fmt.Errorf("claim %s is %s", lockId, claim.Status)
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	timeout, err := time.Parse(time.RFC3339, claim.Timeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout: %v", err)
+	}
+
+	if !now.Before(timeout) {
+		return fmt.Errorf("claim %s expired", lockId)
+	}
+
+	if models.HashPreimage(preimage) != claim.HashLock {
+		return fmt.Errorf("preimage doesn't match the hash lock")
+	}
+
+	user, err := sc.ReadUser(ctx, claim.RecipientID)
+	if err != nil {
+		return err
+	}
+
+	if err := creditUser(ctx, user, claim.Amount, models.MovementTransferIn, lockId, transfersLine(models.Debit, claim.Amount)); err != nil {
+		return err
+	}
+	claim.Status = models.ClaimRedeemed
+	claim.Preimage = preimage
+
+	if err := updateModel(ctx, claim.ID, claim); err != nil {
+		return err
+	}
+
+	return sc.UpdateUser(ctx, claim.RecipientID, user)
+}
@@ -0,0 +1,127 @@
+package chaincode
+
+import (
+	"chaincode/chaincode/mocks"
+	"chaincode/models"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/require"
+)
+
+// linkChannels routes the cross-channel reads of one context to the contract
+// running against the other context.
+func linkChannels(t *testing.T, from *mocks.ChaincodeStub, to *mocks.TransactionContext) {
+	sc := SmartContract{}
+
+	from.InvokeChaincodeStub = func(name string, args [][]byte, channel string) peer.Response {
+		var result interface{}
+		var err error
+
+		switch string(args[0]) {
+		case "ReadHTLC":
+			result, err = sc.ReadHTLC(to, string(args[1]))
+		case "ReadClaim":
+			result, err = sc.ReadClaim(to, string(args[1]))
+		case "ClaimExists":
+			result, err = sc.ClaimExists(to, string(args[1]))
+		default:
+			err = fmt.Errorf("unexpected function %s", args[0])
+		}
+
+		if err != nil {
+			return peer.Response{Status: 500, Message: err.Error()}
+		}
+
+		payload, err := json.Marshal(result)
+		require.NoError(t, err)
+		return peer.Response{Status: 200, Payload: payload}
+	}
+}
+
+func setupHTLCChannels(t *testing.T, now *time.Time) (source map[string][]byte, target map[string][]byte, sourceCtx *mocks.TransactionContext, targetCtx *mocks.TransactionContext) {
+	source = map[string][]byte{}
+	target = map[string][]byte{}
+	putTestModel(t, source, models.User{ID: "USER-u1", Name: "Ana", LastName: "Jovanović", Email: "USER-u1@example.com", Balances: rsdBalances(100), ReceiptsID: []string{}})
+	putTestModel(t, target, models.User{ID: "USER-u1", Name: "Ana", LastName: "Jovanović", Email: "USER-u1@example.com", Balances: rsdBalances(10), ReceiptsID: []string{}})
+
+	sourceCtx, sourceStub := newStatefulContext(source, now)
+	targetCtx, targetStub := newStatefulContext(target, now)
+	sourceStub.GetChannelIDReturns("tradechannel1")
+	targetStub.GetChannelIDReturns("tradechannel2")
+
+	linkChannels(t, sourceStub, targetCtx)
+	linkChannels(t, targetStub, sourceCtx)
+
+	return source, target, sourceCtx, targetCtx
+}
+
+func TestHTLCTransfer(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	source, target, sourceCtx, targetCtx := setupHTLCChannels(t, &now)
+
+	preimage := "secret"
+	timeout := now.Add(time.Hour).Format(time.RFC3339)
+
+	require.Error(t, sc.LockFunds(sourceCtx, "l1", "u1", "u1", rsd(500), models.HashPreimage(preimage), timeout, "tradechannel2", "traderchaincode2"), "lock above the balance")
+	require.Error(t, sc.LockFunds(sourceCtx, "l1", "u1", "u1", rsd(40), "nothex", timeout, "tradechannel2", "traderchaincode2"), "malformed hash lock")
+	require.NoError(t, sc.LockFunds(sourceCtx, "l1", "u1", "u1", rsd(40), models.HashPreimage(preimage), timeout, "tradechannel2", "traderchaincode2"))
+	require.Equal(t, rsd(60), getTestModel[models.User](t, source, "USER-u1").Balances.Get(models.DefaultCurrency))
+
+	require.NoError(t, sc.MintClaim(targetCtx, "l1", "tradechannel1", "traderchaincode1"))
+	require.Error(t, sc.MintClaim(targetCtx, "l1", "tradechannel1", "traderchaincode1"), "claim minted twice")
+
+	require.Error(t, sc.RedeemClaim(targetCtx, "l1", "wrong"), "wrong preimage")
+	require.NoError(t, sc.RedeemClaim(targetCtx, "l1", preimage))
+	require.Equal(t, rsd(50), getTestModel[models.User](t, target, "USER-u1").Balances.Get(models.DefaultCurrency))
+
+	require.Error(t, sc.RefundLock(sourceCtx, "l1"), "refund before the timeout")
+	require.NoError(t, sc.CompleteLock(sourceCtx, "l1", preimage))
+	require.Equal(t, models.HTLCClaimed, getTestModel[models.HTLC](t, source, "HTLC-l1").Status)
+	require.Equal(t, rsd(60), getTestModel[models.User](t, source, "USER-u1").Balances.Get(models.DefaultCurrency))
+}
+
+func TestHTLCRefund(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	source, target, sourceCtx, targetCtx := setupHTLCChannels(t, &now)
+
+	hashLock := models.HashPreimage("secret")
+	timeout := now.Add(time.Hour).Format(time.RFC3339)
+
+	require.NoError(t, sc.LockFunds(sourceCtx, "l1", "u1", "u1", rsd(40), hashLock, timeout, "tradechannel2", "traderchaincode2"))
+	require.NoError(t, sc.MintClaim(targetCtx, "l1", "tradechannel1", "traderchaincode1"))
+
+	now = now.Add(55 * time.Minute)
+	require.Error(t, sc.RedeemClaim(targetCtx, "l1", "secret"), "claim redeemed after it expired")
+
+	now = now.Add(5 * time.Minute)
+	require.NoError(t, sc.RefundLock(sourceCtx, "l1"))
+	require.Equal(t, models.HTLCRefunded, getTestModel[models.HTLC](t, source, "HTLC-l1").Status)
+	require.Equal(t, rsd(100), getTestModel[models.User](t, source, "USER-u1").Balances.Get(models.DefaultCurrency))
+	require.Equal(t, rsd(10), getTestModel[models.User](t, target, "USER-u1").Balances.Get(models.DefaultCurrency))
+}
+
+func TestHTLCRefundCompletesRedeemedClaim(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	source, _, sourceCtx, targetCtx := setupHTLCChannels(t, &now)
+
+	timeout := now.Add(time.Hour).Format(time.RFC3339)
+	require.NoError(t, sc.LockFunds(sourceCtx, "l1", "u1", "u1", rsd(40), models.HashPreimage("secret"), timeout, "tradechannel2", "traderchaincode2"))
+	require.NoError(t, sc.MintClaim(targetCtx, "l1", "tradechannel1", "traderchaincode1"))
+	require.NoError(t, sc.RedeemClaim(targetCtx, "l1", "secret"))
+
+	// the coordinator never completed the lock, so the refund finds the claim
+	now = now.Add(2 * time.Hour)
+	require.NoError(t, sc.RefundLock(sourceCtx, "l1"))
+
+	lock := getTestModel[models.HTLC](t, source, "HTLC-l1")
+	require.Equal(t, models.HTLCClaimed, lock.Status)
+	require.Equal(t, "secret", lock.Preimage)
+	require.Equal(t, rsd(60), getTestModel[models.User](t, source, "USER-u1").Balances.Get(models.DefaultCurrency))
+}
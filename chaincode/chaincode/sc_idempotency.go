@@ -0,0 +1,49 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// PurgeIdempotencyRecords deletes the recorded responses of the transactions
+// submitted with an idempotency key before the given time. Retrying those
+// transactions with their keys runs them again, so only records older than
+// any retry clients may still send should be purged. It returns the number
+// of deleted records.
+func (sc *SmartContract) PurgeIdempotencyRecords(ctx contractapi.TransactionContextInterface, before string) (int, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return 0, err
+	}
+
+	cutoff, err := time.Parse(time.RFC3339, before)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: %v", before, err)
+	}
+
+	records, err := getAllOfType[models.IdempotencyRecord](ctx, models.IDEMPOTENCY_TYPE)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, record := range records {
+		recordedAt, err := time.Parse(time.RFC3339, record.RecordedAt)
+		if err != nil {
+			return purged, fmt.Errorf("idempotency record %s: %v", record.ID, err)
+		}
+
+		if !recordedAt.Before(cutoff) {
+			continue
+		}
+
+		if err := ctx.GetStub().DelState(record.ID); err != nil {
+			return purged, fmt.Errorf("failed to delete %s: %v", record.ID, err)
+		}
+		purged++
+	}
+
+	return purged, nil
+}
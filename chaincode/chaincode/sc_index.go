@@ -0,0 +1,134 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// indexEntries returns the index keys of the stored document and their
+// values: one per relation the record refers through, and the PIB of a
+// trader. A nil document has none.
+func indexEntries(ctx contractapi.TransactionContextInterface, key string, document []byte) (map[string]string, error) {
+	entries := make(map[string]string)
+	if document == nil {
+		return entries, nil
+	}
+
+	entityType := models.EntityType(key)
+	for _, r := range relations {
+		if r.from != entityType {
+			continue
+		}
+
+		id, inUse, err := r.reference(key, document)
+		if err != nil {
+			return nil, err
+		}
+
+		if id == "" {
+			continue
+		}
+
+		indexKey, err := ctx.GetStub().CreateCompositeKey(models.REFERENCE_INDEX, []string{r.name(), id, key})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the index key of %s: %v", key, err)
+		}
+
+		entries[indexKey] = models.REFERENCE_UNUSED
+		if inUse {
+			entries[indexKey] = models.REFERENCE_IN_USE
+		}
+	}
+
+	if entityType == models.TRADER_TYPE {
+		var trader models.Trader
+		if err := models.DecodeModel(key, document, &trader); err != nil {
+			return nil, fmt.Errorf("failed to deserialize %s: %v", key, err)
+		}
+
+		if trader.PIB != "" {
+			indexKey, err := ctx.GetStub().CreateCompositeKey(models.PIB_INDEX, []string{trader.PIB})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create the index key of %s: %v", key, err)
+			}
+			entries[indexKey] = key
+		}
+	}
+
+	return entries, nil
+}
+
+// reindex updates the index entries of the record whose document changes
+// from previous to document, either of which is nil when the record is
+// created or deleted. Entries are compared with the stored ones, so entries
+// missing from the world state are written even when the document doesn't
+// change. It returns the number of written entries.
+func reindex(ctx contractapi.TransactionContextInterface, key string, previous []byte, document []byte) (int, error) {
+	stale, err := indexEntries(ctx, key, previous)
+	if err != nil {
+		return 0, err
+	}
+
+	entries, err := indexEntries(ctx, key, document)
+	if err != nil {
+		return 0, err
+	}
+
+	written := 0
+	for indexKey, value := range stale {
+		if _, ok := entries[indexKey]; ok {
+			continue
+		}
+
+		// the PIB entries hold the key of the trader, one that points at
+		// another trader isn't this record's
+		stored, err := ctx.GetStub().GetState(indexKey)
+		if err != nil {
+			return written, fmt.Errorf("failed to read an index entry of %s: %v", key, err)
+		}
+		if stored == nil || (value == key && string(stored) != key) {
+			continue
+		}
+
+		if err := ctx.GetStub().DelState(indexKey); err != nil {
+			return written, fmt.Errorf("failed to remove an index entry of %s: %v", key, err)
+		}
+		written++
+	}
+
+	for indexKey, value := range entries {
+		stored, err := ctx.GetStub().GetState(indexKey)
+		if err != nil {
+			return written, fmt.Errorf("failed to read an index entry of %s: %v", key, err)
+		}
+
+		if string(stored) == value {
+			continue
+		}
+
+		if err := ctx.GetStub().PutState(indexKey, []byte(value)); err != nil {
+			return written, fmt.Errorf("failed to write an index entry of %s: %v", key, err)
+		}
+		written++
+	}
+
+	return written, nil
+}
+
+// traderWithPIB returns the key of the trader registered with the PIB, or an
+// empty string.
+func traderWithPIB(ctx contractapi.TransactionContextInterface, pib string) (string, error) {
+	indexKey, err := ctx.GetStub().CreateCompositeKey(models.PIB_INDEX, []string{pib})
+	if err != nil {
+		return "", err
+	}
+
+	traderKey, err := ctx.GetStub().GetState(indexKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the PIB index: %v", err)
+	}
+
+	return string(traderKey), nil
+}
@@ -0,0 +1,75 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReferenceIndex(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	ctx, _ := newStatefulContext(state, &now)
+	setCaller(ctx, "Org1MSP", "admin")
+
+	require.NoError(t, sc.CreateTrader(ctx, models.Trader{ID: "tt1", PIB: "100000008", Currency: "RSD"}))
+	require.NoError(t, sc.SetTraderVerification(ctx, "tt1", string(models.VerificationVerified)))
+	require.NoError(t, sc.CreateProduct(ctx, models.Product{ID: "m1", Name: "Product m1", Price: rsd(3), Quantity: 5, TraderID: "tt1"}))
+	require.NoError(t, sc.CreateProduct(ctx, models.Product{ID: "b1", Name: "Product b1", Price: rsd(2), Quantity: 5, TraderID: "tt1"}))
+	require.NoError(t, sc.CreateUser(ctx, models.User{ID: "u1", Name: "Ana", LastName: "Jovanović", Email: "u1@example.com", Balances: rsdBalances(20)}))
+	require.NoError(t, sc.ReserveProduct(ctx, "r1", "m1", "u1", 2))
+
+	indexKey, err := shim.CreateCompositeKey(models.REFERENCE_INDEX, []string{"RESERVATION.product_id", "m1", "RESERVATION-r1"})
+	require.NoError(t, err)
+	require.Equal(t, models.REFERENCE_IN_USE, string(state[indexKey]))
+
+	// releasing the reservation updates its entry, so the product can go
+	require.NoError(t, sc.ReleaseReservation(ctx, "r1"))
+	require.Equal(t, models.REFERENCE_UNUSED, string(state[indexKey]))
+	require.NoError(t, sc.DeleteProduct(ctx, "m1"))
+
+	// a reservation stored before the index is found once MigrateState
+	// indexed it
+	legacy, err := models.EncodeModel("RESERVATION-r2", models.Reservation{ID: "RESERVATION-r2", ProductID: "b1", UserID: "u1", Quantity: 1, Status: models.ReservationActive})
+	require.NoError(t, err)
+	state["RESERVATION-r2"] = legacy
+
+	page, err := sc.MigrateState(ctx, models.RESERVATION_TYPE, 10, "")
+	require.NoError(t, err)
+	require.Equal(t, 2, page.Indexed)
+
+	var restricted *models.RestrictedDeleteError
+	require.ErrorAs(t, sc.DeleteProduct(ctx, "b1"), &restricted)
+	require.Equal(t, []string{"RESERVATION-r2"}, restricted.Referrers)
+
+	page, err = sc.MigrateState(ctx, models.RESERVATION_TYPE, 10, "")
+	require.NoError(t, err)
+	require.Equal(t, 0, page.Indexed)
+}
+
+func TestPIBIndex(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	ctx, _ := newStatefulContext(state, &now)
+	setCaller(ctx, "Org1MSP", "admin")
+
+	require.NoError(t, sc.CreateTrader(ctx, models.Trader{ID: "tt1", PIB: "100000008", Currency: "RSD"}))
+	require.ErrorContains(t, sc.CreateTrader(ctx, models.Trader{ID: "tt2", PIB: "100000008", Currency: "RSD"}), "already registered to TRADER-tt1")
+
+	// a changed PIB frees the old one
+	trader := getTestModel[models.Trader](t, state, "TRADER-tt1")
+	trader.PIB = "100000016"
+	require.NoError(t, sc.UpdateTrader(ctx, "tt1", &trader))
+	require.NoError(t, sc.CreateTrader(ctx, models.Trader{ID: "tt2", PIB: "100000008", Currency: "RSD"}))
+
+	registered, err := traderWithPIB(ctx, "100000016")
+	require.NoError(t, err)
+	require.Equal(t, "TRADER-tt1", registered)
+}
@@ -0,0 +1,357 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"fmt"
+	"slices"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// relation is a foreign key from the records of type from to the records of
+// type to, stored in the field of the referring records. The referring
+// records are found through the reference index, which the writes of the
+// records keep up to date.
+type relation struct {
+	from          string
+	field         string
+	to            string
+	defaultAction models.DeleteAction
+	// reference returns the id the stored document refers to and whether
+	// the record still uses it, such as an active reservation or an open
+	// auction.
+	reference func(key string, document []byte) (string, bool, error)
+}
+
+func (r relation) name() string {
+	return r.from + "." + r.field
+}
+
+// referrers returns the keys of the records that refer to the record with
+// the given id. Unless all is set, only the records that still use it are
+// returned.
+func (r relation) referrers(ctx contractapi.TransactionContextInterface, id string, all bool) ([]string, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(models.REFERENCE_INDEX, []string{r.name(), id})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	keys := make([]string, 0)
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		if !all && string(queryResponse.Value) != models.REFERENCE_IN_USE {
+			continue
+		}
+
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, attributes[2])
+	}
+
+	return keys, nil
+}
+
+func newRelation[T models.Model](from string, field string, to string, defaultAction models.DeleteAction, foreignKey func(*T) string, inUse func(*T) bool) relation {
+	return relation{
+		from:          from,
+		field:         field,
+		to:            to,
+		defaultAction: defaultAction,
+		reference: func(key string, document []byte) (string, bool, error) {
+			var record T
+			if err := models.DecodeModel(key, document, &record); err != nil {
+				return "", false, fmt.Errorf("failed to deserialize %s: %v", key, err)
+			}
+
+			return foreignKey(&record), inUse(&record), nil
+		},
+	}
+}
+
+// relations are the foreign keys between the stored records. Foreign keys
+// hold the id of the referenced record without its type prefix.
+var relations = []relation{
+	newRelation(models.PRODUCT_TYPE, "trader_id", models.TRADER_TYPE, models.DeleteCascade,
+		func(p *models.Product) string { return p.TraderID },
+		func(p *models.Product) bool { return !p.IsArchived() }),
+	newRelation(models.RECEIPT_TYPE, "user_id", models.USER_TYPE, models.DeleteKeep,
+		func(r *models.Receipt) string { return r.UserID },
+		func(r *models.Receipt) bool { return !r.IsArchived() }),
+	newRelation(models.RECEIPT_TYPE, "trader", models.TRADER_TYPE, models.DeleteKeep,
+		func(r *models.Receipt) string { return r.TraderID },
+		func(r *models.Receipt) bool { return !r.IsArchived() }),
+	newRelation(models.RECEIPT_TYPE, "product_id", models.PRODUCT_TYPE, models.DeleteKeep,
+		func(r *models.Receipt) string { return r.ProductID },
+		func(r *models.Receipt) bool { return !r.IsArchived() }),
+	newRelation(models.RESERVATION_TYPE, "product_id", models.PRODUCT_TYPE, models.DeleteRestrict,
+		func(r *models.Reservation) string { return r.ProductID },
+		func(r *models.Reservation) bool { return r.Status == models.ReservationActive }),
+	newRelation(models.RESERVATION_TYPE, "user_id", models.USER_TYPE, models.DeleteRestrict,
+		func(r *models.Reservation) string { return r.UserID },
+		func(r *models.Reservation) bool { return r.Status == models.ReservationActive }),
+	newRelation(models.AUCTION_TYPE, "product_id", models.PRODUCT_TYPE, models.DeleteRestrict,
+		func(a *models.Auction) string { return a.ProductID },
+		func(a *models.Auction) bool { return a.Status == models.AuctionOpen }),
+	newRelation(models.AUCTION_TYPE, "trader_id", models.TRADER_TYPE, models.DeleteRestrict,
+		func(a *models.Auction) string { return a.TraderID },
+		func(a *models.Auction) bool { return a.Status == models.AuctionOpen }),
+	newRelation(models.BID_TYPE, "user_id", models.USER_TYPE, models.DeleteRestrict,
+		func(b *models.Bid) string { return b.UserID },
+		func(b *models.Bid) bool { return b.Status == models.BidLocked }),
+	newRelation(models.HTLC_TYPE, "user_id", models.USER_TYPE, models.DeleteRestrict,
+		func(h *models.HTLC) string { return h.UserID },
+		func(h *models.HTLC) bool { return h.Status == models.HTLCLocked }),
+}
+
+func findRelation(name string) (relation, bool) {
+	index := slices.IndexFunc(relations, func(r relation) bool { return r.name() == name })
+	if index < 0 {
+		return relation{}, false
+	}
+
+	return relations[index], true
+}
+
+// archivableTypes are the types whose records are archived on delete, so
+// only relations from them can cascade.
+var archivableTypes = []string{models.PRODUCT_TYPE, models.USER_TYPE, models.TRADER_TYPE, models.RECEIPT_TYPE}
+
+// checkReference fails with a MissingReferenceError unless the record with
+// the key exists and, when active is set, isn't archived.
+func checkReference(ctx contractapi.TransactionContextInterface, relation string, key string, active bool) error {
+	data, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", key, err)
+	}
+
+	if data == nil {
+		return &models.MissingReferenceError{Relation: relation, Key: key}
+	}
+
+	if !active {
+		return nil
+	}
+
+	var lifecycle models.Lifecycle
+	if err := models.DecodeModel(key, data, &lifecycle); err != nil {
+		return err
+	}
+
+	if lifecycle.IsArchived() {
+		return &models.MissingReferenceError{Relation: relation, Key: key, Archived: true}
+	}
+
+	return nil
+}
+
+func checkProductReferences(ctx contractapi.TransactionContextInterface, product *models.Product, active bool) error {
+	return checkReference(ctx, "PRODUCT.trader_id", models.ToTraderID(product.TraderID), active)
+}
+
+func checkReceiptReferences(ctx contractapi.TransactionContextInterface, receipt *models.Receipt) error {
+	references := map[string]string{
+		"RECEIPT.user_id":    models.ToUserID(receipt.UserID),
+		"RECEIPT.trader":     models.ToTraderID(receipt.TraderID),
+		"RECEIPT.product_id": models.ToProductID(receipt.ProductID),
+	}
+
+	for _, name := range []string{"RECEIPT.user_id", "RECEIPT.trader", "RECEIPT.product_id"} {
+		if err := checkReference(ctx, name, references[name], false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readIntegrityConfig(ctx contractapi.TransactionContextInterface) (*models.IntegrityConfig, error) {
+	exists, err := modelExists(ctx, models.INTEGRITY_CONFIG_ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return &models.IntegrityConfig{ID: models.INTEGRITY_CONFIG_ID, Actions: map[string]models.DeleteAction{}}, nil
+	}
+
+	return readModel[models.IntegrityConfig](ctx, models.INTEGRITY_CONFIG_ID)
+}
+
+func deleteAction(config *models.IntegrityConfig, r relation) models.DeleteAction {
+	if action, ok := config.Actions[r.name()]; ok {
+		return action
+	}
+
+	return r.defaultAction
+}
+
+// deleteRecord archives the record and applies the delete actions of the
+// relations that refer to it. The whole cascade is planned, and every
+// restriction checked, before anything is written.
+func (sc *SmartContract) deleteRecord(ctx contractapi.TransactionContextInterface, entityType string, id string) error {
+	config, err := readIntegrityConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	plan := make([]string, 0)
+	if err := planDelete(ctx, config, models.FormatKey(entityType, id), &plan); err != nil {
+		return err
+	}
+
+	for _, key := range plan {
+		if err := archiveRecord(ctx, models.EntityType(key), key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// planDelete appends the key and the keys of the records its delete
+// cascades to, unless a restricting relation refuses the delete.
+func planDelete(ctx contractapi.TransactionContextInterface, config *models.IntegrityConfig, key string, plan *[]string) error {
+	if slices.Contains(*plan, key) {
+		return nil
+	}
+	*plan = append(*plan, key)
+
+	entityType := models.EntityType(key)
+	id := models.TrimKeyPrefix(entityType, key)
+
+	for _, r := range relations {
+		if r.to != entityType {
+			continue
+		}
+
+		referrers, err := r.referrers(ctx, id, false)
+		if err != nil {
+			return err
+		}
+
+		if len(referrers) == 0 {
+			continue
+		}
+
+		switch deleteAction(config, r) {
+		case models.DeleteRestrict:
+			return &models.RestrictedDeleteError{Key: key, Relation: r.name(), Referrers: referrers}
+		case models.DeleteCascade:
+			for _, referrer := range referrers {
+				if err := planDelete(ctx, config, referrer, plan); err != nil {
+					return fmt.Errorf("failed to cascade the delete of %s: %w", key, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func archiveRecord(ctx contractapi.TransactionContextInterface, entityType string, key string) error {
+	switch entityType {
+	case models.PRODUCT_TYPE:
+		return archiveModel[models.Product](ctx, key)
+	case models.USER_TYPE:
+		return archiveModel[models.User](ctx, key)
+	case models.TRADER_TYPE:
+		return archiveModel[models.Trader](ctx, key)
+	case models.RECEIPT_TYPE:
+		return archiveModel[models.Receipt](ctx, key)
+	default:
+		return fmt.Errorf("%s records can't be archived", entityType)
+	}
+}
+
+// restrictPurge fails while any record, in use or not, refers to the record
+// that is about to be removed from the world state.
+func restrictPurge(ctx contractapi.TransactionContextInterface, entityType string, id string) error {
+	for _, r := range relations {
+		if r.to != entityType {
+			continue
+		}
+
+		referrers, err := r.referrers(ctx, id, true)
+		if err != nil {
+			return err
+		}
+
+		if len(referrers) > 0 {
+			return &models.RestrictedDeleteError{Key: models.FormatKey(entityType, id), Relation: r.name(), Referrers: referrers}
+		}
+	}
+
+	return nil
+}
+
+// GetIntegrityRules lists the relations between the records and what
+// deleting a referenced record does.
+func (sc *SmartContract) GetIntegrityRules(ctx contractapi.TransactionContextInterface) ([]*models.IntegrityRule, error) {
+	config, err := readIntegrityConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]*models.IntegrityRule, 0, len(relations))
+	for _, r := range relations {
+		rules = append(rules, &models.IntegrityRule{
+			Relation:      r.name(),
+			From:          r.from,
+			Field:         r.field,
+			To:            r.to,
+			Action:        deleteAction(config, r),
+			DefaultAction: r.defaultAction,
+		})
+	}
+
+	return rules, nil
+}
+
+// SetDeleteAction chooses what deleting a record does to the records that
+// refer to it through the relation, named like PRODUCT.trader_id.
+func (sc *SmartContract) SetDeleteAction(ctx contractapi.TransactionContextInterface, relationName string, action string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	r, ok := findRelation(relationName)
+	if !ok {
+		return fmt.Errorf("unknown relation %s", relationName)
+	}
+
+	deleteAction, err := models.ParseDeleteAction(action)
+	if err != nil {
+		return err
+	}
+
+	if deleteAction == models.DeleteCascade && !slices.Contains(archivableTypes, r.from) {
+		return fmt.Errorf("%s records can't be deleted, so %s can't cascade", r.from, relationName)
+	}
+
+	config, err := readIntegrityConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	exists := len(config.Actions) > 0
+	if !exists {
+		exists, err = modelExists(ctx, models.INTEGRITY_CONFIG_ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	config.Actions[relationName] = deleteAction
+	if !exists {
+		return createModel(ctx, *config)
+	}
+
+	return updateModel(ctx, config.ID, config)
+}
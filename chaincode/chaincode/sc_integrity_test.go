@@ -0,0 +1,102 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestForeignKeysAreValidated(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	ctx, _ := newStatefulContext(state, &now)
+	setCaller(ctx, "Org1MSP", "admin")
+
+	var missing *models.MissingReferenceError
+	err := sc.CreateProduct(ctx, models.Product{ID: "m1", Name: "Product m1", Price: rsd(3), Quantity: 1, TraderID: "tt1"})
+	require.ErrorAs(t, err, &missing)
+	require.Equal(t, "PRODUCT.trader_id", missing.Relation)
+	require.False(t, missing.Archived)
+	require.NotContains(t, state, "PRODUCT-m1")
+
+	require.NoError(t, sc.CreateTrader(ctx, models.Trader{ID: "tt1", PIB: "100000008", Currency: "RSD"}))
+	require.NoError(t, sc.SetTraderVerification(ctx, "tt1", string(models.VerificationVerified)))
+	require.NoError(t, sc.CreateProduct(ctx, models.Product{ID: "m1", Name: "Product m1", Price: rsd(3), Quantity: 1, TraderID: "tt1"}))
+
+	err = storeReceipt(ctx, models.Receipt{ID: "tt1-2026-000001", UserID: "u9", TraderID: "tt1", ProductID: "m1", Price: rsd(3)})
+	require.ErrorAs(t, err, &missing)
+	require.Equal(t, "USER-u9", missing.Key)
+
+	product := getTestModel[models.Product](t, state, "PRODUCT-m1")
+	product.TraderID = "tt9"
+	require.ErrorAs(t, sc.UpdateProduct(ctx, "m1", &product), &missing)
+	product.TraderID = ""
+	require.ErrorAs(t, sc.UpdateProduct(ctx, "m1", &product), &missing)
+
+	require.NoError(t, sc.DeleteTrader(ctx, "tt1"))
+	err = sc.CreateProduct(ctx, models.Product{ID: "b1", Name: "Product b1", Price: rsd(2), Quantity: 1, TraderID: "tt1"})
+	require.ErrorAs(t, err, &missing)
+	require.True(t, missing.Archived)
+
+	// the product was archived with its trader and can't come back alone
+	require.True(t, getTestModel[models.Product](t, state, "PRODUCT-m1").IsArchived())
+	require.ErrorAs(t, sc.RestoreRecord(ctx, models.PRODUCT_TYPE, "m1"), &missing)
+
+	require.NoError(t, sc.RestoreRecord(ctx, models.TRADER_TYPE, "tt1"))
+	require.NoError(t, sc.RestoreRecord(ctx, models.PRODUCT_TYPE, "m1"))
+}
+
+func TestDeleteActions(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	ctx, _ := newStatefulContext(state, &now)
+	setCaller(ctx, "Org1MSP", "admin")
+
+	require.NoError(t, sc.CreateTrader(ctx, models.Trader{ID: "tt1", PIB: "100000008", Currency: "RSD"}))
+	require.NoError(t, sc.SetTraderVerification(ctx, "tt1", string(models.VerificationVerified)))
+	require.NoError(t, sc.CreateProduct(ctx, models.Product{ID: "m1", Name: "Product m1", Price: rsd(3), Quantity: 5, TraderID: "tt1"}))
+	require.NoError(t, sc.CreateUser(ctx, models.User{ID: "u1", Name: "Ana", LastName: "Jovanović", Email: "u1@example.com", Balances: rsdBalances(20)}))
+	require.NoError(t, sc.ReserveProduct(ctx, "r1", "m1", "u1", 2))
+
+	var restricted *models.RestrictedDeleteError
+	require.ErrorAs(t, sc.DeleteUser(ctx, "u1"), &restricted)
+	require.Equal(t, "RESERVATION.user_id", restricted.Relation)
+	require.Equal(t, []string{"RESERVATION-r1"}, restricted.Referrers)
+
+	// the cascade to the product is refused by the product's reservation,
+	// so the trader isn't archived either
+	require.ErrorAs(t, sc.DeleteTrader(ctx, "tt1"), &restricted)
+	require.Equal(t, "RESERVATION.product_id", restricted.Relation)
+
+	require.NoError(t, sc.ReleaseReservation(ctx, "r1"))
+	require.NoError(t, sc.BuyProduct(ctx, "m1", "u1"))
+
+	// receipts keep pointing at the archived user
+	require.NoError(t, sc.DeleteUser(ctx, "u1"))
+	require.False(t, getTestModel[models.Receipt](t, state, models.ToReceiptID(getTestModel[models.User](t, state, "USER-u1").ReceiptsID[0])).IsArchived())
+
+	require.Error(t, sc.SetDeleteAction(ctx, "RESERVATION.user_id", "CASCADE"), "reservations aren't archived")
+	require.Error(t, sc.SetDeleteAction(ctx, "PRODUCT.owner", "RESTRICT"))
+	require.NoError(t, sc.SetDeleteAction(ctx, "PRODUCT.trader_id", "restrict"))
+
+	rules, err := sc.GetIntegrityRules(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "PRODUCT.trader_id", rules[0].Relation)
+	require.Equal(t, models.DeleteRestrict, rules[0].Action)
+	require.Equal(t, models.DeleteCascade, rules[0].DefaultAction)
+
+	require.ErrorAs(t, sc.DeleteTrader(ctx, "tt1"), &restricted)
+	require.Equal(t, []string{"PRODUCT-m1"}, restricted.Referrers)
+
+	require.NoError(t, sc.DeleteProduct(ctx, "m1"))
+	require.NoError(t, sc.DeleteTrader(ctx, "tt1"))
+
+	setCaller(ctx, "Org1MSP", "client")
+	require.Error(t, sc.SetDeleteAction(ctx, "PRODUCT.trader_id", "CASCADE"))
+}
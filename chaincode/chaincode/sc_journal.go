@@ -0,0 +1,170 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func depositLine(amount models.Money) *models.JournalLine {
+	return &models.JournalLine{Account: models.DepositsAccount, Direction: models.Debit, Amount: amount}
+}
+
+// withdrawalLine balances the money leaving the channel for outside.
+func withdrawalLine(amount models.Money) *models.JournalLine {
+	return &models.JournalLine{Account: models.DepositsAccount, Direction: models.Credit, Amount: amount}
+}
+
+func lockedLine(user *models.User, direction models.MovementDirection, amount models.Money) *models.JournalLine {
+	return &models.JournalLine{Account: models.LockedAccount(models.TrimKeyPrefix(models.USER_TYPE, user.ID)), Direction: direction, Amount: amount}
+}
+
+// traderAccount is the journal account of the trader, whose id may be its
+// key.
+func traderAccount(traderId string) string {
+	return models.TraderAccount(models.TrimKeyPrefix(models.TRADER_TYPE, traderId))
+}
+
+func traderLine(traderId string, direction models.MovementDirection, amount models.Money) *models.JournalLine {
+	return &models.JournalLine{Account: traderAccount(traderId), Direction: direction, Amount: amount}
+}
+
+// transfersLine balances the money leaving for, or coming from, another
+// channel. The transfers account of each channel clears against the others.
+func transfersLine(direction models.MovementDirection, amount models.Money) *models.JournalLine {
+	return &models.JournalLine{Account: models.TransfersAccount, Direction: direction, Amount: amount}
+}
+
+// postJournalEntry stores the entry, which must balance.
+func postJournalEntry(ctx contractapi.TransactionContextInterface, entry models.JournalEntry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	return createModel(ctx, entry)
+}
+
+// journalOpeningBalance journals the balance the trader was created with as a
+// deposit, keyed by the trader.
+func journalOpeningBalance(ctx contractapi.TransactionContextInterface, trader *models.Trader) error {
+	if trader.OpeningBalance.IsZero() {
+		return nil
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	return postJournalEntry(ctx, models.JournalEntry{
+		ID:        models.ToJournalID(trader.ID),
+		Kind:      models.MovementDeposit,
+		Reference: models.TrimKeyPrefix(models.TRADER_TYPE, trader.ID),
+		Lines:     []*models.JournalLine{depositLine(trader.OpeningBalance), traderLine(trader.ID, models.Credit, trader.OpeningBalance)},
+		Timestamp: now.Format(time.RFC3339),
+		TxID:      ctx.GetStub().GetTxID(),
+	})
+}
+
+// accountTotals adds up the lines of the entries per account and currency.
+// The totals are keyed by the account and then the currency.
+func accountTotals(entries []*models.JournalEntry) (map[string]map[string]*models.AccountTotals, error) {
+	totals := make(map[string]map[string]*models.AccountTotals)
+	for _, entry := range entries {
+		for _, line := range entry.Lines {
+			if totals[line.Account] == nil {
+				totals[line.Account] = make(map[string]*models.AccountTotals)
+			}
+
+			currency := line.Amount.Currency
+			account := totals[line.Account][currency]
+			if account == nil {
+				account = &models.AccountTotals{Account: line.Account, Currency: currency, Debits: models.NewMoney(0, currency), Credits: models.NewMoney(0, currency)}
+				totals[line.Account][currency] = account
+			}
+
+			var err error
+			if line.Direction == models.Debit {
+				account.Debits, err = account.Debits.Add(line.Amount)
+			} else {
+				account.Credits, err = account.Credits.Add(line.Amount)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return totals, nil
+}
+
+// GetTrialBalance adds up the journal per account and checks that every
+// entry, and the books as a whole, debit as much as they credit in every
+// currency.
+func (sc *SmartContract) GetTrialBalance(ctx contractapi.TransactionContextInterface) (*models.TrialBalance, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	entries, err := getAllOfType[models.JournalEntry](ctx, models.JOURNAL_TYPE)
+	if err != nil {
+		return nil, err
+	}
+
+	trial := &models.TrialBalance{
+		Entries:    len(entries),
+		Accounts:   make([]*models.AccountTotals, 0),
+		Totals:     make([]*models.CurrencyTotals, 0),
+		Unbalanced: make([]string, 0),
+	}
+
+	for _, entry := range entries {
+		if err := entry.Validate(); err != nil {
+			trial.Unbalanced = append(trial.Unbalanced, entry.ID)
+		}
+	}
+
+	totals, err := accountTotals(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	byCurrency := make(map[string]*models.CurrencyTotals)
+	for _, accounts := range totals {
+		for currency, account := range accounts {
+			trial.Accounts = append(trial.Accounts, account)
+
+			total := byCurrency[currency]
+			if total == nil {
+				total = &models.CurrencyTotals{Currency: currency, Debits: models.NewMoney(0, currency), Credits: models.NewMoney(0, currency)}
+				byCurrency[currency] = total
+				trial.Totals = append(trial.Totals, total)
+			}
+
+			if total.Debits, err = total.Debits.Add(account.Debits); err != nil {
+				return nil, err
+			}
+			if total.Credits, err = total.Credits.Add(account.Credits); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	slices.SortFunc(trial.Accounts, func(a, b *models.AccountTotals) int {
+		return cmp.Or(cmp.Compare(a.Account, b.Account), cmp.Compare(a.Currency, b.Currency))
+	})
+	slices.SortFunc(trial.Totals, func(a, b *models.CurrencyTotals) int {
+		return cmp.Compare(a.Currency, b.Currency)
+	})
+
+	trial.Balanced = len(trial.Unbalanced) == 0
+	for _, total := range trial.Totals {
+		total.Balanced = total.Debits == total.Credits
+		trial.Balanced = trial.Balanced && total.Balanced
+	}
+
+	return trial, nil
+}
@@ -0,0 +1,89 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func accountTotalsOf(trial *models.TrialBalance, account string, currency string) *models.AccountTotals {
+	for _, totals := range trial.Accounts {
+		if totals.Account == account && totals.Currency == currency {
+			return totals
+		}
+	}
+	return nil
+}
+
+func TestJournal(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	ctx, _ := newStatefulContext(state, &now)
+	setCaller(ctx, "Org1MSP", adminOU)
+
+	require.NoError(t, sc.CreateTrader(ctx, models.Trader{ID: "tt2", PIB: "100000008", Currency: "EUR", AccountBalance: eur(2)}))
+	require.NoError(t, sc.SetTraderVerification(ctx, "tt2", string(models.VerificationVerified)))
+	require.NoError(t, sc.CreateProduct(ctx, models.Product{ID: "sw1", Name: "Steering Wheel", Price: eur(5), Quantity: 10, TraderID: "tt2"}))
+	require.NoError(t, sc.CreateUser(ctx, models.User{ID: "u1", Name: "Ana", LastName: "Jovanović", Email: "u1@example.com", Balances: rsdBalances(1000)}))
+	require.NoError(t, sc.DepositFunds(ctx, "u1", eur(10)))
+	require.NoError(t, sc.SetExchangeRate(ctx, "EUR", "RSD", "117.2", 3600))
+
+	require.NoError(t, sc.BuyProduct(ctx, "sw1", "u1"))
+	require.NoError(t, sc.BuyProductWithCurrency(ctx, "sw1", "u1", "RSD"))
+
+	// the payment in dinars is exchanged for the trader's euros
+	entry := getTestModel[models.JournalEntry](t, state, models.ToJournalID(models.ToMovementID("u1", 3)))
+	require.Equal(t, models.MovementPurchase, entry.Kind)
+	require.ElementsMatch(t, []*models.JournalLine{
+		{Account: models.UserAccount("u1"), Direction: models.Debit, Amount: rsd(586)},
+		{Account: models.ExchangeAccount, Direction: models.Credit, Amount: rsd(586)},
+		{Account: models.ExchangeAccount, Direction: models.Debit, Amount: eur(5)},
+		{Account: models.TraderAccount("tt2"), Direction: models.Credit, Amount: eur(5)},
+	}, entry.Lines)
+
+	receipts := getTestModel[models.User](t, state, "USER-u1").ReceiptsID
+	require.NoError(t, sc.RefundReceipt(ctx, receipts[1]))
+
+	// the trader's key and its id name the same account
+	require.Equal(t, models.TraderAccount("tt2"), traderAccount("TRADER-tt2"))
+	require.Equal(t, models.TraderAccount("tt2"), traderAccount("tt2"))
+
+	trial, err := sc.GetTrialBalance(ctx)
+	require.NoError(t, err)
+	require.True(t, trial.Balanced)
+	require.Equal(t, 6, trial.Entries)
+	require.Empty(t, trial.Unbalanced)
+	require.Equal(t, []*models.CurrencyTotals{
+		{Currency: "EUR", Debits: eur(27), Credits: eur(27), Balanced: true},
+		{Currency: "RSD", Debits: rsd(2172), Credits: rsd(2172), Balanced: true},
+	}, trial.Totals)
+
+	require.Equal(t, &models.AccountTotals{Account: models.TraderAccount("tt2"), Currency: "EUR", Debits: eur(5), Credits: eur(12)}, accountTotalsOf(trial, models.TraderAccount("tt2"), "EUR"))
+	require.Equal(t, &models.AccountTotals{Account: models.DepositsAccount, Currency: "RSD", Debits: rsd(1000), Credits: rsd(0)}, accountTotalsOf(trial, models.DepositsAccount, "RSD"))
+	require.Equal(t, eur(7), getTestTrader(t, ctx, state, "TRADER-tt2").AccountBalance)
+
+	report, err := sc.AuditLedger(ctx)
+	require.NoError(t, err)
+	require.Empty(t, report.Violations)
+
+	// a tampered entry breaks the books and no longer explains the balance
+	entry.Lines[0].Amount = rsd(1)
+	putTestModel(t, state, entry)
+
+	trial, err = sc.GetTrialBalance(ctx)
+	require.NoError(t, err)
+	require.False(t, trial.Balanced)
+	require.Equal(t, []string{entry.ID}, trial.Unbalanced)
+
+	report, err = sc.AuditLedger(ctx)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []models.AuditRule{models.RuleJournalUnbalanced, models.RuleAccountUnreconciled}, auditRules(report))
+
+	setCaller(ctx, "Org1MSP", "client")
+	_, err = sc.GetTrialBalance(ctx)
+	require.Error(t, err)
+}
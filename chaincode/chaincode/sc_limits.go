@@ -0,0 +1,82 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// SetSpendingLimits replaces the user's daily and monthly spending limits.
+// The purchases of the current day and month count against the new limits.
+func (sc *SmartContract) SetSpendingLimits(ctx contractapi.TransactionContextInterface, userId string, limits models.SpendingLimits) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+
+	user, err := sc.ReadUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	user.Limits = limits
+	return updateModel(ctx, user.ID, user)
+}
+
+// FreezeAccount blocks every debit of the user's account: purchases, bids
+// and transfers. Credits such as refunds and released bids still go through.
+func (sc *SmartContract) FreezeAccount(ctx contractapi.TransactionContextInterface, userId string, reason string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("a reason is required to freeze an account")
+	}
+
+	user, err := sc.ReadUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if user.IsFrozen() {
+		return fmt.Errorf("account %s is already frozen", userId)
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	user.Freeze = &models.AccountFreeze{
+		Reason:   reason,
+		FrozenAt: now.Format(time.RFC3339),
+		TxID:     ctx.GetStub().GetTxID(),
+	}
+
+	return updateModel(ctx, user.ID, user)
+}
+
+func (sc *SmartContract) UnfreezeAccount(ctx contractapi.TransactionContextInterface, userId string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	user, err := sc.ReadUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsFrozen() {
+		return fmt.Errorf("account %s is not frozen", userId)
+	}
+
+	user.Freeze = nil
+	return updateModel(ctx, user.ID, user)
+}
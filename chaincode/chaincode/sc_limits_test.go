@@ -0,0 +1,89 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpendingLimits(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 31, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	putTestModel(t, state, models.Product{ID: "PRODUCT-m1", Name: "Milk", Price: rsd(3), Quantity: 100, TraderID: "tt1"})
+	putTestModel(t, state, models.Trader{ID: "TRADER-tt1", PIB: "100000008", VerificationStatus: models.VerificationVerified, Products: []string{"PRODUCT-m1"}, Receipts: []string{}})
+	putTestModel(t, state, models.User{ID: "USER-u1", Name: "Ana", LastName: "Jovanović", Email: "ana@example.com", Balances: rsdBalances(100), ReceiptsID: []string{}})
+
+	ctx, _ := newStatefulContext(state, &now)
+	setCaller(ctx, "Org1MSP", "client")
+	limits := models.SpendingLimits{Daily: rsdBalances(7), Monthly: rsdBalances(12)}
+	require.Error(t, sc.SetSpendingLimits(ctx, "u1", limits), "only admins set limits")
+
+	setCaller(ctx, "Org1MSP", "admin")
+	require.ErrorContains(t, sc.SetSpendingLimits(ctx, "u1", models.SpendingLimits{Daily: models.Balances{"EUR": rsd(7)}}), "must be given in EUR")
+	require.NoError(t, sc.SetSpendingLimits(ctx, "u1", limits))
+
+	require.NoError(t, sc.BuyProductQuantity(ctx, "m1", "u1", 2, ""))
+	require.ErrorContains(t, sc.BuyProduct(ctx, "m1", "u1"), "daily spending limit of 7.00 RSD")
+
+	spending := getTestModel[models.User](t, state, "USER-u1").Spending
+	require.Equal(t, "2026-05-31", spending.Day)
+	require.Equal(t, rsd(6), spending.Daily.Get(models.DefaultCurrency))
+
+	// the next day starts a new daily period and, here, a new month
+	now = now.Add(24 * time.Hour)
+	require.NoError(t, sc.BuyProductQuantity(ctx, "m1", "u1", 2, ""))
+
+	now = now.Add(24 * time.Hour)
+	require.NoError(t, sc.BuyProductQuantity(ctx, "m1", "u1", 2, ""))
+
+	now = now.Add(24 * time.Hour)
+	require.ErrorContains(t, sc.BuyProduct(ctx, "m1", "u1"), "monthly spending limit of 12.00 RSD")
+
+	user := getTestModel[models.User](t, state, "USER-u1")
+	require.Equal(t, "2026-06", user.Spending.Month)
+	require.Equal(t, rsd(12), user.Spending.Monthly.Get(models.DefaultCurrency))
+	require.Equal(t, rsd(100-6-6-6), user.Balances.Get(models.DefaultCurrency))
+}
+
+func TestFreezeAccount(t *testing.T) {
+	sc := SmartContract{}
+	state, start := setupAuctionState(t, false)
+	putTestModel(t, state, models.Product{ID: "PRODUCT-m1", Name: "Milk", Price: rsd(3), Quantity: 10, TraderID: "tt2"})
+
+	now := start.Add(10 * time.Minute)
+	ctx, _ := newStatefulContext(state, &now)
+	setCaller(ctx, "Org1MSP", "admin")
+
+	require.NoError(t, sc.PlaceBid(ctx, "a1", "u1", rsd(40)))
+
+	require.ErrorContains(t, sc.FreezeAccount(ctx, "u1", " "), "reason is required")
+	require.NoError(t, sc.FreezeAccount(ctx, "u1", "card reported stolen"))
+	require.ErrorContains(t, sc.FreezeAccount(ctx, "u1", "again"), "already frozen")
+
+	freeze := getTestModel[models.User](t, state, "USER-u1").Freeze
+	require.Equal(t, "card reported stolen", freeze.Reason)
+	require.Equal(t, now.Format(time.RFC3339), freeze.FrozenAt)
+
+	require.ErrorContains(t, sc.BuyProduct(ctx, "m1", "u1"), "account u1 is frozen: card reported stolen")
+	require.ErrorContains(t, sc.PlaceBid(ctx, "a1", "u1", rsd(60)), "frozen")
+	require.ErrorContains(t, sc.LockFunds(ctx, "l1", "u1", "u2", rsd(10), models.HashPreimage("secret"), now.Add(time.Hour).Format(time.RFC3339), "tradechannel2", "traderchaincode2"), "frozen")
+
+	// the frozen winner can't pay, so the bid is released
+	now = start.Add(time.Hour)
+	require.NoError(t, sc.SettleAuction(ctx, "a1"))
+	u1 := getTestModel[models.User](t, state, "USER-u1")
+	require.Equal(t, rsd(100), u1.Balances.Get(models.DefaultCurrency))
+	require.Empty(t, getTestModel[models.Auction](t, state, "AUCTION-a1").WinningBidID)
+
+	setCaller(ctx, "Org1MSP", "client")
+	require.Error(t, sc.UnfreezeAccount(ctx, "u1"), "only admins unfreeze accounts")
+
+	setCaller(ctx, "Org1MSP", "admin")
+	require.NoError(t, sc.UnfreezeAccount(ctx, "u1"))
+	require.ErrorContains(t, sc.UnfreezeAccount(ctx, "u1"), "not frozen")
+	require.NoError(t, sc.BuyProduct(ctx, "m1", "u1"))
+}
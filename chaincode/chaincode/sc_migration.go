@@ -0,0 +1,87 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"fmt"
+	"slices"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// MigrateState upgrades one page of the stored documents of the entity type
+// to the type's current schema version, starting at startKey, and writes the
+// index entries the documents are missing. Callers pass the returned next key
+// back until the page reports that the type is done, so that no single
+// transaction has to rewrite the whole state. Fabric doesn't allow writes
+// after a paginated query, so the page is a plain range scan that stops after
+// pageSize documents.
+func (sc *SmartContract) MigrateState(ctx contractapi.TransactionContextInterface, entityType string, pageSize int32, startKey string) (*models.MigrationPage, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(models.VersionedTypes, entityType) {
+		return nil, fmt.Errorf("%s isn't a stored entity type", entityType)
+	}
+
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be positive")
+	}
+
+	endKey := entityType + "."
+	if startKey == "" {
+		startKey = entityType + "-"
+	}
+	if startKey < entityType+"-" || startKey >= endKey {
+		return nil, fmt.Errorf("the start key %s isn't a %s key", startKey, entityType)
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByRange(startKey, endKey)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	page := &models.MigrationPage{
+		EntityType:    entityType,
+		SchemaVersion: models.SchemaVersion(entityType),
+	}
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		if page.Scanned == int(pageSize) {
+			page.NextKey = queryResponse.Key
+			break
+		}
+		page.Scanned++
+
+		upgraded, changed, err := models.UpgradeDocument(queryResponse.Key, queryResponse.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		// the indexes of records stored before them are built here too
+		indexed, err := reindex(ctx, queryResponse.Key, queryResponse.Value, upgraded)
+		if err != nil {
+			return nil, err
+		}
+		page.Indexed += indexed
+
+		if !changed {
+			continue
+		}
+
+		if err := ctx.GetStub().PutState(queryResponse.Key, upgraded); err != nil {
+			return nil, fmt.Errorf("failed to rewrite %s: %v", queryResponse.Key, err)
+		}
+		page.Migrated++
+	}
+
+	page.Done = page.NextKey == ""
+
+	return page, nil
+}
@@ -0,0 +1,167 @@
+package chaincode
+
+import (
+	"chaincode/chaincode/mocks"
+	"chaincode/models"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/require"
+)
+
+// setCaller makes the context report a client from the given msp with the
+// given node OU.
+func setCaller(ctx *mocks.TransactionContext, mspId string, ou string) {
+	identity := new(mocks.ClientIdentity)
+	identity.GetMSPIDReturns(mspId, nil)
+	identity.GetX509CertificateReturns(&x509.Certificate{Subject: pkix.Name{OrganizationalUnit: []string{ou}}}, nil)
+	ctx.GetClientIdentityReturns(identity)
+}
+
+// rangeIterator serves the keys of state within [startKey, endKey) in order.
+func rangeIterator(state map[string][]byte, startKey string, endKey string) shim.StateQueryIteratorInterface {
+	keys := make([]string, 0)
+	for key := range state {
+		if key >= startKey && key < endKey {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	iterator := new(mocks.StateQueryIterator)
+	next := 0
+	iterator.HasNextStub = func() bool {
+		return next < len(keys)
+	}
+	iterator.NextStub = func() (*queryresult.KV, error) {
+		key := keys[next]
+		next++
+		return &queryresult.KV{Key: key, Value: state[key]}, nil
+	}
+
+	return iterator
+}
+
+func TestMigrateMoney(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Now()
+	state := map[string][]byte{
+		"PRODUCT-b1": []byte(`{"id":"PRODUCT-b1","name":"Bread","expiration_date":"","price":3,"tiers":[{"min_quantity":2,"unit_price":2}],"quantity":10,"trader_id":"tt1"}`),
+		"USER-ou1":   []byte(`{"id":"USER-ou1","name":"Oleksandr","last_name":"Usyk","email":"","receipts_ids":[],"account_balance":1000}`),
+		// upgraded for the verification status before the money was
+		"TRADER-tt1": []byte(`{"schema_version":2,"id":"TRADER-tt1","pib":"100000008","products":[],"receipts":[],"account_balance":5,"verification_status":"VERIFIED"}`),
+	}
+
+	ctx, _ := newStatefulContext(state, &now)
+	setCaller(ctx, "Org1MSP", "admin")
+
+	for _, entityType := range []string{models.PRODUCT_TYPE, models.USER_TYPE, models.TRADER_TYPE} {
+		page, err := sc.MigrateState(ctx, entityType, 10, "")
+		require.NoError(t, err)
+		require.Equal(t, 1, page.Migrated, entityType)
+	}
+
+	require.Contains(t, string(state["PRODUCT-b1"]), `"price":{"amount":300,"currency":"RSD"}`)
+	require.Contains(t, string(state["PRODUCT-b1"]), `"unit_price":{"amount":200,"currency":"RSD"}`)
+
+	user := getTestModel[models.User](t, state, "USER-ou1")
+	require.Equal(t, rsdBalances(1000), user.Balances)
+	require.Contains(t, string(state["USER-ou1"]), `"balances":{"RSD":{"amount":100000,"currency":"RSD"}}`)
+	require.NotContains(t, string(state["USER-ou1"]), "account_balance")
+
+	trader := getTestModel[models.Trader](t, state, "TRADER-tt1")
+	require.Equal(t, rsd(5), trader.AccountBalance)
+	require.Equal(t, models.VerificationVerified, trader.VerificationStatus)
+
+	page, err := sc.MigrateState(ctx, models.PRODUCT_TYPE, 10, "")
+	require.NoError(t, err)
+	require.Equal(t, 0, page.Migrated)
+}
+
+// paginateRange serves pages of the keys of state within [startKey, endKey).
+// The bookmark is the first key of the next page.
+func paginateRange(state map[string][]byte) func(string, string, int32, string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	return func(startKey string, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+		if bookmark != "" {
+			startKey = bookmark
+		}
+
+		keys := make([]string, 0)
+		for key := range state {
+			if key >= startKey && key < endKey {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		page := map[string][]byte{}
+		next := ""
+		for i, key := range keys {
+			if i == int(pageSize) {
+				next = key
+				break
+			}
+			page[key] = state[key]
+		}
+
+		return rangeIterator(page, startKey, endKey), &peer.QueryResponseMetadata{FetchedRecordsCount: int32(len(page)), Bookmark: next}, nil
+	}
+}
+
+func TestMigrateState(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Now()
+	state := map[string][]byte{
+		"PRODUCT-b1": []byte(`{"id":"PRODUCT-b1","name":"Bread","price":{"amount":300,"currency":"RSD"},"quantity":10,"trader_id":"tt1"}`),
+		"PRODUCT-b2": []byte(`{"id":"PRODUCT-b2","name":"Bagel","price":{"amount":200,"currency":"RSD"},"quantity":10,"trader_id":"tt1"}`),
+	}
+	putTestModel(t, state, models.Product{ID: "PRODUCT-b3", Name: "Bun", Price: rsd(1), Quantity: 1, TraderID: "tt1"})
+	putTestModel(t, state, models.User{ID: "USER-u1", Name: "Ana", LastName: "Jovanović", Email: "USER-u1@example.com", Balances: rsdBalances(1)})
+
+	ctx, stub := newStatefulContext(state, &now)
+
+	setCaller(ctx, "Org1MSP", "client")
+	_, err := sc.MigrateState(ctx, models.PRODUCT_TYPE, 2, "")
+	require.Error(t, err)
+
+	setCaller(ctx, "Org1MSP", "admin")
+	_, err = sc.MigrateState(ctx, "UNKNOWN", 2, "")
+	require.Error(t, err)
+
+	page, err := sc.MigrateState(ctx, models.PRODUCT_TYPE, 2, "")
+	require.NoError(t, err)
+	require.Equal(t, 2, page.Scanned)
+	require.Equal(t, 2, page.Migrated)
+	require.Equal(t, "PRODUCT-b3", page.NextKey)
+	require.False(t, page.Done)
+	require.Contains(t, string(state["PRODUCT-b1"]), `"schema_version":2`)
+
+	_, err = sc.MigrateState(ctx, models.PRODUCT_TYPE, 2, "USER-u1")
+	require.Error(t, err, "the start key of another type")
+
+	page, err = sc.MigrateState(ctx, models.PRODUCT_TYPE, 2, page.NextKey)
+	require.NoError(t, err)
+	require.Equal(t, 1, page.Scanned)
+	require.Equal(t, 0, page.Migrated)
+	require.True(t, page.Done)
+
+	// the pages write, so they aren't read with a paginated query
+	require.Zero(t, stub.GetStateByRangeWithPaginationCallCount())
+
+	product, err := sc.ReadProduct(ctx, "b2")
+	require.NoError(t, err)
+	require.Equal(t, "Bagel", product.Name)
+
+	// documents written by a newer chaincode aren't guessed at
+	state["PRODUCT-b4"] = []byte(`{"schema_version":99,"id":"PRODUCT-b4"}`)
+	_, err = sc.MigrateState(ctx, models.PRODUCT_TYPE, 10, "")
+	require.Error(t, err)
+	_, err = sc.ReadProduct(ctx, "b4")
+	require.Error(t, err)
+}
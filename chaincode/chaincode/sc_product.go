@@ -4,8 +4,7 @@ import (
 	"chaincode/models"
 	"encoding/json"
 	"fmt"
-	"strconv"
-	"time"
+	"slices"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
@@ -28,10 +27,13 @@ func (sc *SmartContract) GetAllProducts(ctx contractapi.TransactionContextInterf
 		if queryResponse == nil {
 			return nil, fmt.Errorf("no next")
 		}
-		err = json.Unmarshal(queryResponse.Value, &asset)
+		err = models.DecodeModel(queryResponse.Key, queryResponse.Value, &asset)
 		if err != nil {
 			return nil, err
 		}
+		if asset.IsArchived() {
+			continue
+		}
 		assets = append(assets, &asset)
 	}
 
@@ -42,13 +44,15 @@ func (sc *SmartContract) QueryProducts(ctx contractapi.TransactionContextInterfa
 	selector := make(map[string]interface{})
 
 	for key, value := range filters {
-		// Ako je filter za cenu, koristi numeričko poređenje
+		// Ako je filter za cenu, koristi numeričko poređenje u najmanjim jedinicama
 		if key == "price" {
-			priceVal, err := strconv.ParseFloat(value, 64)
+			price, err := models.ParseMoney(value, models.DefaultCurrency)
 			if err != nil {
 				return nil, fmt.Errorf("invalid price value: %v", err)
 			}
-			selector["price"] = map[string]interface{}{"$eq": priceVal}
+			selector["price.amount"] = map[string]interface{}{"$eq": price.Amount}
+		} else if key == "currency" {
+			selector["price.currency"] = map[string]interface{}{"$eq": value}
 		} else {
 			// Za ostale koristi direktno poređenje
 			selector[key] = map[string]interface{}{"$eq": value}
@@ -79,9 +83,12 @@ func (sc *SmartContract) QueryProducts(ctx contractapi.TransactionContextInterfa
 			return nil, err
 		}
 		var product models.Product
-		if err := json.Unmarshal(response.Value, &product); err != nil {
+		if err := models.DecodeModel(response.Key, response.Value, &product); err != nil {
 			return nil, err
 		}
+		if product.IsArchived() {
+			continue
+		}
 
 		products = append(products, &product)
 	}
@@ -90,76 +97,231 @@ func (sc *SmartContract) QueryProducts(ctx contractapi.TransactionContextInterfa
 }
 
 func (sc *SmartContract) BuyProduct(ctx contractapi.TransactionContextInterface, productId string, userId string) error {
-	user, err := sc.ReadUser(ctx, userId)
-	if err != nil {
+	return sc.buyProduct(ctx, productId, userId, 1, "")
+}
+
+// BuyProductWithCurrency pays for the product from the user's balance in the
+// given currency. The price is converted with the current exchange rate,
+// which is recorded on the receipt.
+func (sc *SmartContract) BuyProductWithCurrency(ctx contractapi.TransactionContextInterface, productId string, userId string, currency string) error {
+	if err := models.NewMoney(0, currency).Validate(); err != nil {
 		return err
 	}
 
+	return sc.buyProduct(ctx, productId, userId, 1, currency)
+}
+
+// BuyProductQuantity buys several units at once at the unit price of the
+// product's tier for the quantity. An empty currency pays in the product's
+// currency.
+func (sc *SmartContract) BuyProductQuantity(ctx contractapi.TransactionContextInterface, productId string, userId string, quantity uint, currency string) error {
+	if quantity == 0 {
+		return fmt.Errorf("quantity must be greater than zero")
+	}
+
+	if currency != "" {
+		if err := models.NewMoney(0, currency).Validate(); err != nil {
+			return err
+		}
+	}
+
+	return sc.buyProduct(ctx, productId, userId, quantity, currency)
+}
+
+// buyProduct pays in the currency, or in the product's currency when it's
+// empty.
+func (sc *SmartContract) buyProduct(ctx contractapi.TransactionContextInterface, productId string, userId string, quantity uint, currency string) error {
 	product, err := sc.ReadProduct(ctx, productId)
 	if err != nil {
 		return err
 	}
 
+	if err := requireActive(product, product.ID); err != nil {
+		return err
+	}
+
+	if err := sc.sweepReservations(ctx, product); err != nil {
+		return err
+	}
+
+	if product.Quantity < quantity {
+		return fmt.Errorf("only %d units of product %s are available", product.Quantity, productId)
+	}
+	product.Quantity -= quantity
+
+	return sc.sellProduct(ctx, product, userId, quantity, currency)
+}
+
+// sellProduct charges the user for quantity units of the product, which were
+// already taken from its stock, and stores the receipt and the product.
+func (sc *SmartContract) sellProduct(ctx contractapi.TransactionContextInterface, product *models.Product, userId string, quantity uint, currency string) error {
+	productId := models.TrimKeyPrefix(models.PRODUCT_TYPE, product.ID)
+
+	user, err := sc.ReadUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if err := requireActive(user, user.ID); err != nil {
+		return err
+	}
+
 	trader, err := sc.ReadTrader(ctx, product.TraderID)
 	if err != nil {
 		return err
 	}
 
-	if product.Price > user.AccountBalance {
-		return fmt.Errorf("user doesn't have enough funds to buy the product")
+	if err := requireActive(trader, trader.ID); err != nil {
+		return err
 	}
 
-	product.Quantity -= 1
-	user.AccountBalance -= product.Price
-	trader.AccountBalance += product.Price
+	if err := requireVerified(trader); err != nil {
+		return err
+	}
 
-	receipt := models.Receipt{
-		ID:        fmt.Sprintf("%s-%s-%s-%d", user.ID, product.TraderID, product.ID, len(user.ReceiptsID)),
-		TraderID:  product.TraderID,
-		UserID:    userId,
-		ProductID: productId,
-		Date:      time.Now().UTC().Format("02-01-2006"),
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
 	}
 
-	if product.Quantity == 0 {
-		if err := sc.DeleteProduct(ctx, productId); err != nil {
-			return fmt.Errorf("failed to remove the product: %v", err)
-		}
+	unitPrice, tier := product.UnitPrice(quantity)
+	price, err := unitPrice.Mul(quantity)
+	if err != nil {
+		return err
+	}
+
+	if currency == "" {
+		currency = price.Currency
+	}
+
+	paid, rate, err := sc.convert(ctx, price, currency)
+	if err != nil {
+		return err
+	}
+
+	receiptId, err := nextReceiptNumber(ctx, product.TraderID, now)
+	if err != nil {
+		return err
+	}
+
+	if err := user.Spend(paid, now); err != nil {
+		return err
+	}
+
+	if err := debitUser(ctx, user, paid, models.MovementPurchase, receiptId, models.ContraLines(traderAccount(trader.ID), models.Credit, paid, price)...); err != nil {
+		return fmt.Errorf("user can't pay for the product: %v", err)
+	}
+
+	receipt := models.Receipt{
+		ID:           receiptId,
+		TraderID:     product.TraderID,
+		UserID:       userId,
+		ProductID:    productId,
+		Price:        price,
+		Quantity:     quantity,
+		Tier:         tier,
+		Paid:         paid,
+		ExchangeRate: rate,
+		Date:         now.Format(models.ReceiptDateLayout),
 	}
 
 	user.ReceiptsID = append(user.ReceiptsID, receipt.ID)
-	trader.Receipts = append(trader.Receipts, receipt.ID)
 
-	if err := sc.CreateReceipt(ctx, receipt); err != nil {
+	if err := storeReceipt(ctx, receipt); err != nil {
+		return err
+	}
+
+	// the trader is paid with a delta, so that the sale doesn't write its
+	// document
+	if err := creditTrader(ctx, product.TraderID, price, receipt.ID); err != nil {
 		return err
 	}
 
+	// sold out products are archived, so that their receipts still refer to
+	// a stored product
+	if product.SoldOut() {
+		product.Archive(now)
+	}
+
 	if err := sc.UpdateProduct(ctx, productId, product); err != nil {
 		return err
 	}
 
-	if err := sc.UpdateTrader(ctx, product.TraderID, trader); err != nil {
+	return sc.UpdateUser(ctx, userId, user)
+}
+
+func (sc *SmartContract) CreateProduct(ctx contractapi.TransactionContextInterface, product models.Product) error {
+	if err := models.Validate(product); err != nil {
 		return err
 	}
 
-	if err := sc.UpdateUser(ctx, userId, user); err != nil {
+	if err := product.ValidatePricing(); err != nil {
 		return err
 	}
 
-	return nil
+	if err := checkProductReferences(ctx, &product, true); err != nil {
+		return err
+	}
+
+	trader, err := sc.ReadTrader(ctx, product.TraderID)
+	if err != nil {
+		return err
+	}
+
+	productId := product.ID
+	if err := sc.prepareProduct(ctx, &product, trader); err != nil {
+		return err
+	}
+
+	if err := createModel(ctx, product); err != nil {
+		return err
+	}
+
+	if err := setEndorsementPolicy(ctx, product.ID, trader.EndorsingOrgs()); err != nil {
+		return err
+	}
+
+	if !slices.Contains(trader.Products, productId) {
+		trader.Products = append(trader.Products, productId)
+	}
+
+	return sc.UpdateTrader(ctx, product.TraderID, trader)
 }
 
-func (sc *SmartContract) CreateProduct(ctx contractapi.TransactionContextInterface, product models.Product) error {
+// prepareProduct checks that a new product of the trader is priced in its
+// currency and has the attributes of its category, and resets the fields the
+// chaincode manages.
+func (sc *SmartContract) prepareProduct(ctx contractapi.TransactionContextInterface, product *models.Product, trader *models.Trader) error {
+	if product.Price.Currency != trader.SettlementCurrency() {
+		return fmt.Errorf("the product must be priced in the trader's currency %s", trader.SettlementCurrency())
+	}
+
+	if err := sc.checkProductAttributes(ctx, product, trader); err != nil {
+		return err
+	}
+
 	product.ID = models.ToProductID(product.ID)
-	return createModel(ctx, product)
+	product.Reserved = 0
+	product.Reservations = make([]string, 0)
+
+	return nil
 }
 
 func (sc *SmartContract) UpdateProduct(ctx contractapi.TransactionContextInterface, id string, model *models.Product) error {
+	if err := model.ValidatePricing(); err != nil {
+		return err
+	}
+
+	if err := checkProductReferences(ctx, model, false); err != nil {
+		return err
+	}
+
 	return updateModel(ctx, models.ToProductID(id), model)
 }
 
+// DeleteProduct archives the product. It's no longer listed or sold.
 func (sc *SmartContract) DeleteProduct(ctx contractapi.TransactionContextInterface, id string) error {
-	return deleteModel(ctx, models.ToProductID(id))
+	return sc.deleteRecord(ctx, models.PRODUCT_TYPE, id)
 }
 
 func (sc *SmartContract) ReadProduct(ctx contractapi.TransactionContextInterface, id string) (*models.Product, error) {
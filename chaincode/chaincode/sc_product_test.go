@@ -0,0 +1,47 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuyProductQuantityAppliesTier(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	putTestModel(t, state, models.User{ID: "USER-u1", Name: "Ana", LastName: "Jovanović", Email: "USER-u1@example.com", Balances: models.Balances{"EUR": eur(100)}, ReceiptsID: []string{}})
+	putTestModel(t, state, models.Trader{ID: "TRADER-tt2", PIB: "100000008", VerificationStatus: models.VerificationVerified, Currency: "EUR", Products: []string{"ti1"}, Receipts: []string{}})
+	ctx, _ := newStatefulContext(state, &now)
+
+	invalid := models.Product{ID: "ti1", Name: "Product ti1", Price: eur(8), Tiers: []models.PriceTier{{MinQuantity: 4, UnitPrice: eur(9)}}, Quantity: 10, TraderID: "tt2"}
+	require.Error(t, sc.CreateProduct(ctx, invalid), "tier above the base price")
+
+	tires := models.Product{ID: "ti1", Name: "Product ti1", Price: eur(8), Tiers: []models.PriceTier{{MinQuantity: 4, UnitPrice: eur(7)}}, Quantity: 10, TraderID: "tt2"}
+	require.NoError(t, sc.CreateProduct(ctx, tires))
+
+	reordered := getTestModel[models.Product](t, state, "PRODUCT-ti1")
+	reordered.Tiers = []models.PriceTier{{MinQuantity: 6, UnitPrice: eur(6)}, {MinQuantity: 4, UnitPrice: eur(7)}}
+	require.Error(t, sc.UpdateProduct(ctx, "ti1", &reordered), "tiers out of order")
+
+	require.NoError(t, sc.BuyProductQuantity(ctx, "ti1", "u1", 3, ""))
+	require.NoError(t, sc.BuyProductQuantity(ctx, "ti1", "u1", 4, ""))
+	require.Error(t, sc.BuyProductQuantity(ctx, "ti1", "u1", 4, ""), "only three units are left")
+
+	user := getTestModel[models.User](t, state, "USER-u1")
+	require.Equal(t, eur(48), user.Balances.Get("EUR"))
+
+	base := getTestModel[models.Receipt](t, state, models.ToReceiptID(user.ReceiptsID[0]))
+	require.Equal(t, eur(24), base.Price)
+	require.Nil(t, base.Tier)
+
+	bulk := getTestModel[models.Receipt](t, state, models.ToReceiptID(user.ReceiptsID[1]))
+	require.Equal(t, eur(28), bulk.Price)
+	require.Equal(t, uint(4), bulk.Quantity)
+	require.Equal(t, &models.PriceTier{MinQuantity: 4, UnitPrice: eur(7)}, bulk.Tier)
+
+	require.Equal(t, uint(3), getTestModel[models.Product](t, state, "PRODUCT-ti1").Quantity)
+}
@@ -2,8 +2,8 @@ package chaincode
 
 import (
 	"chaincode/models"
-	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
@@ -12,15 +12,71 @@ func (sc *SmartContract) ReadReceipt(ctx contractapi.TransactionContextInterface
 	return readModel[models.Receipt](ctx, models.ToReceiptID(id))
 }
 
+// DeleteReceipt archives the receipt. It stays in the receipt lists of its
+// user and trader.
 func (sc *SmartContract) DeleteReceipt(ctx contractapi.TransactionContextInterface, id string) error {
-	return deleteModel(ctx, models.ToReceiptID(id))
+	return sc.deleteRecord(ctx, models.RECEIPT_TYPE, id)
 }
 
-func (sc *SmartContract) CreateReceipt(ctx contractapi.TransactionContextInterface, receipt models.Receipt) error {
+// storeReceipt stores the receipt, which was numbered with nextReceiptNumber.
+// Receipts are only created by the sales that pay for them, there's no
+// transaction creating a receipt on its own.
+func storeReceipt(ctx contractapi.TransactionContextInterface, receipt models.Receipt) error {
+	if err := checkReceiptReferences(ctx, &receipt); err != nil {
+		return err
+	}
+
 	receipt.ID = models.ToReceiptID(receipt.ID)
 	return createModel(ctx, receipt)
 }
 
+// RefundReceipt returns what the user paid for the receipt and takes the
+// price back from the trader. The sold units aren't returned to the stock.
+func (sc *SmartContract) RefundReceipt(ctx contractapi.TransactionContextInterface, id string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	receipt, err := sc.ReadReceipt(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if receipt.RefundedAt != "" {
+		return fmt.Errorf("receipt %s was already refunded", id)
+	}
+
+	user, err := sc.ReadUser(ctx, receipt.UserID)
+	if err != nil {
+		return err
+	}
+
+	trader, err := sc.ReadTrader(ctx, receipt.TraderID)
+	if err != nil {
+		return err
+	}
+
+	if err := creditUser(ctx, user, receipt.AmountPaid(), models.MovementRefund, models.TrimKeyPrefix(models.RECEIPT_TYPE, receipt.ID), models.ContraLines(traderAccount(trader.ID), models.Debit, receipt.AmountPaid(), receipt.Price)...); err != nil {
+		return err
+	}
+
+	if err := debitTrader(ctx, trader, receipt.Price, models.TrimKeyPrefix(models.RECEIPT_TYPE, receipt.ID)); err != nil {
+		return fmt.Errorf("trader can't refund the receipt: %v", err)
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	receipt.RefundedAt = now.Format(time.RFC3339)
+
+	if err := updateModel(ctx, receipt.ID, receipt); err != nil {
+		return err
+	}
+
+	return sc.UpdateUser(ctx, receipt.UserID, user)
+}
+
 func (sc *SmartContract) GetAllReceips(ctx contractapi.TransactionContextInterface) ([]*models.Receipt, error) {
 	resultsIterator, err := ctx.GetStub().GetQueryResult(models.BuildQueryIdStartsWith(models.RECEIPT_TYPE))
 	if err != nil {
@@ -39,10 +95,13 @@ func (sc *SmartContract) GetAllReceips(ctx contractapi.TransactionContextInterfa
 		if queryResponse == nil {
 			return nil, fmt.Errorf("no next")
 		}
-		err = json.Unmarshal(queryResponse.Value, &asset)
+		err = models.DecodeModel(queryResponse.Key, queryResponse.Value, &asset)
 		if err != nil {
 			return nil, err
 		}
+		if asset.IsArchived() {
+			continue
+		}
 		assets = append(assets, &asset)
 	}
 
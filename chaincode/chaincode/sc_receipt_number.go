@@ -0,0 +1,128 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// nextReceiptNumber takes the next fiscal number of the trader's receipts in
+// the year of now. The counter is read and written in the transaction that
+// stores the receipt, so a failed transaction takes no number and the
+// trader's receipts of a year are numbered without gaps.
+//
+// The counter is a hot key of the trader: concurrent sales of a trader
+// conflict on it, only one of them commits per block and the others fail
+// with an MVCC read conflict. The client resubmits a failed sale once. This
+// gives up the trader deltas' aim of committing a trader's sales together;
+// numbering the receipts later, at compaction, would leave sold receipts
+// without a fiscal number until then.
+func nextReceiptNumber(ctx contractapi.TransactionContextInterface, traderId string, now time.Time) (string, error) {
+	traderId = models.TrimKeyPrefix(models.TRADER_TYPE, traderId)
+	year := now.Year()
+	id := models.ToReceiptSequenceID(traderId, year)
+
+	exists, err := modelExists(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	sequence := &models.ReceiptSequence{ID: id, TraderID: traderId, Year: year}
+	if exists {
+		if sequence, err = readModel[models.ReceiptSequence](ctx, id); err != nil {
+			return "", err
+		}
+	}
+
+	sequence.Last++
+
+	sequenceJson, err := models.EncodeModel(id, sequence)
+	if err != nil {
+		return "", err
+	}
+
+	if err := ctx.GetStub().PutState(id, sequenceJson); err != nil {
+		return "", err
+	}
+
+	return models.FormatReceiptNumber(traderId, year, sequence.Last), nil
+}
+
+// GetReceiptByNumber reads the receipt with the fiscal number.
+func (sc *SmartContract) GetReceiptByNumber(ctx contractapi.TransactionContextInterface, number string) (*models.Receipt, error) {
+	if _, _, _, err := models.ParseReceiptNumber(number); err != nil {
+		return nil, err
+	}
+
+	return sc.ReadReceipt(ctx, number)
+}
+
+// CheckReceiptSequence compares the receipts the trader issued in the year
+// with its counter and reports the numbers without a receipt and the receipts
+// the counter didn't number.
+func (sc *SmartContract) CheckReceiptSequence(ctx contractapi.TransactionContextInterface, traderId string, year int) (*models.SequenceReport, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	traderId = models.TrimKeyPrefix(models.TRADER_TYPE, traderId)
+	if _, err := sc.ReadTrader(ctx, traderId); err != nil {
+		return nil, err
+	}
+
+	report := &models.SequenceReport{TraderID: traderId, Year: year, Missing: []string{}, Unexpected: []string{}}
+
+	id := models.ToReceiptSequenceID(traderId, year)
+	exists, err := modelExists(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		sequence, err := readModel[models.ReceiptSequence](ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		report.Last = sequence.Last
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByRange(models.ReceiptNumberKeyRange(traderId, year))
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	issued := map[uint64]bool{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		number := models.TrimKeyPrefix(models.RECEIPT_TYPE, queryResponse.Key)
+		trader, receiptYear, seq, err := models.ParseReceiptNumber(number)
+		if err == nil && (trader != traderId || receiptYear != year) {
+			// the number of a trader whose id extends this one
+			continue
+		}
+
+		if err != nil || seq > report.Last || issued[seq] {
+			report.Unexpected = append(report.Unexpected, number)
+			continue
+		}
+
+		issued[seq] = true
+	}
+
+	for seq := uint64(1); seq <= report.Last; seq++ {
+		if !issued[seq] {
+			report.Missing = append(report.Missing, models.FormatReceiptNumber(traderId, year, seq))
+		}
+	}
+
+	report.Issued = len(issued)
+	report.Complete = len(report.Missing) == 0 && len(report.Unexpected) == 0
+
+	return report, nil
+}
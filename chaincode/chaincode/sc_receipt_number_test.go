@@ -0,0 +1,60 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiptNumbers(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 12, 31, 23, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	putTestModel(t, state, models.User{ID: "USER-u1", Name: "Ana", LastName: "Jovanović", Email: "USER-u1@example.com", Balances: rsdBalances(100), ReceiptsID: []string{}})
+	putTestModel(t, state, models.Trader{ID: "TRADER-tt1", PIB: "100000008", VerificationStatus: models.VerificationVerified, Currency: "RSD", Products: []string{"m1"}, Receipts: []string{}})
+	putTestModel(t, state, models.Trader{ID: "TRADER-tt1-2026", PIB: "100000016", VerificationStatus: models.VerificationVerified, Currency: "RSD", Products: []string{"b1"}, Receipts: []string{}})
+	putTestModel(t, state, models.Product{ID: "PRODUCT-m1", Name: "Milk", Price: rsd(3), Quantity: 10, TraderID: "tt1"})
+	putTestModel(t, state, models.Product{ID: "PRODUCT-b1", Name: "Bread", Price: rsd(2), Quantity: 10, TraderID: "tt1-2026"})
+
+	ctx, _ := newStatefulContext(state, &now)
+	setCaller(ctx, "Org1MSP", adminOU)
+
+	require.NoError(t, sc.BuyProduct(ctx, "m1", "u1"))
+	require.NoError(t, sc.BuyProduct(ctx, "b1", "u1"))
+	require.NoError(t, sc.BuyProduct(ctx, "m1", "u1"))
+
+	now = now.Add(2 * time.Hour)
+	require.NoError(t, sc.BuyProduct(ctx, "m1", "u1"))
+
+	user := getTestModel[models.User](t, state, "USER-u1")
+	require.Equal(t, []string{"tt1-2026-000001", "tt1-2026-2026-000001", "tt1-2026-000002", "tt1-2027-000001"}, user.ReceiptsID)
+
+	receipt, err := sc.GetReceiptByNumber(ctx, "tt1-2026-000002")
+	require.NoError(t, err)
+	require.Equal(t, "RECEIPT-tt1-2026-000002", receipt.ID)
+	require.Equal(t, "m1", receipt.ProductID)
+
+	_, err = sc.GetReceiptByNumber(ctx, "tt1-000002")
+	require.Error(t, err)
+
+	report, err := sc.CheckReceiptSequence(ctx, "tt1", 2026)
+	require.NoError(t, err)
+	require.Equal(t, &models.SequenceReport{TraderID: "tt1", Year: 2026, Last: 2, Issued: 2, Missing: []string{}, Unexpected: []string{}, Complete: true}, report)
+
+	delete(state, "RECEIPT-tt1-2026-000001")
+	putTestModel(t, state, models.Receipt{ID: "RECEIPT-tt1-2026-000009", UserID: "u1", TraderID: "tt1", ProductID: "m1"})
+
+	report, err = sc.CheckReceiptSequence(ctx, "tt1", 2026)
+	require.NoError(t, err)
+	require.Equal(t, []string{"tt1-2026-000001"}, report.Missing)
+	require.Equal(t, []string{"tt1-2026-000009"}, report.Unexpected)
+	require.Equal(t, 1, report.Issued)
+	require.False(t, report.Complete)
+
+	setCaller(ctx, "Org1MSP", "client")
+	_, err = sc.CheckReceiptSequence(ctx, "tt1", 2026)
+	require.Error(t, err)
+}
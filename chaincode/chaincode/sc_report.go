@@ -0,0 +1,164 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// topCustomersCount is the number of customers listed in a sales report.
+const topCustomersCount = 5
+
+// parsePeriod reads the inclusive period between the from and to dates.
+func parsePeriod(from string, to string) (time.Time, time.Time, error) {
+	fromDate, err := time.Parse(models.ReportDateLayout, from)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid from date: %v", err)
+	}
+
+	toDate, err := time.Parse(models.ReportDateLayout, to)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid to date: %v", err)
+	}
+
+	if toDate.Before(fromDate) {
+		return time.Time{}, time.Time{}, fmt.Errorf("the period ends before it starts")
+	}
+
+	return fromDate, toDate, nil
+}
+
+func inPeriod(date time.Time, from time.Time, to time.Time) bool {
+	return !date.Before(from) && date.Before(to.AddDate(0, 0, 1))
+}
+
+// GetTraderSalesReport builds the trader's sales report for the period
+// between the from and to dates, given as YYYY-MM-DD.
+func (sc *SmartContract) GetTraderSalesReport(ctx contractapi.TransactionContextInterface, traderId string, from string, to string) (*models.SalesReport, error) {
+	fromDate, toDate, err := parsePeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	trader, err := sc.ReadTrader(ctx, traderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyTraderDeltas(ctx, trader); err != nil {
+		return nil, err
+	}
+
+	report := &models.SalesReport{
+		TraderID:     traderId,
+		From:         from,
+		To:           to,
+		Revenue:      models.NewMoney(0, trader.SettlementCurrency()),
+		Refunds:      models.NewMoney(0, trader.SettlementCurrency()),
+		Products:     make([]*models.ProductSales, 0),
+		TopCustomers: make([]*models.CustomerSales, 0),
+		Daily:        make([]*models.DailySales, 0),
+	}
+
+	products := make(map[string]*models.ProductSales)
+	customers := make(map[string]*models.CustomerSales)
+	days := make(map[string]*models.DailySales)
+
+	day := func(date time.Time) *models.DailySales {
+		key := date.Format(models.ReportDateLayout)
+		if days[key] == nil {
+			days[key] = &models.DailySales{Date: key}
+			report.Daily = append(report.Daily, days[key])
+		}
+		return days[key]
+	}
+
+	for _, receiptId := range trader.Receipts {
+		receipt, err := sc.ReadReceipt(ctx, receiptId)
+		if err != nil {
+			return nil, err
+		}
+
+		if receipt.RefundedAt != "" {
+			refundedAt, err := time.Parse(time.RFC3339, receipt.RefundedAt)
+			if err != nil {
+				return nil, fmt.Errorf("invalid refund time of receipt %s: %v", receiptId, err)
+			}
+
+			if inPeriod(refundedAt, fromDate, toDate) {
+				daily := day(refundedAt)
+				if daily.Refunds, err = daily.Refunds.Add(receipt.Price); err != nil {
+					return nil, err
+				}
+				if report.Refunds, err = report.Refunds.Add(receipt.Price); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		date, err := time.Parse(models.ReceiptDateLayout, receipt.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date of receipt %s: %v", receiptId, err)
+		}
+
+		if !inPeriod(date, fromDate, toDate) {
+			continue
+		}
+
+		units := receipt.Units()
+		report.Receipts++
+		report.Units += units
+		if report.Revenue, err = report.Revenue.Add(receipt.Price); err != nil {
+			return nil, err
+		}
+
+		if products[receipt.ProductID] == nil {
+			products[receipt.ProductID] = &models.ProductSales{ProductID: receipt.ProductID}
+			report.Products = append(report.Products, products[receipt.ProductID])
+		}
+		product := products[receipt.ProductID]
+		product.Units += units
+		if product.Revenue, err = product.Revenue.Add(receipt.Price); err != nil {
+			return nil, err
+		}
+
+		if customers[receipt.UserID] == nil {
+			customers[receipt.UserID] = &models.CustomerSales{UserID: receipt.UserID}
+			report.TopCustomers = append(report.TopCustomers, customers[receipt.UserID])
+		}
+		customer := customers[receipt.UserID]
+		customer.Receipts++
+		if customer.Spent, err = customer.Spent.Add(receipt.Price); err != nil {
+			return nil, err
+		}
+
+		daily := day(date)
+		daily.Units += units
+		if daily.Revenue, err = daily.Revenue.Add(receipt.Price); err != nil {
+			return nil, err
+		}
+	}
+
+	if report.NetRevenue, err = report.Revenue.Sub(report.Refunds); err != nil {
+		// refunds of sales from before the period can exceed its revenue
+		report.NetRevenue = models.NewMoney(0, report.Revenue.Currency)
+	}
+
+	sort.SliceStable(report.Products, func(i, j int) bool {
+		return report.Products[i].Revenue.Amount > report.Products[j].Revenue.Amount
+	})
+	sort.SliceStable(report.TopCustomers, func(i, j int) bool {
+		return report.TopCustomers[i].Spent.Amount > report.TopCustomers[j].Spent.Amount
+	})
+	if len(report.TopCustomers) > topCustomersCount {
+		report.TopCustomers = report.TopCustomers[:topCustomersCount]
+	}
+	sort.Slice(report.Daily, func(i, j int) bool {
+		return report.Daily[i].Date < report.Daily[j].Date
+	})
+
+	return report, nil
+}
@@ -0,0 +1,66 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTraderSalesReport(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	putTestModel(t, state, models.User{ID: "USER-u1", Name: "Ana", LastName: "Jovanović", Email: "USER-u1@example.com", Balances: rsdBalances(100), ReceiptsID: []string{}})
+	putTestModel(t, state, models.User{ID: "USER-u2", Name: "Ana", LastName: "Jovanović", Email: "USER-u2@example.com", Balances: rsdBalances(100), ReceiptsID: []string{}})
+	putTestModel(t, state, models.Trader{ID: "TRADER-tt1", PIB: "100000008", VerificationStatus: models.VerificationVerified, Currency: "RSD", Products: []string{"t1", "m1"}, Receipts: []string{}})
+	putTestModel(t, state, models.Product{ID: "PRODUCT-t1", Name: "Tomato", Price: rsd(2), Quantity: 10, TraderID: "tt1"})
+	putTestModel(t, state, models.Product{ID: "PRODUCT-m1", Name: "Milk", Price: rsd(3), Quantity: 10, TraderID: "tt1"})
+
+	ctx, _ := newStatefulContext(state, &now)
+	setCaller(ctx, "Org1MSP", "admin")
+
+	require.NoError(t, sc.BuyProductQuantity(ctx, "t1", "u1", 3, ""))
+	now = now.AddDate(0, 0, 1)
+	require.NoError(t, sc.BuyProduct(ctx, "m1", "u2"))
+	require.NoError(t, sc.BuyProduct(ctx, "t1", "u2"))
+	now = now.AddDate(0, 0, 1)
+	require.NoError(t, sc.BuyProduct(ctx, "m1", "u1"))
+
+	require.Len(t, getTestTrader(t, ctx, state, "TRADER-tt1").Receipts, 4)
+	receipt := getTestModel[models.User](t, state, "USER-u2").ReceiptsID[0]
+	require.NoError(t, sc.RefundReceipt(ctx, receipt))
+	require.Error(t, sc.RefundReceipt(ctx, receipt), "refunded twice")
+
+	_, err := sc.GetTraderSalesReport(ctx, "tt1", "2026-05-03", "2026-05-01")
+	require.Error(t, err)
+
+	report, err := sc.GetTraderSalesReport(ctx, "tt1", "2026-05-01", "2026-05-02")
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Receipts)
+	require.Equal(t, uint(5), report.Units)
+	require.Equal(t, rsd(11), report.Revenue)
+	require.Equal(t, rsd(0), report.Refunds, "the refund was made after the period")
+	require.Equal(t, rsd(11), report.NetRevenue)
+
+	require.Equal(t, []*models.ProductSales{
+		{ProductID: "t1", Units: 4, Revenue: rsd(8)},
+		{ProductID: "m1", Units: 1, Revenue: rsd(3)},
+	}, report.Products)
+	require.Equal(t, []*models.CustomerSales{
+		{UserID: "u1", Receipts: 1, Spent: rsd(6)},
+		{UserID: "u2", Receipts: 2, Spent: rsd(5)},
+	}, report.TopCustomers)
+	require.Len(t, report.Daily, 2)
+	require.Equal(t, "2026-05-02", report.Daily[1].Date)
+	require.Equal(t, rsd(5), report.Daily[1].Revenue)
+
+	report, err = sc.GetTraderSalesReport(ctx, "tt1", "2026-05-03", "2026-05-03")
+	require.NoError(t, err)
+	require.Equal(t, rsd(3), report.Revenue)
+	require.Equal(t, rsd(3), report.Refunds)
+	require.Equal(t, rsd(0), report.NetRevenue)
+	require.Equal(t, rsd(98), getTestModel[models.User](t, state, "USER-u2").Balances.Get("RSD"))
+}
@@ -0,0 +1,200 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// reservationTimeout is how long a reservation holds the stock for the user
+// to finish the checkout.
+const reservationTimeout = 15 * time.Minute
+
+func (sc *SmartContract) ReadReservation(ctx contractapi.TransactionContextInterface, id string) (*models.Reservation, error) {
+	return readModel[models.Reservation](ctx, models.ToReservationID(id))
+}
+
+// ReserveProduct holds quantity units of the product for the user. The units
+// aren't available to anyone else until the reservation is confirmed,
+// released or expires.
+func (sc *SmartContract) ReserveProduct(ctx contractapi.TransactionContextInterface, reservationId string, productId string, userId string, quantity uint) error {
+	if quantity == 0 {
+		return fmt.Errorf("quantity must be greater than zero")
+	}
+
+	user, err := sc.ReadUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if err := requireActive(user, user.ID); err != nil {
+		return err
+	}
+
+	product, err := sc.ReadProduct(ctx, productId)
+	if err != nil {
+		return err
+	}
+
+	if err := requireActive(product, product.ID); err != nil {
+		return err
+	}
+
+	trader, err := sc.ReadTrader(ctx, product.TraderID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireVerified(trader); err != nil {
+		return err
+	}
+
+	if err := sc.sweepReservations(ctx, product); err != nil {
+		return err
+	}
+
+	if product.Quantity < quantity {
+		return fmt.Errorf("only %d units of product %s are available", product.Quantity, productId)
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	reservation := models.Reservation{
+		ID:        models.ToReservationID(reservationId),
+		ProductID: productId,
+		UserID:    userId,
+		Quantity:  quantity,
+		ExpiresAt: now.Add(reservationTimeout).Format(time.RFC3339),
+		Status:    models.ReservationActive,
+	}
+
+	if err := createModel(ctx, reservation); err != nil {
+		return err
+	}
+
+	product.Quantity -= quantity
+	product.Reserved += quantity
+	product.Reservations = append(product.Reservations, reservationId)
+
+	return sc.UpdateProduct(ctx, productId, product)
+}
+
+// ConfirmReservation sells the reserved units to the user, who pays in the
+// product's currency.
+func (sc *SmartContract) ConfirmReservation(ctx contractapi.TransactionContextInterface, reservationId string) error {
+	reservation, product, err := sc.readActiveReservation(ctx, reservationId)
+	if err != nil {
+		return err
+	}
+
+	reservation.Status = models.ReservationConfirmed
+	removeReservation(product, reservationId, reservation.Quantity)
+
+	if err := updateModel(ctx, reservation.ID, reservation); err != nil {
+		return err
+	}
+
+	return sc.sellProduct(ctx, product, reservation.UserID, reservation.Quantity, "")
+}
+
+// ReleaseReservation returns the reserved units to the available stock.
+func (sc *SmartContract) ReleaseReservation(ctx contractapi.TransactionContextInterface, reservationId string) error {
+	reservation, product, err := sc.readActiveReservation(ctx, reservationId)
+	if err != nil {
+		return err
+	}
+
+	reservation.Status = models.ReservationReleased
+	removeReservation(product, reservationId, reservation.Quantity)
+	product.Quantity += reservation.Quantity
+
+	if err := updateModel(ctx, reservation.ID, reservation); err != nil {
+		return err
+	}
+
+	return sc.UpdateProduct(ctx, reservation.ProductID, product)
+}
+
+// readActiveReservation reads the reservation and its product after the
+// expired reservations of the product were swept.
+func (sc *SmartContract) readActiveReservation(ctx contractapi.TransactionContextInterface, reservationId string) (*models.Reservation, *models.Product, error) {
+	reservation, err := sc.ReadReservation(ctx, reservationId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if reservation.Status != models.ReservationActive {
+		return nil, nil, fmt.Errorf("reservation %s is %s", reservationId, reservation.Status)
+	}
+
+	product, err := sc.ReadProduct(ctx, reservation.ProductID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := sc.sweepReservations(ctx, product); err != nil {
+		return nil, nil, err
+	}
+
+	if !slices.Contains(product.Reservations, reservationId) {
+		// a failed transaction writes nothing, so the expired reservation is
+		// swept by the next transaction that touches the product
+		return nil, nil, fmt.Errorf("reservation %s expired", reservationId)
+	}
+
+	return reservation, product, nil
+}
+
+// sweepReservations expires the product's reservations that are past their
+// expiry at the transaction time and returns their units to the available
+// stock. Expired reservations are only stored; the product is stored by the
+// caller.
+func (sc *SmartContract) sweepReservations(ctx contractapi.TransactionContextInterface, product *models.Product) error {
+	if len(product.Reservations) == 0 {
+		return nil
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, reservationId := range slices.Clone(product.Reservations) {
+		reservation, err := sc.ReadReservation(ctx, reservationId)
+		if err != nil {
+			return err
+		}
+
+		expiresAt, err := time.Parse(time.RFC3339, reservation.ExpiresAt)
+		if err != nil {
+			return fmt.Errorf("invalid reservation expiry: %v", err)
+		}
+
+		if now.Before(expiresAt) {
+			continue
+		}
+
+		reservation.Status = models.ReservationExpired
+		removeReservation(product, reservationId, reservation.Quantity)
+		product.Quantity += reservation.Quantity
+
+		if err := updateModel(ctx, reservation.ID, reservation); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func removeReservation(product *models.Product, reservationId string, quantity uint) {
+	product.Reservations = slices.DeleteFunc(product.Reservations, func(id string) bool {
+		return id == reservationId
+	})
+	product.Reserved -= quantity
+}
@@ -0,0 +1,73 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupReservationState(t *testing.T) map[string][]byte {
+	state := map[string][]byte{}
+	putTestModel(t, state, models.User{ID: "USER-u1", Name: "Ana", LastName: "Jovanović", Email: "USER-u1@example.com", Balances: rsdBalances(100), ReceiptsID: []string{}})
+	putTestModel(t, state, models.User{ID: "USER-u2", Name: "Ana", LastName: "Jovanović", Email: "USER-u2@example.com", Balances: rsdBalances(100), ReceiptsID: []string{}})
+	putTestModel(t, state, models.Trader{ID: "TRADER-tt1", PIB: "100000008", VerificationStatus: models.VerificationVerified, Currency: "RSD", Products: []string{"m1"}, Receipts: []string{}})
+	putTestModel(t, state, models.Product{ID: "PRODUCT-m1", Name: "Milk", Price: rsd(3), Quantity: 3, Reservations: []string{}, TraderID: "tt1"})
+
+	return state
+}
+
+func TestReserveAndConfirm(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := setupReservationState(t)
+	ctx, _ := newStatefulContext(state, &now)
+
+	require.Error(t, sc.ReserveProduct(ctx, "r1", "m1", "u1", 4), "more than the stock")
+	require.NoError(t, sc.ReserveProduct(ctx, "r1", "m1", "u1", 2))
+	require.Error(t, sc.ReserveProduct(ctx, "r2", "m1", "u2", 2), "the last units are reserved")
+
+	product := getTestModel[models.Product](t, state, "PRODUCT-m1")
+	require.Equal(t, uint(1), product.Quantity)
+	require.Equal(t, uint(2), product.Reserved)
+
+	require.NoError(t, sc.BuyProduct(ctx, "m1", "u2"))
+	require.Error(t, sc.BuyProduct(ctx, "m1", "u2"), "only reserved units are left")
+
+	require.NoError(t, sc.ConfirmReservation(ctx, "r1"))
+	require.Error(t, sc.ConfirmReservation(ctx, "r1"), "confirmed twice")
+
+	require.Equal(t, models.ReservationConfirmed, getTestModel[models.Reservation](t, state, "RESERVATION-r1").Status)
+	require.Equal(t, rsd(94), getTestModel[models.User](t, state, "USER-u1").Balances.Get("RSD"))
+	require.Equal(t, rsd(9), getTestTrader(t, ctx, state, "TRADER-tt1").AccountBalance)
+
+	require.True(t, getTestModel[models.Product](t, state, "PRODUCT-m1").IsArchived(), "sold out product is archived")
+}
+
+func TestReleaseAndExpireReservation(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := setupReservationState(t)
+	ctx, _ := newStatefulContext(state, &now)
+
+	require.NoError(t, sc.ReserveProduct(ctx, "r1", "m1", "u1", 2))
+	require.NoError(t, sc.ReleaseReservation(ctx, "r1"))
+	require.Error(t, sc.ReleaseReservation(ctx, "r1"), "released twice")
+	require.Equal(t, uint(3), getTestModel[models.Product](t, state, "PRODUCT-m1").Quantity)
+
+	require.NoError(t, sc.ReserveProduct(ctx, "r2", "m1", "u1", 3))
+	require.Error(t, sc.ReserveProduct(ctx, "r3", "m1", "u2", 1))
+
+	now = now.Add(reservationTimeout)
+	require.Error(t, sc.ConfirmReservation(ctx, "r2"), "confirmed after it expired")
+
+	// the expired reservation is swept when the next one is made
+	require.NoError(t, sc.ReserveProduct(ctx, "r3", "m1", "u2", 1))
+	require.Equal(t, models.ReservationExpired, getTestModel[models.Reservation](t, state, "RESERVATION-r2").Status)
+
+	product := getTestModel[models.Product](t, state, "PRODUCT-m1")
+	require.Equal(t, uint(2), product.Quantity)
+	require.Equal(t, uint(1), product.Reserved)
+	require.Equal(t, []string{"r3"}, product.Reservations)
+}
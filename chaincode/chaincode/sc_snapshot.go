@@ -0,0 +1,174 @@
+package chaincode
+
+import (
+	"bytes"
+	"chaincode/models"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ExportState returns a page of the stored documents as NDJSON, upgraded to
+// their current schema version. The export goes through the entity types one
+// after the other; pass the returned bookmark until the page is done.
+func (sc *SmartContract) ExportState(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*models.ExportPage, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be positive")
+	}
+
+	entityType, rangeBookmark, err := models.ParseExportBookmark(bookmark)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByRangeWithPagination(entityType+"-", entityType+".", pageSize, rangeBookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var data bytes.Buffer
+	page := &models.ExportPage{FormatVersion: models.SNAPSHOT_FORMAT_VERSION, EntityType: entityType}
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		document, _, err := models.UpgradeDocument(queryResponse.Key, queryResponse.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		if entityType == models.TRADER_TYPE {
+			if document, err = foldTraderDocument(ctx, queryResponse.Key, document); err != nil {
+				return nil, err
+			}
+		}
+
+		line, err := json.Marshal(models.SnapshotEntry{Key: queryResponse.Key, Document: document})
+		if err != nil {
+			return nil, err
+		}
+
+		data.Write(line)
+		data.WriteByte('\n')
+		page.Count++
+	}
+
+	page.Data = data.String()
+	page.Checksum = models.Checksum(page.Data)
+
+	next := ""
+	if metadata != nil {
+		next = metadata.Bookmark
+	}
+
+	if next != "" && page.Count == int(pageSize) {
+		page.Bookmark = models.ExportBookmark(entityType, next)
+		return page, nil
+	}
+
+	// the type is done, the export continues with the next one
+	index := slices.Index(models.VersionedTypes, entityType)
+	if index == len(models.VersionedTypes)-1 {
+		page.Done = true
+	} else {
+		page.Bookmark = models.ExportBookmark(models.VersionedTypes[index+1], "")
+	}
+
+	return page, nil
+}
+
+// ImportState stores the documents of export data whose hex SHA-256 is
+// checksum. Documents exported with an older schema version are upgraded.
+// Unless overwrite is set, the import fails when a key is already stored.
+func (sc *SmartContract) ImportState(ctx contractapi.TransactionContextInterface, data string, checksum string, overwrite bool) (*models.ImportResult, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if models.Checksum(data) != checksum {
+		return nil, fmt.Errorf("the data doesn't match its checksum, it was changed or truncated")
+	}
+
+	entries, err := models.ParseSnapshotLines(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkBatchSize(len(entries)); err != nil {
+		return nil, err
+	}
+
+	// everything is checked before the first write
+	result := &models.ImportResult{Checksum: checksum}
+	documents := make([][]byte, len(entries))
+	keys := make([]string, 0, len(entries))
+
+	for i, entry := range entries {
+		if slices.Contains(keys, entry.Key) {
+			return nil, fmt.Errorf("%s is in the data more than once", entry.Key)
+		}
+		keys = append(keys, entry.Key)
+
+		document, _, err := models.UpgradeDocument(entry.Key, entry.Document)
+		if err != nil {
+			return nil, err
+		}
+		documents[i] = document
+
+		exists, err := modelExists(ctx, entry.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		if exists && !overwrite {
+			return nil, fmt.Errorf("%s is already stored", entry.Key)
+		}
+
+		if exists {
+			result.Overwritten++
+		}
+	}
+
+	for i, entry := range entries {
+		previous, err := ctx.GetStub().GetState(entry.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := reindex(ctx, entry.Key, previous, documents[i]); err != nil {
+			return nil, err
+		}
+
+		if err := ctx.GetStub().PutState(entry.Key, documents[i]); err != nil {
+			return nil, fmt.Errorf("failed to import %s: %v", entry.Key, err)
+		}
+		result.Imported++
+	}
+
+	return result, nil
+}
+
+// foldTraderDocument adds the trader's pending deltas to its exported
+// document. The deltas have composite keys, which the export doesn't cover.
+func foldTraderDocument(ctx contractapi.TransactionContextInterface, key string, document []byte) ([]byte, error) {
+	var trader models.Trader
+	if err := json.Unmarshal(document, &trader); err != nil {
+		return nil, fmt.Errorf("failed to deserialize %s: %v", key, err)
+	}
+
+	if err := applyTraderDeltas(ctx, &trader); err != nil {
+		return nil, err
+	}
+
+	return models.EncodeModel(key, trader)
+}
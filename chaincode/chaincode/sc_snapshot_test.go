@@ -0,0 +1,104 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportAndImportState(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	source := map[string][]byte{
+		// stored before documents were versioned
+		"PRODUCT-b1": []byte(`{"id":"PRODUCT-b1","name":"Bread","price":{"amount":300,"currency":"RSD"},"quantity":10,"trader_id":"tt1"}`),
+	}
+	putTestModel(t, source, models.Product{ID: "PRODUCT-m1", Name: "Milk", Price: rsd(3), Quantity: 1, TraderID: "tt1"})
+	putTestModel(t, source, models.Product{ID: "PRODUCT-t1", Name: "Tomato", Price: rsd(1), Quantity: 1, TraderID: "tt1"})
+	putTestModel(t, source, models.Trader{ID: "TRADER-tt1", PIB: "100000008", VerificationStatus: models.VerificationVerified, Products: []string{"b1", "m1", "t1"}, Receipts: []string{}})
+	putTestModel(t, source, models.User{ID: "USER-u1", Name: "Ana", LastName: "Jovanović", Email: "USER-u1@example.com", Balances: rsdBalances(1)})
+
+	ctx, stub := newStatefulContext(source, &now)
+	stub.GetStateByRangeWithPaginationStub = paginateRange(source)
+
+	setCaller(ctx, "Org1MSP", "client")
+	_, err := sc.ExportState(ctx, 2, "")
+	require.Error(t, err)
+
+	setCaller(ctx, "Org1MSP", "admin")
+	pages := make([]*models.ExportPage, 0)
+	bookmark := ""
+	for {
+		page, err := sc.ExportState(ctx, 2, bookmark)
+		require.NoError(t, err)
+		require.Equal(t, models.Checksum(page.Data), page.Checksum)
+		pages = append(pages, page)
+
+		if page.Done {
+			break
+		}
+		bookmark = page.Bookmark
+	}
+
+	exported := 0
+	for _, page := range pages {
+		exported += page.Count
+	}
+	require.Equal(t, 5, exported)
+	require.Equal(t, models.PRODUCT_TYPE, pages[0].EntityType)
+	require.Equal(t, 2, pages[0].Count)
+	require.Contains(t, pages[0].Data, `"schema_version":2`, "legacy documents are exported stamped")
+
+	target := map[string][]byte{}
+	ctx, _ = newStatefulContext(target, &now)
+
+	setCaller(ctx, "Org1MSP", "admin")
+	_, err = sc.ImportState(ctx, pages[0].Data, models.Checksum("tampered"), false)
+	require.ErrorContains(t, err, "checksum")
+
+	for _, page := range pages {
+		if page.Count == 0 {
+			continue
+		}
+		result, err := sc.ImportState(ctx, page.Data, page.Checksum, false)
+		require.NoError(t, err)
+		require.Equal(t, page.Count, result.Imported)
+	}
+
+	// the import builds the index entries of the documents
+	documents := 0
+	for key := range target {
+		if !strings.HasPrefix(key, "\x00") {
+			documents++
+		}
+	}
+	require.Equal(t, 5, documents)
+	registered, err := traderWithPIB(ctx, "100000008")
+	require.NoError(t, err)
+	require.Equal(t, "TRADER-tt1", registered)
+
+	for key := range source {
+		if strings.HasPrefix(key, "\x00") {
+			continue
+		}
+
+		var want, got map[string]any
+		require.NoError(t, models.DecodeModel(key, source[key], &want))
+		require.NoError(t, models.DecodeModel(key, target[key], &got))
+		require.Equal(t, want, got, key)
+	}
+
+	_, err = sc.ImportState(ctx, pages[0].Data, pages[0].Checksum, false)
+	require.ErrorContains(t, err, "already stored")
+
+	result, err := sc.ImportState(ctx, pages[0].Data, pages[0].Checksum, true)
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Overwritten)
+
+	moved := strings.Replace(pages[0].Data, `"key":"PRODUCT-b1"`, `"key":"PRODUCT-x1"`, 1)
+	_, err = sc.ImportState(ctx, moved, models.Checksum(moved), true)
+	require.ErrorContains(t, err, "stored under")
+}
@@ -0,0 +1,242 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// recordMovement stores a change of the user's spendable balance that was
+// already applied to the user, and journals it against the contra lines,
+// which must balance it. The caller stores the user, whose movement counter
+// is advanced.
+func recordMovement(ctx contractapi.TransactionContextInterface, user *models.User, kind models.MovementKind, direction models.MovementDirection, amount models.Money, reference string, contra ...*models.JournalLine) error {
+	if amount.IsZero() {
+		return nil
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	userId := models.TrimKeyPrefix(models.USER_TYPE, user.ID)
+	movement := models.Movement{
+		ID:        models.ToMovementID(userId, user.Movements),
+		UserID:    userId,
+		Kind:      kind,
+		Direction: direction,
+		Amount:    amount,
+		Balance:   user.Balances.Get(amount.Currency),
+		Reference: reference,
+		Timestamp: now.Format(time.RFC3339),
+		TxID:      ctx.GetStub().GetTxID(),
+	}
+	user.Movements++
+
+	entry := models.JournalEntry{
+		ID:        models.ToJournalID(movement.ID),
+		Kind:      kind,
+		Reference: reference,
+		Lines:     append([]*models.JournalLine{{Account: models.UserAccount(userId), Direction: direction, Amount: amount}}, contra...),
+		Timestamp: movement.Timestamp,
+		TxID:      movement.TxID,
+	}
+
+	if err := postJournalEntry(ctx, entry); err != nil {
+		return err
+	}
+
+	return createModel(ctx, movement)
+}
+
+// nextMovementSequence is the sequence the user's first movement takes. The
+// journal keeps the entries of a purged user with the same id, so a user
+// created again continues after them.
+func nextMovementSequence(ctx contractapi.TransactionContextInterface, userId string) (uint64, error) {
+	startKey, endKey := models.MovementKeyRange(userId)
+	prefix := models.ToJournalID(startKey)
+
+	resultsIterator, err := ctx.GetStub().GetStateByRange(prefix, models.ToJournalID(endKey))
+	if err != nil {
+		return 0, err
+	}
+	defer resultsIterator.Close()
+
+	next := uint64(0)
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return 0, err
+		}
+
+		// the range also covers the users whose id extends this one
+		sequence, err := strconv.ParseUint(strings.TrimPrefix(queryResponse.Key, prefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		next = max(next, sequence+1)
+	}
+
+	return next, nil
+}
+
+func creditUser(ctx contractapi.TransactionContextInterface, user *models.User, amount models.Money, kind models.MovementKind, reference string, contra ...*models.JournalLine) error {
+	if err := user.Credit(amount); err != nil {
+		return err
+	}
+
+	return recordMovement(ctx, user, kind, models.Credit, amount, reference, contra...)
+}
+
+func debitUser(ctx contractapi.TransactionContextInterface, user *models.User, amount models.Money, kind models.MovementKind, reference string, contra ...*models.JournalLine) error {
+	if err := user.CheckDebit(); err != nil {
+		return err
+	}
+
+	if err := user.Debit(amount); err != nil {
+		return err
+	}
+
+	return recordMovement(ctx, user, kind, models.Debit, amount, reference, contra...)
+}
+
+// depositUser credits the user with money from outside the channel.
+func depositUser(ctx contractapi.TransactionContextInterface, user *models.User, amount models.Money) error {
+	return creditUser(ctx, user, amount, models.MovementDeposit, "", depositLine(amount))
+}
+
+// withdrawUser pays out the user's balances to outside the channel, which
+// closes the user's account in the journal.
+func withdrawUser(ctx contractapi.TransactionContextInterface, user *models.User) error {
+	for _, currency := range user.Balances.Currencies() {
+		amount := user.Balances[currency]
+		if err := user.Debit(amount); err != nil {
+			return err
+		}
+
+		if err := recordMovement(ctx, user, models.MovementWithdrawal, models.Debit, amount, "", withdrawalLine(amount)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DepositFunds adds money to the user's balance.
+func (sc *SmartContract) DepositFunds(ctx contractapi.TransactionContextInterface, userId string, amount models.Money) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	if err := amount.Validate(); err != nil {
+		return err
+	}
+
+	if amount.IsZero() {
+		return fmt.Errorf("amount must be greater than zero")
+	}
+
+	user, err := sc.ReadUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if err := depositUser(ctx, user, amount); err != nil {
+		return err
+	}
+
+	return sc.UpdateUser(ctx, userId, user)
+}
+
+// GetUserStatement lists the movements of the user's balance in the currency
+// between the from and to dates, given as YYYY-MM-DD.
+func (sc *SmartContract) GetUserStatement(ctx contractapi.TransactionContextInterface, userId string, currency string, from string, to string) (*models.Statement, error) {
+	if err := models.NewMoney(0, currency).Validate(); err != nil {
+		return nil, err
+	}
+
+	fromDate, toDate, err := parsePeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := sc.ReadUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &models.Statement{
+		UserID:   userId,
+		Currency: currency,
+		From:     from,
+		To:       to,
+		Entries:  make([]*models.Movement, 0),
+	}
+
+	// the opening balance comes from the nearest movement around the period;
+	// without any, the balance never changed since it was first recorded
+	opening := user.Balances.Get(currency)
+	var before, after *models.Movement
+
+	resultsIterator, err := ctx.GetStub().GetStateByRange(models.MovementKeyRange(userId))
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var movement models.Movement
+		if err := models.DecodeModel(queryResponse.Key, queryResponse.Value, &movement); err != nil {
+			return nil, err
+		}
+
+		// the prefix also covers users whose id starts with this one
+		if movement.UserID != userId || movement.Amount.Currency != currency {
+			continue
+		}
+
+		timestamp, err := time.Parse(time.RFC3339, movement.Timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time of movement %s: %v", movement.ID, err)
+		}
+
+		switch {
+		case timestamp.Before(fromDate):
+			before = &movement
+		case inPeriod(timestamp, fromDate, toDate):
+			statement.Entries = append(statement.Entries, &movement)
+		case after == nil:
+			after = &movement
+		}
+	}
+
+	switch {
+	case len(statement.Entries) > 0:
+		opening, err = statement.Entries[0].BalanceBefore()
+	case before != nil:
+		opening = before.Balance
+	case after != nil:
+		opening, err = after.BalanceBefore()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	statement.Opening = opening
+	statement.Closing = opening
+	if len(statement.Entries) > 0 {
+		statement.Closing = statement.Entries[len(statement.Entries)-1].Balance
+	}
+
+	return statement, nil
+}
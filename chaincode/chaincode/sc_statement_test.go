@@ -0,0 +1,76 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUserStatement(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	putTestModel(t, state, models.Trader{ID: "TRADER-tt1", PIB: "100000008", VerificationStatus: models.VerificationVerified, Currency: "RSD", Products: []string{"m1"}, Receipts: []string{}})
+	putTestModel(t, state, models.Product{ID: "PRODUCT-m1", Name: "Milk", Price: rsd(3), Quantity: 10, TraderID: "tt1"})
+
+	ctx, _ := newStatefulContext(state, &now)
+	setCaller(ctx, "Org1MSP", "admin")
+
+	require.NoError(t, sc.CreateUser(ctx, models.User{ID: "u1", Name: "Ana", LastName: "Jovanović", Email: "u1@example.com", Balances: rsdBalances(20)}))
+	require.NoError(t, sc.CreateUser(ctx, models.User{ID: "u1-2", Name: "Ana", LastName: "Jovanović", Email: "u1-2@example.com", Balances: rsdBalances(50)}))
+
+	now = now.AddDate(0, 0, 1)
+	require.NoError(t, sc.BuyProduct(ctx, "m1", "u1"))
+	require.NoError(t, sc.DepositFunds(ctx, "u1", rsd(10)))
+	require.NoError(t, sc.DepositFunds(ctx, "u1", eur(1)))
+
+	now = now.AddDate(0, 0, 1)
+	receipt := getTestModel[models.User](t, state, "USER-u1").ReceiptsID[0]
+	require.NoError(t, sc.RefundReceipt(ctx, receipt))
+	require.NoError(t, sc.BuyProduct(ctx, "m1", "u1"))
+
+	statement, err := sc.GetUserStatement(ctx, "u1", "RSD", "2026-05-02", "2026-05-02")
+	require.NoError(t, err)
+	require.Equal(t, rsd(20), statement.Opening)
+	require.Equal(t, rsd(27), statement.Closing)
+	require.Len(t, statement.Entries, 2)
+	require.Equal(t, models.MovementPurchase, statement.Entries[0].Kind)
+	require.Equal(t, models.Debit, statement.Entries[0].Direction)
+	require.Equal(t, rsd(17), statement.Entries[0].Balance)
+	require.Equal(t, models.MovementDeposit, statement.Entries[1].Kind)
+
+	statement, err = sc.GetUserStatement(ctx, "u1", "RSD", "2026-05-01", "2026-05-03")
+	require.NoError(t, err)
+	require.Equal(t, rsd(0), statement.Opening)
+	require.Equal(t, rsd(27), statement.Closing)
+	require.Len(t, statement.Entries, 5)
+	require.Equal(t, models.MovementRefund, statement.Entries[3].Kind)
+
+	// periods without movements keep the balance of the nearest movement
+	statement, err = sc.GetUserStatement(ctx, "u1", "RSD", "2026-04-01", "2026-04-30")
+	require.NoError(t, err)
+	require.Equal(t, rsd(0), statement.Opening)
+	require.Empty(t, statement.Entries)
+
+	statement, err = sc.GetUserStatement(ctx, "u1", "RSD", "2026-06-01", "2026-06-30")
+	require.NoError(t, err)
+	require.Equal(t, rsd(27), statement.Opening)
+	require.Equal(t, rsd(27), statement.Closing)
+
+	statement, err = sc.GetUserStatement(ctx, "u1", "EUR", "2026-05-01", "2026-05-03")
+	require.NoError(t, err)
+	require.Equal(t, eur(1), statement.Closing)
+	require.Len(t, statement.Entries, 1)
+
+	// archived users keep their statement; purging removes it
+	require.NoError(t, sc.DeleteUser(ctx, "u1"))
+	require.NoError(t, sc.DeleteUser(ctx, "u1-2"))
+	require.NoError(t, sc.PurgeRecord(ctx, models.USER_TYPE, "u1-2"))
+	_, purged := state[models.ToMovementID("u1-2", 0)]
+	require.False(t, purged)
+	_, archived := state[models.ToMovementID("u1", 0)]
+	require.True(t, archived)
+}
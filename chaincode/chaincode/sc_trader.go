@@ -2,7 +2,6 @@ package chaincode
 
 import (
 	"chaincode/models"
-	"encoding/json"
 	"fmt"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
@@ -12,19 +11,163 @@ func (sc *SmartContract) ReadTrader(ctx contractapi.TransactionContextInterface,
 	return readModel[models.Trader](ctx, models.ToTraderID(id))
 }
 
+// UpdateTrader stores the trader. Its verification status can only be
+// changed with SetTraderVerification, its owner and endorsers with
+// TransferTraderOwnership and SetTraderEndorsers. Its balance and receipts
+// change with the deltas of its sales and refunds, its status with
+// DeleteTrader and RestoreRecord. The currency and the opening balance the
+// journal reconciles against are fixed when the trader is created.
 func (sc *SmartContract) UpdateTrader(ctx contractapi.TransactionContextInterface, id string, model *models.Trader) error {
+	stored, err := sc.ReadTrader(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if model.TraderType != stored.TraderType && model.TraderType != "" {
+		if _, err := sc.ReadCategory(ctx, string(model.TraderType)); err != nil {
+			return fmt.Errorf("unknown trader category %s: %v", model.TraderType, err)
+		}
+	}
+
+	if model.PIB != stored.PIB {
+		if err := checkPIB(ctx, model.PIB, stored.ID); err != nil {
+			return err
+		}
+	}
+
+	model.ID = stored.ID
+	model.Currency = stored.Currency
+	model.OpeningBalance = stored.OpeningBalance
+	model.VerificationStatus = stored.VerificationStatus
+	model.AccountBalance = stored.AccountBalance
+	model.Receipts = stored.Receipts
+	model.OwnerMSP = stored.OwnerMSP
+	model.Endorsers = stored.Endorsers
+	model.Lifecycle = stored.Lifecycle
 	return updateModel(ctx, models.ToTraderID(id), model)
 }
+
+// DeleteTrader archives the trader. By default its products are archived
+// with it.
 func (sc *SmartContract) DeleteTrader(ctx contractapi.TransactionContextInterface, id string) error {
-	return deleteModel(ctx, models.ToTraderID(id))
+	return sc.deleteRecord(ctx, models.TRADER_TYPE, id)
 }
 
+// CreateTrader creates a trader owned by the caller's organization, admins
+// can name another owner. Changes to the trader and its products need the
+// endorsement of the owner's peers.
 func (sc *SmartContract) CreateTrader(ctx contractapi.TransactionContextInterface, trader models.Trader) error {
+	if err := models.Validate(trader); err != nil {
+		return err
+	}
+
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return err
+	}
+
+	if trader.OwnerMSP == "" {
+		trader.OwnerMSP = caller.MSPID
+	} else if trader.OwnerMSP != caller.MSPID && !caller.Admin {
+		return fmt.Errorf("only admins can create traders owned by another organization")
+	}
+
+	if trader.Currency == "" {
+		trader.Currency = models.DefaultCurrency
+	}
+
+	if err := models.NewMoney(0, trader.Currency).Validate(); err != nil {
+		return err
+	}
+
+	if err := trader.AccountBalance.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := trader.AccountBalance.Add(models.NewMoney(0, trader.Currency)); err != nil {
+		return fmt.Errorf("the account balance must be in the trader's currency: %v", err)
+	}
+
+	if trader.TraderType != "" {
+		if _, err := sc.ReadCategory(ctx, string(trader.TraderType)); err != nil {
+			return fmt.Errorf("unknown trader category %s: %v", trader.TraderType, err)
+		}
+	}
+
 	trader.ID = models.ToTraderID(trader.ID)
+	if err := checkPIB(ctx, trader.PIB, trader.ID); err != nil {
+		return err
+	}
+
+	trader.OpeningBalance = trader.AccountBalance
+	trader.VerificationStatus = models.VerificationPending
 	trader.Receipts = make([]string, 0)
 	trader.Products = make([]string, 0)
+	trader.Endorsers = nil
+
+	if err := createModel(ctx, trader); err != nil {
+		return err
+	}
 
-	return createModel(ctx, trader)
+	if err := journalOpeningBalance(ctx, &trader); err != nil {
+		return err
+	}
+
+	return setEndorsementPolicy(ctx, trader.ID, trader.EndorsingOrgs())
+}
+
+// checkPIB validates the PIB and makes sure no other trader, archived ones
+// included, is registered with it.
+func checkPIB(ctx contractapi.TransactionContextInterface, pib string, traderKey string) error {
+	if err := models.ValidatePIB(pib); err != nil {
+		return err
+	}
+
+	registered, err := traderWithPIB(ctx, pib)
+	if err != nil {
+		return err
+	}
+
+	if registered != "" && registered != traderKey {
+		return fmt.Errorf("PIB %s is already registered to %s", pib, registered)
+	}
+
+	return nil
+}
+
+// requireVerified rejects sales by traders that aren't verified or were
+// suspended.
+func requireVerified(trader *models.Trader) error {
+	if !trader.IsVerified() {
+		return fmt.Errorf("trader %s can't sell, its verification status is %s", trader.ID, trader.VerificationStatus)
+	}
+
+	return nil
+}
+
+// SetTraderVerification records the outcome of the admins' check of the
+// trader's registration. Suspending a trader stops its sales.
+func (sc *SmartContract) SetTraderVerification(ctx contractapi.TransactionContextInterface, id string, status string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	verificationStatus, err := models.ParseVerificationStatus(status)
+	if err != nil {
+		return err
+	}
+
+	trader, err := sc.ReadTrader(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := requireActive(trader, trader.ID); err != nil {
+		return err
+	}
+
+	trader.VerificationStatus = verificationStatus
+	return updateModel(ctx, trader.ID, trader)
 }
 
 func (sc *SmartContract) GetAllTraders(ctx contractapi.TransactionContextInterface) ([]*models.Trader, error) {
@@ -45,10 +188,17 @@ func (sc *SmartContract) GetAllTraders(ctx contractapi.TransactionContextInterfa
 		if queryResponse == nil {
 			return nil, fmt.Errorf("no next")
 		}
-		err = json.Unmarshal(queryResponse.Value, &asset)
+		err = models.DecodeModel(queryResponse.Key, queryResponse.Value, &asset)
 		if err != nil {
 			return nil, err
 		}
+		if asset.IsArchived() {
+			continue
+		}
+
+		if err := applyTraderDeltas(ctx, &asset); err != nil {
+			return nil, err
+		}
 		assets = append(assets, &asset)
 	}
 
@@ -0,0 +1,52 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraderOnboarding(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+
+	ctx, _ := newStatefulContext(state, &now)
+	setCaller(ctx, "Org1MSP", "client")
+
+	require.ErrorContains(t, sc.CreateTrader(ctx, models.Trader{ID: "tt1", PIB: "pib1"}), "9 digits")
+	require.ErrorContains(t, sc.CreateTrader(ctx, models.Trader{ID: "tt1", PIB: "100000009"}), "check digit")
+
+	// the status the trader asks for is ignored
+	require.NoError(t, sc.CreateTrader(ctx, models.Trader{ID: "tt1", PIB: "100000008", Currency: "RSD", VerificationStatus: models.VerificationVerified}))
+	require.Equal(t, models.VerificationPending, getTestModel[models.Trader](t, state, "TRADER-tt1").VerificationStatus)
+	require.ErrorContains(t, sc.CreateTrader(ctx, models.Trader{ID: "tt2", PIB: "100000008"}), "already registered")
+
+	require.NoError(t, sc.CreateProduct(ctx, models.Product{ID: "m1", Name: "Milk", Price: rsd(3), Quantity: 5, TraderID: "tt1"}))
+	require.NoError(t, sc.CreateUser(ctx, models.User{ID: "u1", Name: "Ana", LastName: "Jovanović", Email: "u1@example.com", Balances: rsdBalances(20)}))
+	require.ErrorContains(t, sc.BuyProduct(ctx, "m1", "u1"), "PENDING")
+	require.ErrorContains(t, sc.ReserveProduct(ctx, "r1", "m1", "u1", 1), "PENDING")
+
+	require.Error(t, sc.SetTraderVerification(ctx, "tt1", string(models.VerificationVerified)), "only admins verify traders")
+
+	setCaller(ctx, "Org1MSP", "admin")
+	require.ErrorContains(t, sc.SetTraderVerification(ctx, "tt1", "APPROVED"), "unknown verification status")
+	require.NoError(t, sc.SetTraderVerification(ctx, "tt1", string(models.VerificationVerified)))
+	require.NoError(t, sc.BuyProduct(ctx, "m1", "u1"))
+
+	// updates keep the status and the PIB stays unique
+	trader := getTestModel[models.Trader](t, state, "TRADER-tt1")
+	trader.VerificationStatus = models.VerificationSuspended
+	trader.PIB = "100000016"
+	require.NoError(t, sc.UpdateTrader(ctx, "tt1", &trader))
+	require.Equal(t, models.VerificationVerified, getTestModel[models.Trader](t, state, "TRADER-tt1").VerificationStatus)
+
+	require.NoError(t, sc.CreateTrader(ctx, models.Trader{ID: "tt2", PIB: "100000008"}))
+	trader.PIB = "100000008"
+	require.ErrorContains(t, sc.UpdateTrader(ctx, "tt1", &trader), "already registered")
+
+	require.NoError(t, sc.SetTraderVerification(ctx, "tt1", string(models.VerificationSuspended)))
+	require.ErrorContains(t, sc.BuyProduct(ctx, "m1", "u1"), "SUSPENDED")
+}
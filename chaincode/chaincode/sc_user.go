@@ -2,7 +2,6 @@ package chaincode
 
 import (
 	"chaincode/models"
-	"encoding/json"
 	"fmt"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
@@ -16,13 +15,73 @@ func (sc *SmartContract) UpdateUser(ctx contractapi.TransactionContextInterface,
 	return updateModel(ctx, models.ToUserID(id), model)
 }
 
+// DeleteUser archives the user, who can no longer buy or bid. The user's
+// balances, movements and receipts are kept. It's refused while the user has
+// active reservations, locked bids or locked funds.
 func (sc *SmartContract) DeleteUser(ctx contractapi.TransactionContextInterface, id string) error {
-	return deleteModel(ctx, models.ToUserID(id))
+	return sc.deleteRecord(ctx, models.USER_TYPE, id)
+}
+
+// deleteMovements removes the user's movements, so a user created later with
+// the same id starts with an empty statement.
+func deleteMovements(ctx contractapi.TransactionContextInterface, id string) error {
+	resultsIterator, err := ctx.GetStub().GetStateByRange(models.MovementKeyRange(id))
+	if err != nil {
+		return err
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return err
+		}
+
+		var movement models.Movement
+		if err := models.DecodeModel(queryResponse.Key, queryResponse.Value, &movement); err != nil {
+			return err
+		}
+
+		if movement.UserID != id {
+			continue
+		}
+
+		if err := ctx.GetStub().DelState(queryResponse.Key); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (sc *SmartContract) CreateUser(ctx contractapi.TransactionContextInterface, user models.User) error {
+	if err := models.Validate(user); err != nil {
+		return err
+	}
+
+	if err := user.Balances.Validate(); err != nil {
+		return err
+	}
+
+	sequence, err := nextMovementSequence(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
 	user.ID = models.ToUserID(user.ID)
 	user.ReceiptsID = make([]string, 0)
+	user.LockedBalances = models.Balances{}
+	user.Movements = sequence
+	user.Limits = models.SpendingLimits{}
+	user.Spending = models.Spending{}
+	user.Freeze = nil
+
+	// the initial balances are recorded as deposits
+	for _, currency := range user.Balances.Currencies() {
+		if err := recordMovement(ctx, &user, models.MovementDeposit, models.Credit, user.Balances[currency], "", depositLine(user.Balances[currency])); err != nil {
+			return err
+		}
+	}
 
 	return createModel(ctx, user)
 }
@@ -45,10 +104,13 @@ func (sc *SmartContract) GetAllUsers(ctx contractapi.TransactionContextInterface
 		if queryResponse == nil {
 			return nil, fmt.Errorf("no next")
 		}
-		err = json.Unmarshal(queryResponse.Value, &asset)
+		err = models.DecodeModel(queryResponse.Key, queryResponse.Value, &asset)
 		if err != nil {
 			return nil, err
 		}
+		if asset.IsArchived() {
+			continue
+		}
 		assets = append(assets, &asset)
 	}
 
@@ -71,10 +133,13 @@ func (s *SmartContract) QueryUsers(ctx contractapi.TransactionContextInterface,
 		}
 
 		var person models.User
-		err = json.Unmarshal(queryResponse.Value, &person)
+		err = models.DecodeModel(queryResponse.Key, queryResponse.Value, &person)
 		if err != nil {
 			return nil, err
 		}
+		if person.IsArchived() {
+			continue
+		}
 		users = append(users, &person)
 	}
 
@@ -99,14 +164,19 @@ func (s *SmartContract) SearchUsersByLastNameAndEmail(ctx contractapi.Transactio
 	return s.QueryUsers(ctx, queryString)
 }
 
-func (sc *SmartContract) GetUsersGTEBalance(ctx contractapi.TransactionContextInterface, balance uint) ([]*models.User, error) {
+func (sc *SmartContract) GetUsersGTEBalance(ctx contractapi.TransactionContextInterface, balance models.Money) ([]*models.User, error) {
+	// the currency is part of the field path, so it must be a valid code
+	if err := balance.Validate(); err != nil || balance.Currency == "" {
+		return nil, fmt.Errorf("invalid balance %v", balance)
+	}
+
 	queryString := `
 	{
 	"selector": {
 		"$and": [
 		{
-			"account_balance": {
-			"$gte": ` + fmt.Sprintf("%d", balance) + `
+			"balances.` + balance.Currency + `.amount": {
+			"$gte": ` + fmt.Sprintf("%d", balance.Amount) + `
 			}
 		},
 		{
@@ -135,10 +205,13 @@ func (sc *SmartContract) GetUsersGTEBalance(ctx contractapi.TransactionContextIn
 		if queryResponse == nil {
 			return nil, fmt.Errorf("no next")
 		}
-		err = json.Unmarshal(queryResponse.Value, &asset)
+		err = models.DecodeModel(queryResponse.Key, queryResponse.Value, &asset)
 		if err != nil {
 			return nil, err
 		}
+		if asset.IsArchived() {
+			continue
+		}
 		assets = append(assets, &asset)
 	}
 
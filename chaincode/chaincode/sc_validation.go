@@ -0,0 +1,15 @@
+package chaincode
+
+import (
+	"chaincode/models"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// GetValidationRules returns the rules the arguments of the transactions
+// creating and updating users, products, traders and categories are checked
+// against, so clients can check them before submitting. MetadataChaincode
+// adds the same rules to the schemas of the contract metadata.
+func (sc *SmartContract) GetValidationRules(ctx contractapi.TransactionContextInterface) []models.ModelRules {
+	return models.ValidationRules()
+}
@@ -0,0 +1,30 @@
+package chaincode
+
+import (
+	"chaincode/models"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArgumentsAreValidated(t *testing.T) {
+	sc := SmartContract{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := map[string][]byte{}
+	ctx, stub := newStatefulContext(state, &now)
+
+	err := sc.CreateUser(ctx, models.User{ID: "u1", Email: "not an address", Balances: rsdBalances(20)})
+	var errs models.ValidationErrors
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 3)
+	require.Equal(t, 0, stub.PutStateCallCount())
+
+	putTestModel(t, state, models.Trader{ID: "TRADER-tt1", PIB: "100000008", Currency: "RSD", VerificationStatus: models.VerificationVerified})
+	err = sc.CreateProduct(ctx, models.Product{ID: "m1", Price: rsd(0), Quantity: 1, TraderID: "tt1"})
+	require.ErrorContains(t, err, `{"field":"name","rule":"required","message":"is required"},{"field":"price","rule":"required","message":"is required"}`)
+	require.Equal(t, 0, stub.PutStateCallCount())
+
+	require.Len(t, sc.GetValidationRules(ctx), 4)
+}
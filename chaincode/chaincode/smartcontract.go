@@ -2,8 +2,8 @@ package chaincode
 
 import (
 	"chaincode/models"
-	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
@@ -12,10 +12,10 @@ type SmartContract struct {
 	contractapi.Contract
 }
 
-func putWorldState[T models.Model](models []T, ctx contractapi.TransactionContextInterface) error {
+func putWorldState[T models.Model](entities []T, ctx contractapi.TransactionContextInterface) error {
 
-	for _, model := range models {
-		modelJson, err := json.Marshal(model)
+	for _, model := range entities {
+		modelJson, err := models.EncodeModel(model.GetID(), model)
 		if err != nil {
 			return err
 		}
@@ -23,6 +23,10 @@ func putWorldState[T models.Model](models []T, ctx contractapi.TransactionContex
 		if err := ctx.GetStub().PutState(model.GetID(), modelJson); err != nil {
 			return fmt.Errorf("failed to put an asset into the world state: id:%v err:%v", model.GetID(), err)
 		}
+
+		if _, err := reindex(ctx, model.GetID(), nil, modelJson); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -32,6 +36,10 @@ func (sc *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface)
 
 	initialState := models.GetInitialChainState()
 
+	if err := putWorldState(initialState.Categories, ctx); err != nil {
+		return err
+	}
+
 	if err := putWorldState(initialState.Products, ctx); err != nil {
 		return err
 	}
@@ -40,10 +48,33 @@ func (sc *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface)
 		return err
 	}
 
+	// the seeded balances are recorded as deposits
+	for i := range initialState.Users {
+		user := &initialState.Users[i]
+		for _, currency := range user.Balances.Currencies() {
+			if err := recordMovement(ctx, user, models.MovementDeposit, models.Credit, user.Balances[currency], "", depositLine(user.Balances[currency])); err != nil {
+				return err
+			}
+		}
+	}
+
 	if err := putWorldState(initialState.Users, ctx); err != nil {
 		return err
 	}
 
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range initialState.ExchangeRates {
+		initialState.ExchangeRates[i].UpdatedAt = now.Format(time.RFC3339)
+	}
+
+	if err := putWorldState(initialState.ExchangeRates, ctx); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -70,11 +101,15 @@ func createModel[T models.Model](ctx contractapi.TransactionContextInterface, mo
 		return fmt.Errorf("asset with id:%s already exists", model.GetID())
 	}
 
-	modelJson, err := json.Marshal(model)
+	modelJson, err := models.EncodeModel(model.GetID(), model)
 	if err != nil {
 		return err
 	}
 
+	if _, err := reindex(ctx, model.GetID(), nil, modelJson); err != nil {
+		return err
+	}
+
 	return ctx.GetStub().PutState(model.GetID(), modelJson)
 }
 
@@ -90,7 +125,7 @@ func readModel[T models.Model](ctx contractapi.TransactionContextInterface, id s
 
 	var model T
 
-	if err := json.Unmarshal(modelJson, &model); err != nil {
+	if err := models.DecodeModel(id, modelJson, &model); err != nil {
 		return nil, fmt.Errorf("failed to deserialize the model: %v", err)
 	}
 
@@ -98,30 +133,90 @@ func readModel[T models.Model](ctx contractapi.TransactionContextInterface, id s
 }
 
 func updateModel[T models.Model](ctx contractapi.TransactionContextInterface, id string, model *T) error {
-	exists, err := modelExists(ctx, id)
+	previous, err := ctx.GetStub().GetState(id)
 	if err != nil {
 		return err
 	}
-	if !exists {
+	if previous == nil {
 		return fmt.Errorf("the model %s does not exist", id)
 	}
 
-	modelJson, err := json.Marshal(model)
+	modelJson, err := models.EncodeModel(id, model)
 	if err != nil {
 		return err
 	}
 
+	if _, err := reindex(ctx, id, previous, modelJson); err != nil {
+		return err
+	}
+
 	return ctx.GetStub().PutState(id, modelJson)
 }
 
 func deleteModel(ctx contractapi.TransactionContextInterface, id string) error {
-	exists, err := modelExists(ctx, id)
+	previous, err := ctx.GetStub().GetState(id)
 	if err != nil {
 		return err
 	}
-	if !exists {
+	if previous == nil {
 		return fmt.Errorf("the model %s does not exist", id)
 	}
 
+	if _, err := reindex(ctx, id, previous, nil); err != nil {
+		return err
+	}
+
 	return ctx.GetStub().DelState(id)
 }
+
+// getAllOfType reads every stored entity of the given type with a range scan,
+// which unlike rich queries also works on LevelDB.
+func getAllOfType[T models.Model](ctx contractapi.TransactionContextInterface, entityType string) ([]*T, error) {
+	// '.' is the character right after '-', so the range covers every key
+	// with the "<type>-" prefix
+	resultsIterator, err := ctx.GetStub().GetStateByRange(entityType+"-", entityType+".")
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	assets := make([]*T, 0)
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var asset T
+		if err := models.DecodeModel(queryResponse.Key, queryResponse.Value, &asset); err != nil {
+			return nil, fmt.Errorf("failed to deserialize %s: %v", queryResponse.Key, err)
+		}
+		assets = append(assets, &asset)
+	}
+
+	return assets, nil
+}
+
+func getQueryResult[T models.Model](ctx contractapi.TransactionContextInterface, query string) ([]*T, error) {
+	resultsIterator, err := ctx.GetStub().GetQueryResult(query)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var assets []*T
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var asset T
+		if err := models.DecodeModel(queryResponse.Key, queryResponse.Value, &asset); err != nil {
+			return nil, err
+		}
+		assets = append(assets, &asset)
+	}
+
+	return assets, nil
+}
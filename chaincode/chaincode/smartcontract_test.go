@@ -12,10 +12,23 @@ import (
 	"github.com/google/uuid"
 	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
 
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/stretchr/testify/require"
 )
 
+type clientIdentity interface {
+	cid.ClientIdentity
+}
+
+func rsd(amount int64) models.Money {
+	return models.FromMajorUnits(amount, models.DefaultCurrency)
+}
+
+func rsdBalances(amount int64) models.Balances {
+	return models.Balances{models.DefaultCurrency: rsd(amount)}
+}
+
 func TestInitLedgerProducts(t *testing.T) {
 	chaincodeStub := &mocks.ChaincodeStub{}
 	transactionContext := &mocks.TransactionContext{}
@@ -35,17 +48,31 @@ func TestCreateModel(t *testing.T) {
 	transactionContext := &mocks.TransactionContext{}
 	transactionContext.GetStubReturns(chaincodeStub)
 
+	traderBytes, err := json.Marshal(models.Trader{ID: "TRADER-t1", Products: []string{}})
+	require.NoError(t, err)
+	chaincodeStub.GetStateStub = func(key string) ([]byte, error) {
+		if key == "TRADER-t1" {
+			return traderBytes, nil
+		}
+		return nil, nil
+	}
+
 	assetTransfer := SmartContract{}
 	id := uuid.NewString()
-	err := assetTransfer.CreateProduct(transactionContext, models.Product{ID: id, Name: "p1", ExpirationDate: time.Now().Format(time.RFC3339), Price: 1, Quantity: 1})
+	err = assetTransfer.CreateProduct(transactionContext, models.Product{ID: id, Name: "p1", ExpirationDate: time.Now().Format(time.RFC3339), Price: rsd(1), Quantity: 1, TraderID: "t1"})
 	require.NoError(t, err)
 
+	err = assetTransfer.CreateProduct(transactionContext, models.Product{ID: id, Name: "p1", ExpirationDate: time.Now().Format(time.RFC3339), Price: rsd(1), Quantity: 1, TraderID: "t2"})
+	var missing *models.MissingReferenceError
+	require.ErrorAs(t, err, &missing, "the trader doesn't exist")
+
+	chaincodeStub.GetStateStub = nil
 	chaincodeStub.GetStateReturns([]byte{}, nil)
-	err = assetTransfer.CreateProduct(transactionContext, models.Product{ID: id, Name: "p2", ExpirationDate: time.Now().Format(time.RFC3339), Price: 1, Quantity: 1})
+	err = assetTransfer.CreateProduct(transactionContext, models.Product{ID: id, Name: "p2", ExpirationDate: time.Now().Format(time.RFC3339), Price: rsd(1), Quantity: 1, TraderID: "t1"})
 	require.Error(t, err)
 
 	chaincodeStub.GetStateReturns(nil, fmt.Errorf("unable to retrieve asset"))
-	err = assetTransfer.CreateProduct(transactionContext, models.Product{ID: id, Name: "p3", ExpirationDate: time.Now().Format(time.RFC3339), Price: 1, Quantity: 1})
+	err = assetTransfer.CreateProduct(transactionContext, models.Product{ID: id, Name: "p3", ExpirationDate: time.Now().Format(time.RFC3339), Price: rsd(1), Quantity: 1, TraderID: "t1"})
 	require.Error(t, err)
 }
 
@@ -111,7 +138,12 @@ func TestDeleteModel(t *testing.T) {
 	require.NoError(t, err)
 
 	chaincodeStub.GetStateReturns(bytes, nil)
-	chaincodeStub.DelStateReturns(nil)
+	chaincodeStub.GetStateByRangeStub = func(startKey string, endKey string) (shim.StateQueryIteratorInterface, error) {
+		return rangeIterator(map[string][]byte{}, startKey, endKey), nil
+	}
+	chaincodeStub.GetStateByPartialCompositeKeyStub = func(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+		return rangeIterator(map[string][]byte{}, "", ""), nil
+	}
 	assetTransfer := SmartContract{}
 	err = assetTransfer.DeleteProduct(transactionContext, "")
 	require.NoError(t, err)
@@ -135,12 +167,12 @@ func TestBuyProduct(t *testing.T) {
 
 	// Initial data
 	user := models.User{
-		ID:             "u1",
-		Name:           "User",
-		LastName:       "Test",
-		Email:          "user@test.com",
-		AccountBalance: 100,
-		ReceiptsID:     []string{},
+		ID:         "u1",
+		Name:       "User",
+		LastName:   "Test",
+		Email:      "user@test.com",
+		Balances:   rsdBalances(100),
+		ReceiptsID: []string{},
 	}
 
 	product := models.Product{
@@ -148,31 +180,32 @@ func TestBuyProduct(t *testing.T) {
 		Name:           "Product",
 		TraderID:       "t1",
 		ExpirationDate: time.Now().Format(time.RFC3339),
-		Price:          10,
+		Price:          rsd(10),
 		Quantity:       2,
 	}
 
 	storedUser := models.User{
-		ID:             "USER-u1",
-		Name:           "User",
-		LastName:       "Test",
-		Email:          "user@test.com",
-		AccountBalance: 100,
-		ReceiptsID:     []string{},
+		ID:         "USER-u1",
+		Name:       "User",
+		LastName:   "Test",
+		Email:      "user@test.com",
+		Balances:   rsdBalances(100),
+		ReceiptsID: []string{},
 	}
 	storedProduct := models.Product{
 		ID:             "PRODUCT-p1",
 		Name:           "Product",
 		TraderID:       "t1",
 		ExpirationDate: time.Now().Format(time.RFC3339),
-		Price:          10,
+		Price:          rsd(10),
 		Quantity:       2,
 	}
 	storedTrader := models.Trader{
-		ID:             "TRADER-t1",
-		PIB:            "123456",
-		AccountBalance: 100,
-		Receipts:       []string{},
+		ID:                 "TRADER-t1",
+		PIB:                "100000008",
+		AccountBalance:     rsd(100),
+		Receipts:           []string{},
+		VerificationStatus: models.VerificationVerified,
 	}
 
 	userBytes, _ := json.Marshal(storedUser)
@@ -196,6 +229,7 @@ func TestBuyProduct(t *testing.T) {
 		delete(state, key)
 		return nil
 	}
+	stub.CreateCompositeKeyStub = shim.CreateCompositeKey
 
 	err := sc.BuyProduct(ctx, product.ID, user.ID)
 	require.NoError(t, err)
@@ -207,11 +241,21 @@ func TestBuyProduct(t *testing.T) {
 	require.NoError(t, json.Unmarshal(state[storedTrader.ID], &updatedTrader))
 	require.NoError(t, json.Unmarshal(state[storedProduct.ID], &updatedProduct))
 
-	require.Equal(t, uint(90), updatedUser.AccountBalance)
-	require.Equal(t, uint(110), updatedTrader.AccountBalance)
+	require.Equal(t, rsd(90), updatedUser.Balances.Get(models.DefaultCurrency))
 	require.Equal(t, uint(1), updatedProduct.Quantity)
 	require.Len(t, updatedUser.ReceiptsID, 1)
-	require.Len(t, updatedTrader.Receipts, 1)
+
+	// the trader is paid with a delta, its document isn't written
+	require.Equal(t, rsd(100), updatedTrader.AccountBalance)
+	require.Empty(t, updatedTrader.Receipts)
+
+	deltaKey, err := shim.CreateCompositeKey(models.TRADER_DELTA_TYPE, []string{storedTrader.ID, updatedUser.ReceiptsID[0], string(models.Credit)})
+	require.NoError(t, err)
+
+	var delta models.TraderDelta
+	require.NoError(t, json.Unmarshal(state[deltaKey], &delta))
+	require.Equal(t, rsd(10), delta.Amount)
+	require.Equal(t, updatedUser.ReceiptsID[0], delta.ReceiptID)
 }
 
 func TestQueryProducts(t *testing.T) {
@@ -221,7 +265,7 @@ func TestQueryProducts(t *testing.T) {
 		ID:             "p1",
 		Name:           "Apple",
 		ExpirationDate: time.Now().Format(time.RFC3339),
-		Price:          10,
+		Price:          rsd(10),
 		Quantity:       5,
 		TraderID:       "t1",
 	}
@@ -258,7 +302,7 @@ func TestQueryProducts(t *testing.T) {
 	require.Equal(t, product.Name, results[0].Name)
 
 	queryArg := stub.GetQueryResultArgsForCall(0)
-	expectedQuery := `{"selector":{"name":{"$eq":"Apple"},"trader_id":{"$eq":"t1"}, "price":{"$eq":10}}}`
+	expectedQuery := `{"selector":{"name":{"$eq":"Apple"},"trader_id":{"$eq":"t1"}, "price.amount":{"$eq":1000}}}`
 	require.JSONEq(t, expectedQuery, queryArg)
 }
 
@@ -269,9 +313,9 @@ func TestGetAllProducts(t *testing.T) {
 	iterator := new(mocks.StateQueryIterator)
 
 	products := []models.Product{
-		{ID: "PRODUCT-p1", Name: "p1", Price: 10, TraderID: "t1"},
-		{ID: "PRODUCT-p2", Name: "p2", Price: 10, TraderID: "t2"},
-		{ID: "PRODUCT-p3", Name: "p3", Price: 10, TraderID: "t3"},
+		{ID: "PRODUCT-p1", Name: "p1", Price: rsd(10), TraderID: "t1"},
+		{ID: "PRODUCT-p2", Name: "p2", Price: rsd(10), TraderID: "t2"},
+		{ID: "PRODUCT-p3", Name: "p3", Price: rsd(10), TraderID: "t3"},
 	}
 
 	for i, obj := range products {
@@ -313,10 +357,10 @@ func TestGetAllProducts_FiltersOnlyProducts(t *testing.T) {
 
 	// Prepare test data: products and a non-product entry
 	products := []models.Product{
-		{ID: "PRODUCT-p1", Name: "p1", Price: 10, TraderID: "t1"},
-		{ID: "PRODUCT-p2", Name: "p2", Price: 20, TraderID: "t2"},
+		{ID: "PRODUCT-p1", Name: "p1", Price: rsd(10), TraderID: "t1"},
+		{ID: "PRODUCT-p2", Name: "p2", Price: rsd(20), TraderID: "t2"},
 	}
-	nonProduct := models.Product{ID: "TRADER-t1", Name: "not a product", Price: 0, TraderID: "t0"}
+	nonProduct := models.Product{ID: "TRADER-t1", Name: "not a product", Price: rsd(0), TraderID: "t0"}
 
 	state := map[string][]byte{}
 	for _, p := range products {
@@ -368,10 +412,10 @@ func TestGetAllUsers_FiltersOnlyUsers(t *testing.T) {
 	ctx := new(mocks.TransactionContext)
 
 	products := []models.User{
-		{ID: "USER-p1", Name: "p1", AccountBalance: 10, LastName: "t1", Email: "t2@gmail.com"},
-		{ID: "USER-p2", Name: "p2", AccountBalance: 20, LastName: "t2", Email: "t2@gmail.com"},
+		{ID: "USER-p1", Name: "p1", Balances: rsdBalances(10), LastName: "t1", Email: "t2@gmail.com"},
+		{ID: "USER-p2", Name: "p2", Balances: rsdBalances(20), LastName: "t2", Email: "t2@gmail.com"},
 	}
-	nonProduct := models.User{ID: "TRADER-t1", Name: "not a user", AccountBalance: 0, Email: "t0"}
+	nonProduct := models.User{ID: "TRADER-t1", Name: "not a user", Balances: rsdBalances(0), Email: "t0"}
 
 	state := map[string][]byte{}
 	for _, p := range products {
@@ -411,7 +455,7 @@ func TestGetAllUsers_FiltersOnlyUsers(t *testing.T) {
 	for i, product := range products {
 		require.Equal(t, product.ID, results[i].ID)
 		require.Equal(t, product.Name, results[i].Name)
-		require.Equal(t, product.AccountBalance, results[i].AccountBalance)
+		require.Equal(t, product.Balances, results[i].Balances)
 	}
 }
 
@@ -419,12 +463,12 @@ func TestQueryUsers(t *testing.T) {
 	sc := SmartContract{}
 
 	user := models.User{
-		ID:             "USER-1",
-		Name:           "John",
-		LastName:       "Doe",
-		Email:          "john@example.com",
-		ReceiptsID:     []string{"R1", "R2"},
-		AccountBalance: 100,
+		ID:         "USER-1",
+		Name:       "John",
+		LastName:   "Doe",
+		Email:      "john@example.com",
+		ReceiptsID: []string{"R1", "R2"},
+		Balances:   rsdBalances(100),
 	}
 
 	userBytes, err := json.Marshal(user)
@@ -457,28 +501,28 @@ func TestGetUsersGTEBalance(t *testing.T) {
 
 	users := []models.User{
 		{
-			ID:             "USER-001",
-			Name:           "Alice",
-			LastName:       "Smith",
-			Email:          "alice@example.com",
-			ReceiptsID:     []string{"R1"},
-			AccountBalance: 150,
+			ID:         "USER-001",
+			Name:       "Alice",
+			LastName:   "Smith",
+			Email:      "alice@example.com",
+			ReceiptsID: []string{"R1"},
+			Balances:   rsdBalances(150),
 		},
 		{
-			ID:             "USER-002",
-			Name:           "Bob",
-			LastName:       "Jones",
-			Email:          "bob@example.com",
-			ReceiptsID:     []string{"R2"},
-			AccountBalance: 90,
+			ID:         "USER-002",
+			Name:       "Bob",
+			LastName:   "Jones",
+			Email:      "bob@example.com",
+			ReceiptsID: []string{"R2"},
+			Balances:   rsdBalances(90),
 		},
 		{
-			ID:             "TRADER-001",
-			Name:           "Eve",
-			LastName:       "Black",
-			Email:          "eve@example.com",
-			ReceiptsID:     nil,
-			AccountBalance: 999,
+			ID:         "TRADER-001",
+			Name:       "Eve",
+			LastName:   "Black",
+			Email:      "eve@example.com",
+			ReceiptsID: nil,
+			Balances:   rsdBalances(999),
 		},
 	}
 
@@ -491,7 +535,7 @@ func TestGetUsersGTEBalance(t *testing.T) {
 
 	expectedKeys := []string{}
 	for _, u := range users {
-		if strings.HasPrefix(u.ID, "USER") && u.AccountBalance >= 100 {
+		if strings.HasPrefix(u.ID, "USER") && u.Balances.Get(models.DefaultCurrency).Amount >= rsd(100).Amount {
 			expectedKeys = append(expectedKeys, u.ID)
 		}
 	}
@@ -517,10 +561,10 @@ func TestGetUsersGTEBalance(t *testing.T) {
 	}
 	ctx.GetStubReturns(stub)
 
-	results, err := sc.GetUsersGTEBalance(ctx, 100)
+	results, err := sc.GetUsersGTEBalance(ctx, rsd(100))
 	require.NoError(t, err)
 	require.Len(t, results, 1)
 	require.Equal(t, "USER-001", results[0].ID)
-	require.Equal(t, uint(150), results[0].AccountBalance)
+	require.Equal(t, rsd(150), results[0].Balances.Get(models.DefaultCurrency))
 
 }
@@ -3,10 +3,15 @@ package chaincode
 import (
 	"chaincode/models"
 	"fmt"
+	"slices"
+	"time"
 
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
+const adminOU = "admin"
+
 func (sc *SmartContract) GetEntityById(ctx contractapi.TransactionContextInterface, entityType string, id string) ([]byte, error) {
 	entity, err := ctx.GetStub().GetState(models.FormatKey(entityType, id))
 	if err != nil {
@@ -24,3 +29,50 @@ func (sc *SmartContract) EntityExists(ctx contractapi.TransactionContextInterfac
 
 	return itemJSON != nil, nil
 }
+
+func getTxTime(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read the transaction timestamp: %v", err)
+	}
+
+	return timestamp.AsTime().UTC(), nil
+}
+
+// requireAdmin allows the call only to identities enrolled with the admin
+// node OU of their organization.
+func requireAdmin(ctx contractapi.TransactionContextInterface) error {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !caller.Admin {
+		return fmt.Errorf("the caller is not an admin")
+	}
+
+	return nil
+}
+
+// getCaller reads the caller from the TransactionContext of the namespaced
+// contracts, which caches it, or from the client identity.
+func getCaller(ctx contractapi.TransactionContextInterface) (*Caller, error) {
+	if tc, ok := ctx.(*TransactionContext); ok {
+		return tc.Caller()
+	}
+
+	return readCaller(ctx)
+}
+
+func isAdmin(identity cid.ClientIdentity) (bool, error) {
+	cert, err := identity.GetX509Certificate()
+	if err != nil {
+		return false, fmt.Errorf("failed to read the client certificate: %v", err)
+	}
+
+	if cert != nil && slices.Contains(cert.Subject.OrganizationalUnit, adminOU) {
+		return true, nil
+	}
+
+	return false, nil
+}
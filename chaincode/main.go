@@ -4,16 +4,17 @@ import (
 	"chaincode/chaincode"
 	"log"
 
+	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
 func main() {
-	traderChaincode, err := contractapi.NewChaincode(&chaincode.SmartContract{})
+	traderChaincode, err := contractapi.NewChaincode(chaincode.Contracts()...)
 	if err != nil {
 		log.Panicf("Error creating bank chaincode: %v", err)
 	}
 
-	if err := traderChaincode.Start(); err != nil {
+	if err := shim.Start(chaincode.IdempotentChaincode{Chaincode: chaincode.MetadataChaincode{Chaincode: traderChaincode}}); err != nil {
 		log.Panicf("Error starting bank chaincode: %v", err)
 	}
 }
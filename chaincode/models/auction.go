@@ -0,0 +1,69 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+type AuctionStatus string
+
+const (
+	AuctionOpen    AuctionStatus = "OPEN"
+	AuctionSettled AuctionStatus = "SETTLED"
+)
+
+type BidStatus string
+
+const (
+	BidLocked   BidStatus = "LOCKED"
+	BidWon      BidStatus = "WON"
+	BidReleased BidStatus = "RELEASED"
+)
+
+func init() {
+	// version 2 converts the money stored before amounts had a currency.
+	RegisterMigration(AUCTION_TYPE, moneyMigration(Auction{}))
+	RegisterMigration(BID_TYPE, moneyMigration(Bid{}))
+}
+
+type Auction struct {
+	ID            string        `json:"id"`
+	ProductID     string        `json:"product_id"`
+	TraderID      string        `json:"trader_id"`
+	ReservePrice  Money         `json:"reserve_price"`
+	StartTime     string        `json:"start_time"`
+	EndTime       string        `json:"end_time"`
+	RevealEndTime string        `json:"reveal_end_time"`
+	Sealed        bool          `json:"sealed"`
+	Status        AuctionStatus `json:"status"`
+	HighestBid    Money         `json:"highest_bid"`
+	WinningBidID  string        `json:"winning_bid_id"`
+	Bids          []string      `json:"bids"`
+}
+
+func (a Auction) GetID() string {
+	return a.ID
+}
+
+type Bid struct {
+	ID         string    `json:"id"`
+	AuctionID  string    `json:"auction_id"`
+	UserID     string    `json:"user_id"`
+	Amount     Money     `json:"amount"`
+	Deposit    Money     `json:"deposit"`
+	Commitment string    `json:"commitment"`
+	Revealed   bool      `json:"revealed"`
+	Status     BidStatus `json:"status"`
+}
+
+func (b Bid) GetID() string {
+	return b.ID
+}
+
+// BidCommitment is the hash a bidder submits in a sealed auction and later
+// opens with RevealBid.
+func BidCommitment(amount Money, salt string) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%d:%s:%s", amount.Amount, amount.Currency, salt)))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,39 @@
+package models
+
+import "fmt"
+
+type AuditRule string
+
+const (
+	RuleReceiptUserMissing      AuditRule = "RECEIPT_USER_MISSING"
+	RuleReceiptTraderMissing    AuditRule = "RECEIPT_TRADER_MISSING"
+	RuleUserReceiptsMismatch    AuditRule = "USER_RECEIPTS_MISMATCH"
+	RuleTraderReceiptsMismatch  AuditRule = "TRADER_RECEIPTS_MISMATCH"
+	RuleUserBalanceUnexplained  AuditRule = "USER_BALANCE_UNEXPLAINED"
+	RuleUserLockedMismatch      AuditRule = "USER_LOCKED_BALANCE_MISMATCH"
+	RuleTraderBalanceMismatch   AuditRule = "TRADER_BALANCE_UNEXPLAINED"
+	RuleProductOwnerMissing     AuditRule = "PRODUCT_OWNER_MISSING"
+	RuleProductNotListed        AuditRule = "PRODUCT_NOT_LISTED"
+	RuleTraderProductMissing    AuditRule = "TRADER_PRODUCT_MISSING"
+	RuleMovementChainBroken     AuditRule = "MOVEMENT_CHAIN_BROKEN"
+	RuleReservationCountInvalid AuditRule = "PRODUCT_RESERVED_MISMATCH"
+	RuleJournalUnbalanced       AuditRule = "JOURNAL_ENTRY_UNBALANCED"
+	RuleAccountUnreconciled     AuditRule = "ACCOUNT_UNRECONCILED"
+)
+
+type Violation struct {
+	Rule     AuditRule `json:"rule"`
+	EntityID string    `json:"entity_id"`
+	Message  string    `json:"message"`
+}
+
+// AuditReport lists the broken invariants found in the world state together
+// with the number of entities of each type that were checked.
+type AuditReport struct {
+	Checked    map[string]int `json:"checked"`
+	Violations []*Violation   `json:"violations"`
+}
+
+func (r *AuditReport) Add(rule AuditRule, entityId string, format string, args ...interface{}) {
+	r.Violations = append(r.Violations, &Violation{Rule: rule, EntityID: entityId, Message: fmt.Sprintf(format, args...)})
+}
@@ -0,0 +1,95 @@
+package models
+
+import "sort"
+
+// Balances holds one balance per currency, keyed by the ISO currency code.
+type Balances map[string]Money
+
+// Get returns the balance in the currency, which is zero when the currency
+// was never credited.
+func (b Balances) Get(currency string) Money {
+	if balance, ok := b[currency]; ok {
+		return balance
+	}
+
+	return Money{Currency: currency}
+}
+
+// Currencies returns the currencies of the balances in alphabetical order.
+func (b Balances) Currencies() []string {
+	currencies := make([]string, 0, len(b))
+	for currency := range b {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	return currencies
+}
+
+func (b *Balances) Credit(amount Money) error {
+	if err := amount.Validate(); err != nil {
+		return err
+	}
+
+	if amount.IsZero() {
+		return nil
+	}
+
+	balance, err := b.Get(amount.Currency).Add(amount)
+	if err != nil {
+		return err
+	}
+
+	if *b == nil {
+		*b = make(Balances)
+	}
+	(*b)[amount.Currency] = balance
+
+	return nil
+}
+
+func (b *Balances) Debit(amount Money) error {
+	if err := amount.Validate(); err != nil {
+		return err
+	}
+
+	if amount.IsZero() {
+		return nil
+	}
+
+	balance, err := b.Get(amount.Currency).Sub(amount)
+	if err != nil {
+		return err
+	}
+	(*b)[amount.Currency] = balance
+
+	return nil
+}
+
+// Add returns the sum of both balances per currency.
+func (b Balances) Add(other Balances) (Balances, error) {
+	sum := make(Balances, len(b))
+	for _, balances := range []Balances{b, other} {
+		for _, balance := range balances {
+			if err := sum.Credit(balance); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return sum, nil
+}
+
+func (b Balances) Validate() error {
+	for currency, balance := range b {
+		if err := balance.Validate(); err != nil {
+			return err
+		}
+
+		if balance.Currency != currency && !balance.IsZero() {
+			return ErrCurrencyMismatch
+		}
+	}
+
+	return nil
+}
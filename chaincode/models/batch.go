@@ -0,0 +1,73 @@
+package models
+
+import "fmt"
+
+// MAX_BATCH_SIZE is the most items a batch transaction accepts, so that its
+// read and write sets stay well within the block size.
+const MAX_BATCH_SIZE = 500
+
+// BatchMode is what a batch transaction does when some of its items fail.
+type BatchMode string
+
+const (
+	// BatchAllOrNothing writes nothing unless every item is valid.
+	BatchAllOrNothing BatchMode = "ALL_OR_NOTHING"
+	// BatchBestEffort writes the valid items and skips the others.
+	BatchBestEffort BatchMode = "BEST_EFFORT"
+)
+
+func ParseBatchMode(value string) (BatchMode, error) {
+	mode := BatchMode(value)
+	switch mode {
+	case BatchAllOrNothing, BatchBestEffort:
+		return mode, nil
+	case "":
+		return BatchAllOrNothing, nil
+	default:
+		return "", fmt.Errorf("unknown batch mode %s", value)
+	}
+}
+
+// BatchItemResult is the outcome of one item, at its index in the batch.
+type BatchItemResult struct {
+	Index int    `json:"index"`
+	ID    string `json:"id"`
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+// BatchResult reports every item of a batch transaction. Committed tells
+// whether the valid items were written, which all-or-nothing batches only
+// do when no item failed.
+type BatchResult struct {
+	Mode      BatchMode         `json:"mode"`
+	Committed bool              `json:"committed"`
+	Valid     int               `json:"valid"`
+	Failed    int               `json:"failed"`
+	Items     []BatchItemResult `json:"items"`
+}
+
+func NewBatchResult(mode BatchMode, size int) *BatchResult {
+	return &BatchResult{Mode: mode, Items: make([]BatchItemResult, 0, size)}
+}
+
+func (r *BatchResult) Add(index int, id string, err error) {
+	item := BatchItemResult{Index: index, ID: id, Valid: err == nil}
+	if err != nil {
+		item.Error = err.Error()
+		r.Failed++
+	} else {
+		r.Valid++
+	}
+
+	r.Items = append(r.Items, item)
+}
+
+// ShouldCommit tells whether the valid items are to be written.
+func (r *BatchResult) ShouldCommit() bool {
+	if r.Mode == BatchAllOrNothing && r.Failed > 0 {
+		return false
+	}
+
+	return r.Valid > 0
+}
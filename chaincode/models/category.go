@@ -0,0 +1,96 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// Category is a kind of trader. Its AttributesSchema is the JSON schema of
+// the extra attributes of the products sold by traders of the category.
+type Category struct {
+	ID               string         `json:"id" validate:"required,max=64"`
+	Name             string         `json:"name" validate:"required,max=100"`
+	Description      string         `json:"description"`
+	AttributesSchema map[string]any `json:"attributes_schema"`
+}
+
+func (c Category) GetID() string {
+	return c.ID
+}
+
+// ValidateSchema checks that the attributes schema is a valid JSON schema.
+// Only references within the schema are allowed, chaincode can't fetch
+// documents from elsewhere.
+func (c Category) ValidateSchema() error {
+	if err := checkLocalRefs(c.AttributesSchema); err != nil {
+		return err
+	}
+
+	if _, err := c.schema(); err != nil {
+		return fmt.Errorf("invalid attributes schema of category %s: %v", c.ID, err)
+	}
+
+	return nil
+}
+
+// ValidateAttributes checks the attributes of a product against the schema
+// and reports every attribute that doesn't match it.
+func (c Category) ValidateAttributes(attributes map[string]any) error {
+	schema, err := c.schema()
+	if err != nil {
+		return fmt.Errorf("invalid attributes schema of category %s: %v", c.ID, err)
+	}
+
+	if attributes == nil {
+		attributes = map[string]any{}
+	}
+
+	result, err := schema.Validate(gojsonschema.NewGoLoader(attributes))
+	if err != nil {
+		return err
+	}
+
+	if result.Valid() {
+		return nil
+	}
+
+	problems := make([]string, 0, len(result.Errors()))
+	for _, problem := range result.Errors() {
+		problems = append(problems, problem.String())
+	}
+
+	return fmt.Errorf("product attributes don't match category %s: %s", c.ID, strings.Join(problems, "; "))
+}
+
+func (c Category) schema() (*gojsonschema.Schema, error) {
+	if c.AttributesSchema == nil {
+		return gojsonschema.NewSchema(gojsonschema.NewGoLoader(map[string]any{}))
+	}
+
+	return gojsonschema.NewSchema(gojsonschema.NewGoLoader(c.AttributesSchema))
+}
+
+func checkLocalRefs(value any) error {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if ref, ok := child.(string); ok && key == "$ref" && !strings.HasPrefix(ref, "#") {
+				return fmt.Errorf("attributes schema can't refer to %s, only references within the schema are allowed", ref)
+			}
+
+			if err := checkLocalRefs(child); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, child := range v {
+			if err := checkLocalRefs(child); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitialProductsMatchTheirCategory(t *testing.T) {
+	state := GetInitialChainState()
+
+	categories := map[string]Category{}
+	for _, category := range state.Categories {
+		require.NoError(t, category.ValidateSchema(), category.ID)
+		categories[TrimKeyPrefix(CATEGORY_TYPE, category.ID)] = category
+	}
+
+	for _, product := range state.Products {
+		category, ok := categories[product.Category]
+		require.True(t, ok, product.ID)
+		require.NoError(t, category.ValidateAttributes(product.Attributes), product.ID)
+	}
+
+	require.Error(t, categories[string(AutoParts)].ValidateAttributes(map[string]any{"part_number": "SW-1", "color": "red"}))
+}
@@ -4,3 +4,15 @@ const PRODUCT_TYPE string = "PRODUCT"
 const USER_TYPE string = "USER"
 const TRADER_TYPE string = "TRADER"
 const RECEIPT_TYPE string = "RECEIPT"
+const AUCTION_TYPE string = "AUCTION"
+const BID_TYPE string = "BID"
+const HTLC_TYPE string = "HTLC"
+const CLAIM_TYPE string = "CLAIM"
+const EXCHANGE_RATE_TYPE string = "EXCHANGERATE"
+const RESERVATION_TYPE string = "RESERVATION"
+const MOVEMENT_TYPE string = "MOVEMENT"
+const CONFIG_TYPE string = "CONFIG"
+const CATEGORY_TYPE string = "CATEGORY"
+const IDEMPOTENCY_TYPE string = "IDEMPOTENCY"
+const RECEIPT_SEQUENCE_TYPE string = "RECEIPTSEQ"
+const JOURNAL_TYPE string = "JOURNAL"
@@ -0,0 +1,53 @@
+package models
+
+import "slices"
+
+// TRADER_DELTA_TYPE is the object type of the composite keys of the trader
+// deltas, which are keyed by the trader's key, the receipt and the
+// direction. A receipt is sold and refunded once, so the keys are unique.
+const TRADER_DELTA_TYPE string = "TRADERDELTA"
+
+// TraderDelta is a change of a trader's balance and receipts written by a
+// sale or a refund. The deltas have keys of their own, so sales only read the
+// trader's document and don't conflict with each other on it. They don't
+// commit in the same block though: every sale also takes the next number of
+// the trader's gap-free receipt sequence, and concurrent sales of a trader
+// conflict on that counter. Gap-free numbers given out in the sale need
+// that, so the deltas only keep the trader's document from being a second
+// hot key. Reads of the trader add its deltas up until a compaction folds
+// them into the document.
+type TraderDelta struct {
+	ID        string            `json:"id"`
+	TraderID  string            `json:"trader_id"`
+	Direction MovementDirection `json:"direction"`
+	Amount    Money             `json:"amount"`
+	// ReceiptID is the receipt that was sold, or refunded. Sales add it to
+	// the trader's receipts.
+	ReceiptID string `json:"receipt_id"`
+	TxID      string `json:"tx_id"`
+}
+
+// CompactionResult reports a compaction of the trader deltas. Done is false
+// when the limit left deltas to fold.
+type CompactionResult struct {
+	Deltas  int  `json:"deltas"`
+	Traders int  `json:"traders"`
+	Done    bool `json:"done"`
+}
+
+// Apply adds the delta to the trader's balance and receipts.
+func (t *Trader) Apply(delta TraderDelta) error {
+	if delta.Direction == Debit {
+		return t.Debit(delta.Amount)
+	}
+
+	if err := t.Credit(delta.Amount); err != nil {
+		return err
+	}
+
+	if delta.ReceiptID != "" && !slices.Contains(t.Receipts, delta.ReceiptID) {
+		t.Receipts = append(t.Receipts, delta.ReceiptID)
+	}
+
+	return nil
+}
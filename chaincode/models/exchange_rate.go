@@ -0,0 +1,108 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// RateScale is the number of rate units in one; rates keep six decimals.
+const RateScale int64 = 1_000_000
+
+// ExchangeRate is the price of one unit of Base in Quote. Every update bumps
+// the version, so receipts can refer to the exact rate they were paid at.
+type ExchangeRate struct {
+	ID        string `json:"id"`
+	Base      string `json:"base"`
+	Quote     string `json:"quote"`
+	Rate      int64  `json:"rate"`
+	Version   uint64 `json:"version"`
+	UpdatedAt string `json:"updated_at"`
+	// MaxAge is the number of seconds after UpdatedAt the rate may be used.
+	MaxAge int64 `json:"max_age"`
+}
+
+func (e ExchangeRate) GetID() string {
+	return e.ID
+}
+
+// AppliedRate records the exchange rate a payment was converted with.
+type AppliedRate struct {
+	Base    string `json:"base"`
+	Quote   string `json:"quote"`
+	Rate    int64  `json:"rate"`
+	Version uint64 `json:"version"`
+}
+
+// ParseRate reads a decimal rate such as "117.2" with at most six decimals.
+func ParseRate(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	whole, fraction, hasFraction := strings.Cut(value, ".")
+
+	if hasFraction && (len(fraction) == 0 || len(fraction) > 6) {
+		return 0, fmt.Errorf("invalid rate %q: at most six decimal places are allowed", value)
+	}
+
+	major, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || major < 0 {
+		return 0, fmt.Errorf("invalid rate %q", value)
+	}
+
+	var minor int64
+	if hasFraction {
+		fraction += strings.Repeat("0", 6-len(fraction))
+		minor, err = strconv.ParseInt(fraction, 10, 64)
+		if err != nil || minor < 0 {
+			return 0, fmt.Errorf("invalid rate %q", value)
+		}
+	}
+
+	if major > (math.MaxInt64-minor)/RateScale {
+		return 0, fmt.Errorf("invalid rate %q: too large", value)
+	}
+
+	rate := major*RateScale + minor
+	if rate == 0 {
+		return 0, fmt.Errorf("rate must be greater than zero")
+	}
+
+	return rate, nil
+}
+
+// Convert returns amount, given in Base, in Quote. Both currencies are
+// assumed to have MinorUnits minor units; the result is rounded half up.
+func (r AppliedRate) Convert(amount Money) (Money, error) {
+	if amount.Currency != r.Base {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, amount.Currency, r.Base)
+	}
+
+	return scale(amount.Amount, r.Rate, RateScale, r.Quote)
+}
+
+// ConvertBack returns amount, given in Quote, in Base, rounded half up.
+func (r AppliedRate) ConvertBack(amount Money) (Money, error) {
+	if amount.Currency != r.Quote {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, amount.Currency, r.Quote)
+	}
+
+	return scale(amount.Amount, RateScale, r.Rate, r.Base)
+}
+
+// scale returns amount * numerator / denominator rounded half up.
+func scale(amount int64, numerator int64, denominator int64, currency string) (Money, error) {
+	result := new(big.Int).Mul(big.NewInt(amount), big.NewInt(numerator))
+	result.Add(result, big.NewInt(denominator/2))
+	result.Quo(result, big.NewInt(denominator))
+
+	if !result.IsInt64() {
+		return Money{}, ErrMoneyOverflow
+	}
+
+	return Money{Amount: result.Int64(), Currency: currency}, nil
+}
+
+func (r AppliedRate) String() string {
+	return fmt.Sprintf("1 %s = %d.%06d %s", r.Base, r.Rate/RateScale, r.Rate%RateScale, r.Quote)
+}
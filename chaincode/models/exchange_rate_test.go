@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRate(t *testing.T) {
+	rate, err := ParseRate("117.2")
+	require.NoError(t, err)
+	require.Equal(t, int64(117_200_000), rate)
+
+	rate, err = ParseRate("0.008532")
+	require.NoError(t, err)
+	require.Equal(t, int64(8_532), rate)
+
+	for _, invalid := range []string{"", "0", "-1", "1.1234567", "1.", "abc"} {
+		_, err := ParseRate(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestAppliedRateConvert(t *testing.T) {
+	rate := AppliedRate{Base: "EUR", Quote: "RSD", Rate: 117_200_000, Version: 3}
+
+	converted, err := rate.Convert(NewMoney(500, "EUR"))
+	require.NoError(t, err)
+	require.Equal(t, NewMoney(58_600, "RSD"), converted)
+
+	// 10.00 RSD is 0.0853... EUR, rounded to 0.09 EUR
+	converted, err = rate.ConvertBack(NewMoney(1_000, "RSD"))
+	require.NoError(t, err)
+	require.Equal(t, NewMoney(9, "EUR"), converted)
+
+	_, err = rate.Convert(NewMoney(500, "RSD"))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestBalances(t *testing.T) {
+	var balances Balances
+	require.NoError(t, balances.Credit(NewMoney(500, "EUR")))
+	require.NoError(t, balances.Credit(NewMoney(100, "RSD")))
+	require.ErrorIs(t, balances.Debit(NewMoney(200, "RSD")), ErrInsufficientFunds)
+	require.NoError(t, balances.Debit(NewMoney(200, "EUR")))
+
+	require.Equal(t, NewMoney(300, "EUR"), balances.Get("EUR"))
+	require.Equal(t, NewMoney(100, "RSD"), balances.Get("RSD"))
+	require.Equal(t, NewMoney(0, "USD"), balances.Get("USD"))
+
+	total, err := balances.Add(Balances{"RSD": NewMoney(50, "RSD")})
+	require.NoError(t, err)
+	require.Equal(t, Balances{"EUR": NewMoney(300, "EUR"), "RSD": NewMoney(150, "RSD")}, total)
+}
+
+func TestUserReadsLegacyBalance(t *testing.T) {
+	var user User
+	require.NoError(t, DecodeModel("USER-u1", []byte(`{"id":"USER-u1","account_balance":1000,"locked_balance":{"amount":250,"currency":"EUR"}}`), &user))
+	require.Equal(t, Balances{"RSD": NewMoney(100_000, "RSD")}, user.Balances)
+	require.Equal(t, Balances{"EUR": NewMoney(250, "EUR")}, user.LockedBalances)
+}
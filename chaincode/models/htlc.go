@@ -0,0 +1,69 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+type HTLCStatus string
+
+const (
+	HTLCLocked   HTLCStatus = "LOCKED"
+	HTLCClaimed  HTLCStatus = "CLAIMED"
+	HTLCRefunded HTLCStatus = "REFUNDED"
+)
+
+type ClaimStatus string
+
+const (
+	ClaimPending  ClaimStatus = "PENDING"
+	ClaimRedeemed ClaimStatus = "REDEEMED"
+)
+
+// HTLC locks funds on the source channel of a cross-channel transfer until
+// the preimage of HashLock is revealed or the timeout passes.
+func init() {
+	// version 2 converts the money stored before amounts had a currency.
+	RegisterMigration(HTLC_TYPE, moneyMigration(HTLC{}))
+	RegisterMigration(CLAIM_TYPE, moneyMigration(Claim{}))
+}
+
+type HTLC struct {
+	ID              string     `json:"id"`
+	UserID          string     `json:"user_id"`
+	RecipientID     string     `json:"recipient_id"`
+	Amount          Money      `json:"amount"`
+	HashLock        string     `json:"hash_lock"`
+	Timeout         string     `json:"timeout"`
+	TargetChannel   string     `json:"target_channel"`
+	TargetChaincode string     `json:"target_chaincode"`
+	Status          HTLCStatus `json:"status"`
+	Preimage        string     `json:"preimage"`
+}
+
+func (h HTLC) GetID() string {
+	return h.ID
+}
+
+// Claim is the target channel side of an HTLC. It shares the lock's id and
+// hash lock and credits the recipient once the preimage is revealed.
+type Claim struct {
+	ID              string      `json:"id"`
+	SourceChannel   string      `json:"source_channel"`
+	SourceChaincode string      `json:"source_chaincode"`
+	RecipientID     string      `json:"recipient_id"`
+	Amount          Money       `json:"amount"`
+	HashLock        string      `json:"hash_lock"`
+	Timeout         string      `json:"timeout"`
+	Status          ClaimStatus `json:"status"`
+	Preimage        string      `json:"preimage"`
+}
+
+func (c Claim) GetID() string {
+	return c.ID
+}
+
+func HashPreimage(preimage string) string {
+	hash := sha256.Sum256([]byte(preimage))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// IDEMPOTENCY_KEY_FIELD is the field of the transient data a transaction
+// carries its idempotency key in.
+const IDEMPOTENCY_KEY_FIELD = "idempotency_key"
+
+// MAX_IDEMPOTENCY_KEY_LENGTH bounds the keys clients may send.
+const MAX_IDEMPOTENCY_KEY_LENGTH = 128
+
+// IdempotencyRecord is the response of a transaction submitted with an
+// idempotency key. A transaction submitted again with the key gets the
+// recorded payload instead of running a second time.
+type IdempotencyRecord struct {
+	ID string `json:"id"`
+	// RequestHash fingerprints the caller and the transaction's arguments,
+	// a key can't be reused for another request.
+	RequestHash string `json:"request_hash"`
+	Transaction string `json:"transaction"`
+	Payload     []byte `json:"payload"`
+	TxID        string `json:"tx_id"`
+	RecordedAt  string `json:"recorded_at"`
+}
+
+func (r IdempotencyRecord) GetID() string {
+	return r.ID
+}
+
+// ValidateIdempotencyKey checks that a key is short and printable.
+func ValidateIdempotencyKey(key string) error {
+	if len(key) > MAX_IDEMPOTENCY_KEY_LENGTH {
+		return fmt.Errorf("idempotency key must be at most %d characters", MAX_IDEMPOTENCY_KEY_LENGTH)
+	}
+
+	for _, c := range key {
+		if c < '!' || c > '~' {
+			return fmt.Errorf("idempotency key must be printable ascii without spaces")
+		}
+	}
+
+	return nil
+}
+
+// ToIdempotencyRecordID scopes the key to the caller, two clients using the
+// same key don't see each other's responses.
+func ToIdempotencyRecordID(callerId string, key string) string {
+	hash := sha256.Sum256([]byte(callerId + "\x00" + key))
+	return FormatKey(IDEMPOTENCY_TYPE, hex.EncodeToString(hash[:]))
+}
+
+// IdempotencyRequestHash fingerprints the caller and the arguments of a
+// transaction, the name of the function included.
+func IdempotencyRequestHash(callerId string, args [][]byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(callerId))
+	for _, arg := range args {
+		hash.Write([]byte{0})
+		hash.Write(arg)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -1,6 +1,9 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 func BuildQueryIdStartsWith(prefix string) string {
 	return fmt.Sprintf("{\"selector\": {\"id\": { \"$regex\": \"^(%s-)\" } } }", prefix)
@@ -38,3 +41,65 @@ func ToReceiptID(id string) string {
 func ToTraderID(id string) string {
 	return FormatKey(TRADER_TYPE, id)
 }
+
+func ToCategoryID(id string) string {
+	return FormatKey(CATEGORY_TYPE, id)
+}
+
+func ToAuctionID(id string) string {
+	return FormatKey(AUCTION_TYPE, id)
+}
+
+func ToBidID(id string) string {
+	return FormatKey(BID_TYPE, id)
+}
+
+func ToHTLCID(id string) string {
+	return FormatKey(HTLC_TYPE, id)
+}
+
+func ToClaimID(id string) string {
+	return FormatKey(CLAIM_TYPE, id)
+}
+
+func ToReservationID(id string) string {
+	return FormatKey(RESERVATION_TYPE, id)
+}
+
+// ToMovementID numbers the movements of each user, so their keys sort in
+// the order they were made.
+func ToMovementID(userId string, sequence uint64) string {
+	return FormatKey(MOVEMENT_TYPE, fmt.Sprintf("%s-%012d", userId, sequence))
+}
+
+// MovementKeyRange returns the key range of the user's movements. It also
+// covers the movements of users whose id starts with userId and a '-'.
+func MovementKeyRange(userId string) (string, string) {
+	prefix := FormatKey(MOVEMENT_TYPE, userId)
+	return prefix + "-", prefix + "."
+}
+
+func ToReceiptSequenceID(traderId string, year int) string {
+	return FormatKey(RECEIPT_SEQUENCE_TYPE, fmt.Sprintf("%s-%04d", traderId, year))
+}
+
+// ReceiptNumberKeyRange returns the key range of the receipts the trader
+// numbered in the year. It also covers the receipts of traders whose id
+// starts with traderId, a '-' and the year.
+func ReceiptNumberKeyRange(traderId string, year int) (string, string) {
+	prefix := ToReceiptID(fmt.Sprintf("%s-%04d", traderId, year))
+	return prefix + "-", prefix + "."
+}
+
+// ToJournalID keys a journal entry by the key of the record it journals.
+func ToJournalID(recordKey string) string {
+	return FormatKey(JOURNAL_TYPE, recordKey)
+}
+
+func TrimKeyPrefix(entityType string, key string) string {
+	return strings.TrimPrefix(key, entityType+"-")
+}
+
+func ToExchangeRateID(base string, quote string) string {
+	return FormatKey(EXCHANGE_RATE_TYPE, base+"-"+quote)
+}
@@ -8,6 +8,10 @@ type InitialChainState struct {
 	Products []Product
 	Traders  []Trader
 	Users    []User
+	// ExchangeRates are stored with the time of the transaction that
+	// initializes the ledger.
+	ExchangeRates []ExchangeRate
+	Categories    []Category
 }
 
 func getIds[T Model](models []T) []string {
@@ -21,40 +25,73 @@ func getIds[T Model](models []T) []string {
 }
 
 func GetInitialChainState() InitialChainState {
+	rsd := func(amount int64) Money {
+		return FromMajorUnits(amount, DefaultCurrency)
+	}
+	eur := func(amount int64) Money {
+		return FromMajorUnits(amount, "EUR")
+	}
 
 	marketProducts := []Product{
-		{ID: ToProductID("t1"), Name: "Tomato", ExpirationDate: time.Now().Add(10 * 24 * time.Hour).UTC().Format("02-01-2006"), Price: 2, Quantity: 10, TraderID: "tt1"},
-		{ID: ToProductID("b1"), Name: "Bread", ExpirationDate: time.Now().Add(2 * 24 * time.Hour).UTC().Format("02-01-2006"), Price: 3, Quantity: 10, TraderID: "tt1"},
-		{ID: ToProductID("c1"), Name: "Cucumber", ExpirationDate: time.Now().Add(10 * 24 * time.Hour).UTC().Format("02-01-2006"), Price: 2, Quantity: 10, TraderID: "tt1"},
-		{ID: ToProductID("m1"), Name: "Milk", ExpirationDate: time.Now().Add(30 * 24 * time.Hour).UTC().Format("02-01-2006"), Price: 3, Quantity: 10, TraderID: "tt1"},
+		{ID: ToProductID("t1"), Name: "Tomato", ExpirationDate: time.Now().Add(10 * 24 * time.Hour).UTC().Format("02-01-2006"), Price: rsd(2), Quantity: 10, Category: string(Market), Attributes: map[string]any{"weight_unit": "kg"}, TraderID: "tt1"},
+		{ID: ToProductID("b1"), Name: "Bread", ExpirationDate: time.Now().Add(2 * 24 * time.Hour).UTC().Format("02-01-2006"), Price: rsd(3), Quantity: 10, Category: string(Market), Attributes: map[string]any{"weight_unit": "pcs"}, TraderID: "tt1"},
+		{ID: ToProductID("c1"), Name: "Cucumber", ExpirationDate: time.Now().Add(10 * 24 * time.Hour).UTC().Format("02-01-2006"), Price: rsd(2), Quantity: 10, Category: string(Market), Attributes: map[string]any{"weight_unit": "kg"}, TraderID: "tt1"},
+		{ID: ToProductID("m1"), Name: "Milk", ExpirationDate: time.Now().Add(30 * 24 * time.Hour).UTC().Format("02-01-2006"), Price: rsd(3), Quantity: 10, Category: string(Market), Attributes: map[string]any{"weight_unit": "l"}, TraderID: "tt1"},
 	}
 
 	autoParts := []Product{
-		{ID: ToProductID("sw1"), Name: "Steering Wheel", Price: 5, Quantity: 10, TraderID: "tt2"},
-		{ID: ToProductID("ti1"), Name: "Tire", Price: 8, Quantity: 10, TraderID: "tt2"},
-		{ID: ToProductID("ge1"), Name: "Gearbox", Price: 20, Quantity: 10, TraderID: "tt2"},
+		{ID: ToProductID("sw1"), Name: "Steering Wheel", Price: eur(5), Quantity: 10, Category: string(AutoParts), Attributes: map[string]any{"part_number": "SW-1001", "oem_code": "4F0419091"}, TraderID: "tt2"},
+		{ID: ToProductID("ti1"), Name: "Tire", Price: eur(8), Tiers: []PriceTier{{MinQuantity: 4, UnitPrice: eur(7)}}, Quantity: 10, Category: string(AutoParts), Attributes: map[string]any{"part_number": "TI-2055"}, TraderID: "tt2"},
+		{ID: ToProductID("ge1"), Name: "Gearbox", Price: eur(20), Quantity: 10, Category: string(AutoParts), Attributes: map[string]any{"part_number": "GE-6100", "oem_code": "02M300048"}, TraderID: "tt2"},
 	}
 
 	motoParts := []Product{
-		{ID: ToProductID("si1"), Name: "Side Mirrors", Price: 6, Quantity: 10, TraderID: "tt3"},
-		{ID: ToProductID("pi1"), Name: "Pillion Seat Cover", Price: 4, Quantity: 10, TraderID: "tt3"},
-		{ID: ToProductID("br1"), Name: "Braking Pads", Price: 5, Quantity: 10, TraderID: "tt3"},
+		{ID: ToProductID("si1"), Name: "Side Mirrors", Price: rsd(6), Quantity: 10, Category: string(MotorcycleParts), Attributes: map[string]any{"part_number": "SI-0310"}, TraderID: "tt3"},
+		{ID: ToProductID("pi1"), Name: "Pillion Seat Cover", Price: rsd(4), Quantity: 10, Category: string(MotorcycleParts), Attributes: map[string]any{"part_number": "PI-0420"}, TraderID: "tt3"},
+		{ID: ToProductID("br1"), Name: "Braking Pads", Price: rsd(5), Quantity: 10, Category: string(MotorcycleParts), Attributes: map[string]any{"part_number": "BR-0530"}, TraderID: "tt3"},
 	}
 
 	allProducts := append(marketProducts, autoParts...)
 	allProducts = append(allProducts, motoParts...)
 
 	traders := []Trader{
-		{ID: ToTraderID("tt1"), TraderType: Market, PIB: "pib1", Products: getIds(marketProducts), Receipts: make([]string, 0)},
-		{ID: ToTraderID("tt2"), TraderType: AutoParts, PIB: "pib2", Products: getIds(autoParts), Receipts: make([]string, 0)},
-		{ID: ToTraderID("tt3"), TraderType: MotorcycleParts, PIB: "pib3", Products: getIds(motoParts), Receipts: make([]string, 0)},
+		{ID: ToTraderID("tt1"), TraderType: Market, PIB: "100000008", VerificationStatus: VerificationVerified, Currency: DefaultCurrency, Products: getIds(marketProducts), Receipts: make([]string, 0)},
+		{ID: ToTraderID("tt2"), TraderType: AutoParts, PIB: "100000016", VerificationStatus: VerificationVerified, Currency: "EUR", Products: getIds(autoParts), Receipts: make([]string, 0)},
+		{ID: ToTraderID("tt3"), TraderType: MotorcycleParts, PIB: "100000024", VerificationStatus: VerificationVerified, Currency: DefaultCurrency, Products: getIds(motoParts), Receipts: make([]string, 0)},
 	}
 
 	users := []User{
-		{ID: ToUserID("jj1"), Name: "Jon", LastName: "Jones", Email: "duck@jonjones.com", AccountBalance: 0, ReceiptsID: make([]string, 0)},
-		{ID: ToUserID("it1"), Name: "Ilia", LastName: "Topuria", Email: "copycat@connor.com", AccountBalance: 0, ReceiptsID: make([]string, 0)},
-		{ID: ToUserID("ou1"), Name: "Oleksandr", LastName: "Usyk", Email: "heavy.goat@box.com", AccountBalance: 1000, ReceiptsID: make([]string, 0)},
+		{ID: ToUserID("jj1"), Name: "Jon", LastName: "Jones", Email: "duck@jonjones.com", Balances: Balances{}, ReceiptsID: make([]string, 0)},
+		{ID: ToUserID("it1"), Name: "Ilia", LastName: "Topuria", Email: "copycat@connor.com", Balances: Balances{}, ReceiptsID: make([]string, 0)},
+		{ID: ToUserID("ou1"), Name: "Oleksandr", LastName: "Usyk", Email: "heavy.goat@box.com", Balances: Balances{DefaultCurrency: rsd(1000), "EUR": eur(50)}, ReceiptsID: make([]string, 0)},
+	}
+
+	exchangeRates := []ExchangeRate{
+		{ID: ToExchangeRateID("EUR", DefaultCurrency), Base: "EUR", Quote: DefaultCurrency, Rate: 117_200_000, Version: 1, MaxAge: 24 * 60 * 60},
+	}
+
+	partSchema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"part_number": map[string]any{"type": "string", "minLength": 1},
+			"oem_code":    map[string]any{"type": "string"},
+		},
+		"required":             []any{"part_number"},
+		"additionalProperties": false,
+	}
+
+	categories := []Category{
+		{ID: ToCategoryID(string(Market)), Name: "Market", Description: "Groceries sold by weight, volume or piece", AttributesSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"weight_unit": map[string]any{"enum": []any{"g", "kg", "ml", "l", "pcs"}},
+			},
+			"required":             []any{"weight_unit"},
+			"additionalProperties": false,
+		}},
+		{ID: ToCategoryID(string(AutoParts)), Name: "Auto parts", Description: "Car parts", AttributesSchema: partSchema},
+		{ID: ToCategoryID(string(MotorcycleParts)), Name: "Motorcycle parts", Description: "Motorcycle parts", AttributesSchema: partSchema},
 	}
 
-	return InitialChainState{Products: allProducts, Traders: traders, Users: users}
+	return InitialChainState{Products: allProducts, Traders: traders, Users: users, ExchangeRates: exchangeRates, Categories: categories}
 }
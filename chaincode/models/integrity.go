@@ -0,0 +1,93 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DeleteAction is what deleting a record does to the records that refer to
+// it through a relation.
+type DeleteAction string
+
+const (
+	// DeleteRestrict refuses to delete a record that is still in use.
+	DeleteRestrict DeleteAction = "RESTRICT"
+	// DeleteCascade deletes the referring records together with the record.
+	DeleteCascade DeleteAction = "CASCADE"
+	// DeleteKeep leaves the referring records pointing at the archived
+	// record, which stays readable.
+	DeleteKeep DeleteAction = "KEEP"
+)
+
+func ParseDeleteAction(value string) (DeleteAction, error) {
+	action := DeleteAction(strings.ToUpper(value))
+	switch action {
+	case DeleteRestrict, DeleteCascade, DeleteKeep:
+		return action, nil
+	default:
+		return "", fmt.Errorf("unknown delete action %s", value)
+	}
+}
+
+// REFERENCE_INDEX is the object type of the composite keys indexing the
+// relations, keyed by the relation's name, the referenced id and the key of
+// the referring record. The value tells whether the referring record still
+// uses the referenced one, so deletes don't have to read the records.
+const REFERENCE_INDEX string = "REF"
+
+const (
+	REFERENCE_IN_USE string = "1"
+	REFERENCE_UNUSED string = "0"
+)
+
+// INTEGRITY_CONFIG_ID is the key of the delete actions chosen by the admins.
+const INTEGRITY_CONFIG_ID string = CONFIG_TYPE + "-integrity"
+
+// IntegrityConfig overrides the default delete actions of relations, keyed
+// by relation name.
+type IntegrityConfig struct {
+	ID      string                  `json:"id"`
+	Actions map[string]DeleteAction `json:"actions"`
+}
+
+func (c IntegrityConfig) GetID() string {
+	return c.ID
+}
+
+// IntegrityRule describes a relation and the delete action in effect for it.
+type IntegrityRule struct {
+	Relation      string       `json:"relation"`
+	From          string       `json:"from"`
+	Field         string       `json:"field"`
+	To            string       `json:"to"`
+	Action        DeleteAction `json:"action"`
+	DefaultAction DeleteAction `json:"default_action"`
+}
+
+// MissingReferenceError is returned when a record is written with a foreign
+// key to a record that doesn't exist, or that is archived where an active
+// record is required.
+type MissingReferenceError struct {
+	Relation string
+	Key      string
+	Archived bool
+}
+
+func (e *MissingReferenceError) Error() string {
+	if e.Archived {
+		return fmt.Sprintf("%s refers to %s, which is archived", e.Relation, e.Key)
+	}
+	return fmt.Sprintf("%s refers to %s, which doesn't exist", e.Relation, e.Key)
+}
+
+// RestrictedDeleteError is returned when a record can't be deleted because
+// other records still refer to it.
+type RestrictedDeleteError struct {
+	Key       string
+	Relation  string
+	Referrers []string
+}
+
+func (e *RestrictedDeleteError) Error() string {
+	return fmt.Sprintf("%s can't be deleted, it's still referenced through %s by %s", e.Key, e.Relation, strings.Join(e.Referrers, ", "))
+}
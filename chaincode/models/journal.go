@@ -0,0 +1,157 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+)
+
+// The accounts of the journal. Users and traders hold liabilities of the
+// ledger, which credits increase; the money coming from outside the channel
+// is debited to the deposits account.
+const (
+	DepositsAccount  = "EXTERNAL:DEPOSITS"
+	TransfersAccount = "CLEARING:TRANSFERS"
+	ExchangeAccount  = "CLEARING:EXCHANGE"
+)
+
+func UserAccount(userId string) string {
+	return "USER:" + userId
+}
+
+// LockedAccount holds the user's bid deposits.
+func LockedAccount(userId string) string {
+	return "LOCKED:" + userId
+}
+
+func TraderAccount(traderId string) string {
+	return "TRADER:" + traderId
+}
+
+type JournalLine struct {
+	Account   string            `json:"account"`
+	Direction MovementDirection `json:"direction"`
+	Amount    Money             `json:"amount"`
+}
+
+// JournalEntry is one money movement in double entry. Its debits and credits
+// balance in every currency. An entry is keyed by the record it journals,
+// such as the user's movement.
+type JournalEntry struct {
+	ID        string         `json:"id"`
+	Kind      MovementKind   `json:"kind"`
+	Reference string         `json:"reference"`
+	Lines     []*JournalLine `json:"lines"`
+	Timestamp string         `json:"timestamp"`
+	TxID      string         `json:"tx_id"`
+}
+
+func (e JournalEntry) GetID() string {
+	return e.ID
+}
+
+func opposite(direction MovementDirection) MovementDirection {
+	if direction == Debit {
+		return Credit
+	}
+
+	return Debit
+}
+
+// ContraLines balance a movement of amount with settled on the account, in
+// the given direction. When the currencies differ, the amount is exchanged
+// for settled through the exchange account.
+func ContraLines(account string, direction MovementDirection, amount Money, settled Money) []*JournalLine {
+	if amount.Currency == settled.Currency {
+		return []*JournalLine{{Account: account, Direction: direction, Amount: settled}}
+	}
+
+	return []*JournalLine{
+		{Account: ExchangeAccount, Direction: direction, Amount: amount},
+		{Account: ExchangeAccount, Direction: opposite(direction), Amount: settled},
+		{Account: account, Direction: direction, Amount: settled},
+	}
+}
+
+// Totals adds up the debits and the credits of the lines per currency.
+func Totals(lines []*JournalLine) (Balances, Balances, error) {
+	var debits, credits Balances
+	for _, line := range lines {
+		if line.Direction == Debit {
+			if err := debits.Credit(line.Amount); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		if err := credits.Credit(line.Amount); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return debits, credits, nil
+}
+
+// Validate checks that the entry has valid lines whose debits and credits
+// balance in every currency.
+func (e JournalEntry) Validate() error {
+	if len(e.Lines) < 2 {
+		return fmt.Errorf("journal entry %s needs a debit and a credit", e.ID)
+	}
+
+	for _, line := range e.Lines {
+		if line.Account == "" || (line.Direction != Debit && line.Direction != Credit) {
+			return fmt.Errorf("journal entry %s has an invalid line", e.ID)
+		}
+
+		if err := line.Amount.Validate(); err != nil {
+			return err
+		}
+	}
+
+	debits, credits, err := Totals(e.Lines)
+	if err != nil {
+		return err
+	}
+
+	for _, currency := range unionOfCurrencies(debits, credits) {
+		if debits.Get(currency) != credits.Get(currency) {
+			return fmt.Errorf("journal entry %s debits %s but credits %s", e.ID, debits.Get(currency), credits.Get(currency))
+		}
+	}
+
+	return nil
+}
+
+func unionOfCurrencies(a Balances, b Balances) []string {
+	currencies := append(a.Currencies(), b.Currencies()...)
+	slices.Sort(currencies)
+	return slices.Compact(currencies)
+}
+
+// AccountTotals are the debits and credits posted to an account in a
+// currency.
+type AccountTotals struct {
+	Account  string `json:"account"`
+	Currency string `json:"currency"`
+	Debits   Money  `json:"debits"`
+	Credits  Money  `json:"credits"`
+}
+
+// CurrencyTotals are the debits and credits of all the accounts in a
+// currency, which are equal when the books balance.
+type CurrencyTotals struct {
+	Currency string `json:"currency"`
+	Debits   Money  `json:"debits"`
+	Credits  Money  `json:"credits"`
+	Balanced bool   `json:"balanced"`
+}
+
+// TrialBalance lists the totals of every account of the journal. Unbalanced
+// are the entries whose own debits and credits differ.
+type TrialBalance struct {
+	Entries    int               `json:"entries"`
+	Accounts   []*AccountTotals  `json:"accounts"`
+	Totals     []*CurrencyTotals `json:"totals"`
+	Unbalanced []string          `json:"unbalanced"`
+	Balanced   bool              `json:"balanced"`
+}
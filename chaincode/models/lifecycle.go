@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+type RecordStatus string
+
+const (
+	RecordActive   RecordStatus = "ACTIVE"
+	RecordArchived RecordStatus = "ARCHIVED"
+)
+
+// Lifecycle is embedded in the records that are archived instead of being
+// removed from the world state, so that the records referring to them stay
+// valid. Records stored before archiving was introduced have no status and
+// are active.
+type Lifecycle struct {
+	Status RecordStatus `json:"status,omitempty" metadata:",optional"`
+	// DeletedAt is the RFC 3339 time the record was archived at.
+	DeletedAt string `json:"deleted_at,omitempty" metadata:",optional"`
+}
+
+func (l Lifecycle) IsArchived() bool {
+	return l.Status == RecordArchived
+}
+
+func (l Lifecycle) ArchivedAt() string {
+	return l.DeletedAt
+}
+
+func (l *Lifecycle) Archive(at time.Time) {
+	l.Status = RecordArchived
+	l.DeletedAt = at.Format(time.RFC3339)
+}
+
+func (l *Lifecycle) Restore() {
+	l.Status = RecordActive
+	l.DeletedAt = ""
+}
+
+// ArchivedRecord identifies an archived record in a listing of the archive.
+type ArchivedRecord struct {
+	ID         string `json:"id"`
+	EntityType string `json:"entity_type"`
+	DeletedAt  string `json:"deleted_at"`
+}
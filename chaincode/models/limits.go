@@ -0,0 +1,142 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	SpendingDayLayout   = "2006-01-02"
+	SpendingMonthLayout = "2006-01"
+)
+
+// SpendingLimits cap what a user spends on purchases per calendar day and
+// month, in UTC. The limits are per currency, purchases paid in a currency
+// without a limit aren't capped.
+type SpendingLimits struct {
+	Daily   Balances `json:"daily"`
+	Monthly Balances `json:"monthly"`
+}
+
+func (l SpendingLimits) Validate() error {
+	for _, limits := range []Balances{l.Daily, l.Monthly} {
+		for currency, limit := range limits {
+			if limit.Currency != currency {
+				return fmt.Errorf("the limit in %s must be given in %s", currency, currency)
+			}
+		}
+
+		if err := limits.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Spending is what a user spent on purchases in the day and the month of
+// their last purchase. The periods come from the transaction timestamps, so
+// every peer counts the same.
+type Spending struct {
+	Day     string   `json:"day"`
+	Daily   Balances `json:"daily"`
+	Month   string   `json:"month"`
+	Monthly Balances `json:"monthly"`
+}
+
+// current returns the spending in the periods of now. Spending of earlier
+// periods no longer counts.
+func (s Spending) current(now time.Time) Spending {
+	day := now.UTC().Format(SpendingDayLayout)
+	month := now.UTC().Format(SpendingMonthLayout)
+
+	current := Spending{Day: day, Month: month, Daily: Balances{}, Monthly: Balances{}}
+	if s.Day == day {
+		for _, spent := range s.Daily {
+			current.Daily[spent.Currency] = spent
+		}
+	}
+	if s.Month == month {
+		for _, spent := range s.Monthly {
+			current.Monthly[spent.Currency] = spent
+		}
+	}
+
+	return current
+}
+
+// AccountFreeze records why and when an admin froze an account.
+type AccountFreeze struct {
+	Reason   string `json:"reason"`
+	FrozenAt string `json:"frozen_at"`
+	TxID     string `json:"tx_id"`
+}
+
+func (u User) IsFrozen() bool {
+	return u.Freeze != nil
+}
+
+// CheckDebit refuses any debit of a frozen account.
+func (u User) CheckDebit() error {
+	if u.IsFrozen() {
+		return fmt.Errorf("account %s is frozen: %s", TrimKeyPrefix(USER_TYPE, u.ID), u.Freeze.Reason)
+	}
+
+	return nil
+}
+
+// CheckSpend checks that a purchase of amount at now fits in the user's
+// limits.
+func (u User) CheckSpend(amount Money, now time.Time) error {
+	_, err := u.spendingAfter(amount, now)
+	return err
+}
+
+// Spend counts a purchase of amount at now against the user's limits.
+func (u *User) Spend(amount Money, now time.Time) error {
+	spending, err := u.spendingAfter(amount, now)
+	if err != nil {
+		return err
+	}
+
+	u.Spending = spending
+	return nil
+}
+
+func (u User) spendingAfter(amount Money, now time.Time) (Spending, error) {
+	spending := u.Spending.current(now)
+
+	if err := spending.Daily.Credit(amount); err != nil {
+		return Spending{}, err
+	}
+	if err := spending.Monthly.Credit(amount); err != nil {
+		return Spending{}, err
+	}
+
+	periods := []struct {
+		name  string
+		limit Balances
+		spent Balances
+	}{
+		{"daily", u.Limits.Daily, spending.Daily},
+		{"monthly", u.Limits.Monthly, spending.Monthly},
+	}
+
+	for _, period := range periods {
+		limit, ok := period.limit[amount.Currency]
+		if !ok {
+			continue
+		}
+
+		cmp, err := period.spent.Get(amount.Currency).Compare(limit)
+		if err != nil {
+			return Spending{}, err
+		}
+
+		if cmp > 0 {
+			return Spending{}, fmt.Errorf("the purchase of %s exceeds the %s spending limit of %s", amount, period.name, limit)
+		}
+	}
+
+	return spending, nil
+}
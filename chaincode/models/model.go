@@ -1,7 +1,7 @@
 package models
 
 type Model interface {
-	Product | User | Trader | Receipt
+	Product | User | Trader | Receipt | Auction | Bid | HTLC | Claim | ExchangeRate | Reservation | Movement | IntegrityConfig | Category | IdempotencyRecord | ReceiptSequence | JournalEntry
 
 	GetID() string
 }
@@ -0,0 +1,268 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const DefaultCurrency string = "RSD"
+
+// MinorUnits is the number of minor units (para, cent) in one major unit.
+const MinorUnits int64 = 100
+
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+var ErrMoneyOverflow = errors.New("money amount overflow")
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+var currencyPattern = regexp.MustCompile("^[A-Z]{3}$")
+
+var amountPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]{1,2})?$`)
+
+// Money is an amount in integer minor units of an ISO 4217 currency. The zero
+// value is a zero amount without a currency and can be combined with money
+// of any currency.
+type Money struct {
+	Amount   int64  `json:"amount" validate:"min=0"`
+	Currency string `json:"currency" validate:"currency"`
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ParseMoney reads a decimal amount such as "2.49" in the given currency.
+func ParseMoney(value string, currency string) (Money, error) {
+	value = strings.TrimSpace(value)
+	if !amountPattern.MatchString(value) {
+		return Money{}, fmt.Errorf("invalid amount %q: expected digits with at most two decimal places", value)
+	}
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	major, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q", value)
+	}
+
+	fraction += strings.Repeat("0", 2-len(fraction))
+	minor, err := strconv.ParseInt(fraction, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q", value)
+	}
+
+	if major > (math.MaxInt64-minor)/MinorUnits {
+		return Money{}, ErrMoneyOverflow
+	}
+
+	money := Money{Amount: major*MinorUnits + minor, Currency: currency}
+	return money, money.Validate()
+}
+
+func (m Money) Validate() error {
+	if m.Amount < 0 {
+		return fmt.Errorf("money amount can't be negative")
+	}
+
+	if (m.Amount != 0 || m.Currency != "") && !currencyPattern.MatchString(m.Currency) {
+		return fmt.Errorf("invalid currency code %q", m.Currency)
+	}
+
+	return nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) String() string {
+	return fmt.Sprintf("%d.%02d %s", m.Amount/MinorUnits, m.Amount%MinorUnits, m.Currency)
+}
+
+// currencyWith returns the currency of the result of combining m and other.
+func (m Money) currencyWith(other Money) (string, error) {
+	switch {
+	case m.Currency == other.Currency:
+		return m.Currency, nil
+	case m.Currency == "" && m.Amount == 0:
+		return other.Currency, nil
+	case other.Currency == "" && other.Amount == 0:
+		return m.Currency, nil
+	}
+
+	return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	currency, err := m.currencyWith(other)
+	if err != nil {
+		return Money{}, err
+	}
+
+	if other.Amount > 0 && m.Amount > math.MaxInt64-other.Amount {
+		return Money{}, ErrMoneyOverflow
+	}
+
+	return Money{Amount: m.Amount + other.Amount, Currency: currency}, nil
+}
+
+// Sub subtracts other from m. Money amounts are never negative, so a result
+// below zero is reported as ErrInsufficientFunds.
+func (m Money) Sub(other Money) (Money, error) {
+	currency, err := m.currencyWith(other)
+	if err != nil {
+		return Money{}, err
+	}
+
+	if other.Amount > m.Amount {
+		return Money{}, ErrInsufficientFunds
+	}
+
+	return Money{Amount: m.Amount - other.Amount, Currency: currency}, nil
+}
+
+func (m Money) Mul(quantity uint) (Money, error) {
+	if quantity != 0 && uint64(m.Amount) > uint64(math.MaxInt64)/uint64(quantity) {
+		return Money{}, ErrMoneyOverflow
+	}
+
+	return Money{Amount: m.Amount * int64(quantity), Currency: m.Currency}, nil
+}
+
+// Compare returns -1, 0 or 1 when m is less than, equal to or greater than
+// other.
+func (m Money) Compare(other Money) (int, error) {
+	if _, err := m.currencyWith(other); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case m.Amount < other.Amount:
+		return -1, nil
+	case m.Amount > other.Amount:
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
+// moneyType is the type whose bare whole number documents the money
+// migrations convert.
+var moneyType = reflect.TypeOf(Money{})
+
+// moneyMigration converts the money of the model's stored fields, nested ones
+// included, from the bare whole numbers stored before amounts had a currency.
+// They are read as major units of DefaultCurrency. Money that already has a
+// currency is left as it is.
+func moneyMigration(model any) Migration {
+	modelType := reflect.TypeOf(model)
+	return func(document map[string]json.RawMessage) error {
+		return upgradeMoneyFields(modelType, document)
+	}
+}
+
+func upgradeMoneyFields(structType reflect.Type, document map[string]json.RawMessage) error {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := upgradeMoneyFields(field.Type, document); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := fieldName(field)
+		value, ok := document[name]
+		if !ok {
+			continue
+		}
+
+		upgraded, err := upgradeMoneyValue(field.Type, value)
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		document[name] = upgraded
+	}
+
+	return nil
+}
+
+func upgradeMoneyValue(valueType reflect.Type, value json.RawMessage) (json.RawMessage, error) {
+	for valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+
+	if string(value) == "null" {
+		return value, nil
+	}
+
+	switch {
+	case valueType == moneyType:
+		var legacy uint64
+		if err := json.Unmarshal(value, &legacy); err != nil {
+			return value, nil
+		}
+
+		if legacy > uint64(math.MaxInt64/MinorUnits) {
+			return nil, ErrMoneyOverflow
+		}
+
+		return json.Marshal(Money{Amount: int64(legacy) * MinorUnits, Currency: DefaultCurrency})
+	case valueType.Kind() == reflect.Slice || valueType.Kind() == reflect.Array:
+		var items []json.RawMessage
+		if err := json.Unmarshal(value, &items); err != nil {
+			return nil, err
+		}
+
+		for i := range items {
+			upgraded, err := upgradeMoneyValue(valueType.Elem(), items[i])
+			if err != nil {
+				return nil, err
+			}
+			items[i] = upgraded
+		}
+
+		return json.Marshal(items)
+	case valueType.Kind() == reflect.Map:
+		var items map[string]json.RawMessage
+		if err := json.Unmarshal(value, &items); err != nil {
+			return nil, err
+		}
+
+		for key := range items {
+			upgraded, err := upgradeMoneyValue(valueType.Elem(), items[key])
+			if err != nil {
+				return nil, err
+			}
+			items[key] = upgraded
+		}
+
+		return json.Marshal(items)
+	case valueType.Kind() == reflect.Struct && nestedStruct(valueType) != nil:
+		var document map[string]json.RawMessage
+		if err := json.Unmarshal(value, &document); err != nil {
+			return nil, err
+		}
+
+		if err := upgradeMoneyFields(valueType, document); err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(document)
+	}
+
+	return value, nil
+}
+
+// FromMajorUnits returns the money worth amount whole units of the currency.
+func FromMajorUnits(amount int64, currency string) Money {
+	return Money{Amount: amount * MinorUnits, Currency: currency}
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	money, err := ParseMoney("2.49", "RSD")
+	require.NoError(t, err)
+	require.Equal(t, Money{Amount: 249, Currency: "RSD"}, money)
+
+	money, err = ParseMoney("3.5", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, Money{Amount: 350, Currency: "EUR"}, money)
+
+	money, err = ParseMoney("7", "RSD")
+	require.NoError(t, err)
+	require.Equal(t, int64(700), money.Amount)
+	require.Equal(t, "7.00 RSD", money.String())
+
+	for _, invalid := range []string{"", "-1", "+1", "1.234", "1.", "1.-5", "1.+5", ".5", "abc", "92233720368547758.08"} {
+		_, err := ParseMoney(invalid, "RSD")
+		require.Error(t, err, invalid)
+	}
+
+	_, err = ParseMoney("1.00", "dinar")
+	require.Error(t, err)
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	a := NewMoney(250, "RSD")
+	b := NewMoney(100, "RSD")
+
+	sum, err := a.Add(b)
+	require.NoError(t, err)
+	require.Equal(t, NewMoney(350, "RSD"), sum)
+
+	diff, err := a.Sub(b)
+	require.NoError(t, err)
+	require.Equal(t, NewMoney(150, "RSD"), diff)
+
+	_, err = b.Sub(a)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = a.Add(NewMoney(1, "EUR"))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = NewMoney(math.MaxInt64, "RSD").Add(NewMoney(1, "RSD"))
+	require.ErrorIs(t, err, ErrMoneyOverflow)
+
+	_, err = NewMoney(math.MaxInt64/2+1, "RSD").Mul(2)
+	require.ErrorIs(t, err, ErrMoneyOverflow)
+
+	product, err := a.Mul(4)
+	require.NoError(t, err)
+	require.Equal(t, NewMoney(1000, "RSD"), product)
+
+	// the zero value takes over the currency of the other operand
+	sum, err = Money{}.Add(NewMoney(5, "EUR"))
+	require.NoError(t, err)
+	require.Equal(t, NewMoney(5, "EUR"), sum)
+
+	cmp, err := a.Compare(b)
+	require.NoError(t, err)
+	require.Equal(t, 1, cmp)
+}
+
+func TestMoneyJSON(t *testing.T) {
+	bytes, err := json.Marshal(NewMoney(249, "RSD"))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"amount":249,"currency":"RSD"}`, string(bytes))
+
+	var money Money
+	require.NoError(t, json.Unmarshal(bytes, &money))
+	require.Equal(t, NewMoney(249, "RSD"), money)
+
+	// amounts stored before the migration were whole dinars
+	var product Product
+	require.NoError(t, DecodeModel("PRODUCT-b1", []byte(`{"id":"PRODUCT-b1","price":3}`), &product))
+	require.Equal(t, NewMoney(300, DefaultCurrency), product.Price)
+}
@@ -0,0 +1,62 @@
+package models
+
+type MovementKind string
+
+const (
+	MovementDeposit        MovementKind = "DEPOSIT"
+	MovementPurchase       MovementKind = "PURCHASE"
+	MovementRefund         MovementKind = "REFUND"
+	MovementTransferOut    MovementKind = "TRANSFER_OUT"
+	MovementTransferIn     MovementKind = "TRANSFER_IN"
+	MovementTransferReturn MovementKind = "TRANSFER_RETURN"
+	MovementBidDeposit     MovementKind = "BID_DEPOSIT"
+	MovementBidRelease     MovementKind = "BID_RELEASE"
+	// MovementWithdrawal pays out the balances a purged user leaves behind.
+	MovementWithdrawal MovementKind = "WITHDRAWAL"
+)
+
+type MovementDirection string
+
+const (
+	Debit  MovementDirection = "DEBIT"
+	Credit MovementDirection = "CREDIT"
+)
+
+// Movement is one change of a user's spendable balance. Balance is the
+// balance in the currency of the amount right after the movement.
+type Movement struct {
+	ID        string            `json:"id"`
+	UserID    string            `json:"user_id"`
+	Kind      MovementKind      `json:"kind"`
+	Direction MovementDirection `json:"direction"`
+	Amount    Money             `json:"amount"`
+	Balance   Money             `json:"balance"`
+	Reference string            `json:"reference"`
+	Timestamp string            `json:"timestamp"`
+	TxID      string            `json:"tx_id"`
+}
+
+func (m Movement) GetID() string {
+	return m.ID
+}
+
+// BalanceBefore is the balance in the currency right before the movement.
+func (m Movement) BalanceBefore() (Money, error) {
+	if m.Direction == Credit {
+		return m.Balance.Sub(m.Amount)
+	}
+
+	return m.Balance.Add(m.Amount)
+}
+
+// Statement lists the movements of a user's balance in one currency between
+// From and To, both inclusive.
+type Statement struct {
+	UserID   string      `json:"user_id"`
+	Currency string      `json:"currency"`
+	From     string      `json:"from"`
+	To       string      `json:"to"`
+	Opening  Money       `json:"opening_balance"`
+	Closing  Money       `json:"closing_balance"`
+	Entries  []*Movement `json:"entries"`
+}
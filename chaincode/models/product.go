@@ -1,14 +1,90 @@
 package models
 
+import "fmt"
+
+func init() {
+	// version 2 converts the money stored before amounts had a currency.
+	RegisterMigration(PRODUCT_TYPE, moneyMigration(Product{}))
+}
+
+// PriceTier is the unit price of purchases of at least MinQuantity units.
+type PriceTier struct {
+	MinQuantity uint  `json:"min_quantity" validate:"required,min=2"`
+	UnitPrice   Money `json:"unit_price" validate:"required"`
+}
+
 type Product struct {
-	ID             string `json:"id"`
-	Name           string `json:"name"`
+	ID             string `json:"id" validate:"required,max=64"`
+	Name           string `json:"name" validate:"required,max=200"`
 	ExpirationDate string `json:"expiration_date"`
-	Price          uint   `json:"price"`
-	Quantity       uint   `json:"quantity"`
-	TraderID       string `json:"trader_id"`
+	Price          Money  `json:"price" validate:"required"`
+	// Tiers lower the unit price of bulk purchases. They are ordered by
+	// their minimum quantity.
+	Tiers []PriceTier `json:"tiers" metadata:",optional"`
+	// Quantity is the stock available for sale; Reserved is the stock held
+	// by the active reservations listed in Reservations.
+	Quantity     uint     `json:"quantity"`
+	Reserved     uint     `json:"reserved" metadata:",optional"`
+	Reservations []string `json:"reservations" metadata:",optional"`
+	TraderID     string   `json:"trader_id" validate:"required"`
+	// Category is the category of the product's trader. Its schema
+	// describes the Attributes.
+	Category   string         `json:"category,omitempty" metadata:",optional"`
+	Attributes map[string]any `json:"attributes,omitempty" metadata:",optional"`
+
+	Lifecycle
 }
 
 func (p Product) GetID() string {
 	return p.ID
 }
+
+// ValidatePricing checks the price and that every tier is in the same
+// currency, starts at more units and is cheaper than the one before it.
+func (p Product) ValidatePricing() error {
+	if err := p.Price.Validate(); err != nil {
+		return err
+	}
+
+	previous := PriceTier{MinQuantity: 1, UnitPrice: p.Price}
+	for _, tier := range p.Tiers {
+		if err := tier.UnitPrice.Validate(); err != nil {
+			return err
+		}
+
+		if tier.MinQuantity <= previous.MinQuantity {
+			return fmt.Errorf("tier for %d units must start above %d units", tier.MinQuantity, previous.MinQuantity)
+		}
+
+		cmp, err := tier.UnitPrice.Compare(previous.UnitPrice)
+		if err != nil {
+			return fmt.Errorf("tier for %d units: %v", tier.MinQuantity, err)
+		}
+
+		if cmp >= 0 || tier.UnitPrice.IsZero() {
+			return fmt.Errorf("tier for %d units must have a lower unit price than %s", tier.MinQuantity, previous.UnitPrice)
+		}
+
+		previous = tier
+	}
+
+	return nil
+}
+
+// UnitPrice returns the unit price of a purchase of the quantity and the tier
+// it comes from, which is nil when the base price applies.
+func (p Product) UnitPrice(quantity uint) (Money, *PriceTier) {
+	for i := len(p.Tiers) - 1; i >= 0; i-- {
+		if quantity >= p.Tiers[i].MinQuantity {
+			tier := p.Tiers[i]
+			return tier.UnitPrice, &tier
+		}
+	}
+
+	return p.Price, nil
+}
+
+// SoldOut reports whether no stock is left, neither available nor reserved.
+func (p Product) SoldOut() bool {
+	return p.Quantity == 0 && p.Reserved == 0
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductValidatePricing(t *testing.T) {
+	product := Product{Price: NewMoney(800, "EUR"), Tiers: []PriceTier{
+		{MinQuantity: 4, UnitPrice: NewMoney(700, "EUR")},
+		{MinQuantity: 10, UnitPrice: NewMoney(650, "EUR")},
+	}}
+	require.NoError(t, product.ValidatePricing())
+
+	invalid := map[string][]PriceTier{
+		"single unit":         {{MinQuantity: 1, UnitPrice: NewMoney(700, "EUR")}},
+		"unordered":           {{MinQuantity: 10, UnitPrice: NewMoney(650, "EUR")}, {MinQuantity: 4, UnitPrice: NewMoney(700, "EUR")}},
+		"not cheaper":         {{MinQuantity: 4, UnitPrice: NewMoney(800, "EUR")}},
+		"free":                {{MinQuantity: 4, UnitPrice: NewMoney(0, "EUR")}},
+		"different currency":  {{MinQuantity: 4, UnitPrice: NewMoney(700, "RSD")}},
+		"negative unit price": {{MinQuantity: 4, UnitPrice: NewMoney(-1, "EUR")}},
+	}
+	for name, tiers := range invalid {
+		product.Tiers = tiers
+		require.Error(t, product.ValidatePricing(), name)
+	}
+}
+
+func TestProductUnitPrice(t *testing.T) {
+	product := Product{Price: NewMoney(800, "EUR"), Tiers: []PriceTier{
+		{MinQuantity: 4, UnitPrice: NewMoney(700, "EUR")},
+		{MinQuantity: 10, UnitPrice: NewMoney(650, "EUR")},
+	}}
+
+	price, tier := product.UnitPrice(3)
+	require.Equal(t, NewMoney(800, "EUR"), price)
+	require.Nil(t, tier)
+
+	price, tier = product.UnitPrice(4)
+	require.Equal(t, NewMoney(700, "EUR"), price)
+	require.Equal(t, &product.Tiers[0], tier)
+
+	price, _ = product.UnitPrice(12)
+	require.Equal(t, NewMoney(650, "EUR"), price)
+}
@@ -1,13 +1,53 @@
 package models
 
+// ReceiptDateLayout is the layout of the receipt date.
+const ReceiptDateLayout = "02-01-2006"
+
+func init() {
+	// version 2 converts the money stored before amounts had a currency.
+	RegisterMigration(RECEIPT_TYPE, moneyMigration(Receipt{}))
+}
+
 type Receipt struct {
 	ID        string `json:"id"`
 	TraderID  string `json:"trader"`
 	UserID    string `json:"user_id"`
 	ProductID string `json:"product_id"`
-	Date      string `json:"date"`
+	Price     Money  `json:"price"`
+	Quantity  uint   `json:"quantity"`
+	// Tier is the price tier the units were sold at, if any.
+	Tier *PriceTier `json:"tier,omitempty"`
+	// Paid is what the user was charged. It differs from Price when the user
+	// paid in another currency, converted with ExchangeRate.
+	Paid         Money        `json:"paid"`
+	ExchangeRate *AppliedRate `json:"exchange_rate,omitempty"`
+	Date         string       `json:"date"`
+	// RefundedAt is the RFC 3339 time of the refund of a refunded receipt.
+	RefundedAt string `json:"refunded_at,omitempty"`
+
+	Lifecycle
 }
 
 func (r Receipt) GetID() string {
 	return r.ID
 }
+
+// Units is the number of units sold. Receipts stored before quantities were
+// recorded are for a single unit.
+func (r Receipt) Units() uint {
+	if r.Quantity == 0 {
+		return 1
+	}
+
+	return r.Quantity
+}
+
+// AmountPaid is what the user was charged. Receipts stored before payments
+// were recorded were paid at the price.
+func (r Receipt) AmountPaid() Money {
+	if r.Paid.IsZero() && r.Paid.Currency == "" {
+		return r.Price
+	}
+
+	return r.Paid
+}
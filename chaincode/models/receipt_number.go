@@ -0,0 +1,70 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ReceiptSequence numbers the fiscal receipts of a trader in a year. Last is
+// the number of the latest receipt. Every sale of the trader writes it, so
+// the trader's sales commit one at a time.
+type ReceiptSequence struct {
+	ID       string `json:"id"`
+	TraderID string `json:"trader_id"`
+	Year     int    `json:"year"`
+	Last     uint64 `json:"last"`
+}
+
+func (s ReceiptSequence) GetID() string {
+	return s.ID
+}
+
+// FormatReceiptNumber formats the fiscal number of a receipt, such as
+// tt1-2026-000123. Receipts are stored under their number.
+func FormatReceiptNumber(traderId string, year int, sequence uint64) string {
+	return fmt.Sprintf("%s-%04d-%06d", traderId, year, sequence)
+}
+
+// ParseReceiptNumber splits a fiscal receipt number into the trader, the year
+// and the sequence number. Trader ids may contain '-', so the number is split
+// from the right.
+func ParseReceiptNumber(number string) (string, int, uint64, error) {
+	i := strings.LastIndex(number, "-")
+	if i < 0 {
+		return "", 0, 0, fmt.Errorf("invalid receipt number %q", number)
+	}
+
+	sequence, err := strconv.ParseUint(number[i+1:], 10, 64)
+	if err != nil || sequence == 0 {
+		return "", 0, 0, fmt.Errorf("invalid sequence in receipt number %q", number)
+	}
+
+	j := strings.LastIndex(number[:i], "-")
+	if j <= 0 || i-j-1 != 4 {
+		return "", 0, 0, fmt.Errorf("invalid receipt number %q", number)
+	}
+
+	year, err := strconv.Atoi(number[j+1 : i])
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("invalid year in receipt number %q", number)
+	}
+
+	return number[:j], year, sequence, nil
+}
+
+// SequenceReport is the check of the receipt numbers a trader issued in a
+// year. The sequence is complete when every number up to Last has a receipt
+// and no receipt has a number the sequence didn't issue.
+type SequenceReport struct {
+	TraderID string `json:"trader_id"`
+	Year     int    `json:"year"`
+	Last     uint64 `json:"last"`
+	Issued   int    `json:"issued"`
+	// Missing are the numbers up to Last without a receipt.
+	Missing []string `json:"missing"`
+	// Unexpected are the receipts in the year's range whose number is past
+	// Last or malformed.
+	Unexpected []string `json:"unexpected"`
+	Complete   bool     `json:"complete"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReceiptNumber(t *testing.T) {
+	trader, year, sequence, err := ParseReceiptNumber(FormatReceiptNumber("tt-1", 2026, 123))
+	require.NoError(t, err)
+	require.Equal(t, "tt-1", trader)
+	require.Equal(t, 2026, year)
+	require.Equal(t, uint64(123), sequence)
+
+	for _, number := range []string{"", "tt1", "2026-000001", "tt1-26-000001", "tt1-2026-", "tt1-2026-000000", "tt1-20x6-000001", "u1-tt1-PRODUCT-m1-0"} {
+		_, _, _, err := ParseReceiptNumber(number)
+		require.Error(t, err, number)
+	}
+}
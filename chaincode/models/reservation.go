@@ -0,0 +1,26 @@
+package models
+
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "ACTIVE"
+	ReservationConfirmed ReservationStatus = "CONFIRMED"
+	ReservationReleased  ReservationStatus = "RELEASED"
+	ReservationExpired   ReservationStatus = "EXPIRED"
+)
+
+// Reservation holds units of a product for a user until it's confirmed,
+// released or it expires. Held units are moved from the product's quantity
+// to its reserved quantity.
+type Reservation struct {
+	ID        string            `json:"id"`
+	ProductID string            `json:"product_id"`
+	UserID    string            `json:"user_id"`
+	Quantity  uint              `json:"quantity"`
+	ExpiresAt string            `json:"expires_at"`
+	Status    ReservationStatus `json:"status"`
+}
+
+func (r Reservation) GetID() string {
+	return r.ID
+}
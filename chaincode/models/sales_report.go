@@ -0,0 +1,40 @@
+package models
+
+// ReportDateLayout is the layout of the dates of reports and statements.
+const ReportDateLayout = "2006-01-02"
+
+type ProductSales struct {
+	ProductID string `json:"product_id"`
+	Units     uint   `json:"units"`
+	Revenue   Money  `json:"revenue"`
+}
+
+type CustomerSales struct {
+	UserID   string `json:"user_id"`
+	Receipts int    `json:"receipts"`
+	Spent    Money  `json:"spent"`
+}
+
+type DailySales struct {
+	Date    string `json:"date"`
+	Units   uint   `json:"units"`
+	Revenue Money  `json:"revenue"`
+	Refunds Money  `json:"refunds"`
+}
+
+// SalesReport sums up the trader's receipts dated within From and To, both
+// inclusive. Refunds are counted on the day they were made, so they may
+// belong to sales from before the period.
+type SalesReport struct {
+	TraderID     string           `json:"trader_id"`
+	From         string           `json:"from"`
+	To           string           `json:"to"`
+	Receipts     int              `json:"receipts"`
+	Units        uint             `json:"units"`
+	Revenue      Money            `json:"revenue"`
+	Refunds      Money            `json:"refunds"`
+	NetRevenue   Money            `json:"net_revenue"`
+	Products     []*ProductSales  `json:"products"`
+	TopCustomers []*CustomerSales `json:"top_customers"`
+	Daily        []*DailySales    `json:"daily"`
+}
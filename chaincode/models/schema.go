@@ -0,0 +1,158 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SCHEMA_VERSION_FIELD is the field of every stored document that holds the
+// version of the layout the document was written with.
+const SCHEMA_VERSION_FIELD string = "schema_version"
+
+// Documents written before versioning don't have the field and are read as
+// the first version.
+const initialSchemaVersion = 1
+
+// Migration upgrades a stored document by one schema version. It edits the
+// top level fields of the document in place.
+type Migration func(document map[string]json.RawMessage) error
+
+// migrations holds the upgrade path of every entity type. The migration at
+// index i upgrades documents from version i+1 to version i+2, so the current
+// version of a type is one more than the number of its migrations.
+var migrations = map[string][]Migration{}
+
+// compositeKeyNamespace starts and separates the parts of composite keys.
+const compositeKeyNamespace = "\x00"
+
+// VersionedTypes are the entity types whose documents MigrateState upgrades.
+// Trader deltas are versioned too, but composite keys can't be range scanned:
+// they're upgraded as they're read, until the compaction folds them.
+var VersionedTypes = []string{
+	PRODUCT_TYPE, USER_TYPE, TRADER_TYPE, RECEIPT_TYPE, AUCTION_TYPE, BID_TYPE, HTLC_TYPE,
+	CLAIM_TYPE, EXCHANGE_RATE_TYPE, RESERVATION_TYPE, MOVEMENT_TYPE, CONFIG_TYPE, CATEGORY_TYPE, IDEMPOTENCY_TYPE, RECEIPT_SEQUENCE_TYPE,
+	JOURNAL_TYPE,
+}
+
+// MigrationPage reports one page of a MigrateState run.
+type MigrationPage struct {
+	EntityType    string `json:"entity_type"`
+	SchemaVersion int    `json:"schema_version"`
+	Scanned       int    `json:"scanned"`
+	Migrated      int    `json:"migrated"`
+	// Indexed is the number of index entries written for records stored
+	// before their indexes.
+	Indexed int    `json:"indexed"`
+	NextKey string `json:"next_key"`
+	Done    bool   `json:"done"`
+}
+
+// RegisterMigration appends the next step of the entity type's upgrade path
+// and so bumps its current schema version. Migrations are registered from
+// init functions, once a model changes its stored layout.
+func RegisterMigration(entityType string, migration Migration) {
+	migrations[entityType] = append(migrations[entityType], migration)
+}
+
+// SchemaVersion returns the version documents of the entity type are written
+// with.
+func SchemaVersion(entityType string) int {
+	return initialSchemaVersion + len(migrations[entityType])
+}
+
+// EntityType returns the type part of a world state key. The type of a
+// composite key, such as the key of a trader delta, is its object type.
+func EntityType(key string) string {
+	if objectType, ok := strings.CutPrefix(key, compositeKeyNamespace); ok {
+		objectType, _, _ = strings.Cut(objectType, compositeKeyNamespace)
+		return objectType
+	}
+
+	entityType, _, _ := strings.Cut(key, "-")
+	return entityType
+}
+
+// EncodeModel marshals the model stored under key and stamps it with the
+// current schema version of its type.
+func EncodeModel(key string, model any) ([]byte, error) {
+	modelJson, err := json.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(modelJson) < 2 || modelJson[0] != '{' {
+		return nil, fmt.Errorf("%s isn't stored as a json object", key)
+	}
+
+	version := fmt.Sprintf(`{"%s":%d`, SCHEMA_VERSION_FIELD, SchemaVersion(EntityType(key)))
+	if len(bytes.TrimSpace(modelJson[1:len(modelJson)-1])) > 0 {
+		version += ","
+	}
+
+	return append([]byte(version), modelJson[1:]...), nil
+}
+
+// DecodeModel upgrades the document stored under key to the current schema
+// version of its type and unmarshals it into model.
+func DecodeModel(key string, data []byte, model any) error {
+	upgraded, _, err := UpgradeDocument(key, data)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(upgraded, model)
+}
+
+// UpgradeDocument runs the migrations the document stored under key is
+// missing and stamps it with the current schema version. The flag reports
+// whether the document had to change.
+func UpgradeDocument(key string, data []byte) ([]byte, bool, error) {
+	var header struct {
+		SchemaVersion *int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, false, fmt.Errorf("failed to deserialize %s: %v", key, err)
+	}
+
+	entityType := EntityType(key)
+	current := SchemaVersion(entityType)
+
+	version := initialSchemaVersion
+	if header.SchemaVersion != nil {
+		version = *header.SchemaVersion
+	}
+
+	if version > current {
+		return nil, false, fmt.Errorf("%s has schema version %d but this chaincode only knows versions up to %d", key, version, current)
+	}
+
+	if version < initialSchemaVersion {
+		return nil, false, fmt.Errorf("%s has an invalid schema version %d", key, version)
+	}
+
+	if header.SchemaVersion != nil && version == current {
+		return data, false, nil
+	}
+
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, false, fmt.Errorf("failed to deserialize %s: %v", key, err)
+	}
+
+	for ; version < current; version++ {
+		if err := migrations[entityType][version-initialSchemaVersion](document); err != nil {
+			return nil, false, fmt.Errorf("failed to migrate %s to schema version %d: %v", key, version+1, err)
+		}
+	}
+	document[SCHEMA_VERSION_FIELD] = json.RawMessage(strconv.Itoa(current))
+
+	upgraded, err := json.Marshal(document)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return upgraded, true, nil
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaVersioning(t *testing.T) {
+	t.Cleanup(func() { delete(migrations, CATEGORY_TYPE) })
+
+	stored, err := EncodeModel("CATEGORY-c1", Category{ID: "CATEGORY-c1", Name: "Bakery"})
+	require.NoError(t, err)
+	require.Contains(t, string(stored), `{"schema_version":1,"id":"CATEGORY-c1"`)
+
+	// version 2 renames the category's title to its name
+	RegisterMigration(CATEGORY_TYPE, func(document map[string]json.RawMessage) error {
+		if title, ok := document["title"]; ok {
+			document["name"] = title
+			delete(document, "title")
+		}
+		return nil
+	})
+	require.Equal(t, 2, SchemaVersion(CATEGORY_TYPE))
+	require.Equal(t, 1, SchemaVersion(CONFIG_TYPE))
+
+	var category Category
+	require.NoError(t, DecodeModel("CATEGORY-c1", []byte(`{"id":"CATEGORY-c1","title":"Bakery"}`), &category))
+	require.Equal(t, "Bakery", category.Name)
+
+	upgraded, changed, err := UpgradeDocument("CATEGORY-c1", []byte(`{"schema_version":1,"id":"CATEGORY-c1","title":"Bakery"}`))
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.JSONEq(t, `{"schema_version":2,"id":"CATEGORY-c1","name":"Bakery"}`, string(upgraded))
+
+	_, changed, err = UpgradeDocument("CATEGORY-c1", upgraded)
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	_, _, err = UpgradeDocument("CATEGORY-c1", []byte(`{"schema_version":3,"id":"CATEGORY-c1"}`))
+	require.Error(t, err)
+
+	require.Equal(t, TRADER_DELTA_TYPE, EntityType("\x00"+TRADER_DELTA_TYPE+"\x00TRADER-tt1\x00tt1-2026-000001\x00CREDIT\x00"))
+	require.Equal(t, JOURNAL_TYPE, EntityType(ToJournalID("RECEIPT-tt1-2026-000001")))
+}
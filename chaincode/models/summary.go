@@ -0,0 +1,19 @@
+package models
+
+// UserSummary is a read-only view of everything a user owns on one channel.
+type UserSummary struct {
+	Channel  string     `json:"channel"`
+	User     *User      `json:"user"`
+	Receipts []*Receipt `json:"receipts"`
+	Products []*Product `json:"products"`
+}
+
+// UserOverview joins the user's summaries from the local channel and every
+// remote channel that was queried.
+type UserOverview struct {
+	UserID         string         `json:"user_id"`
+	TotalBalance   Balances       `json:"total_balance"`
+	TotalLocked    Balances       `json:"total_locked"`
+	TotalReceipts  int            `json:"total_receipts"`
+	ChannelSummary []*UserSummary `json:"channels"`
+}
@@ -1,5 +1,13 @@
 package models
 
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// TraderType is the id of the trader's category. The constants are the
+// categories the ledger is initialized with, admins can register others.
 type TraderType string
 
 const (
@@ -8,15 +16,162 @@ const (
 	MotorcycleParts TraderType = "MOTOPARTS"
 )
 
+// VerificationStatus is where a trader is in onboarding. Only verified
+// traders can sell.
+type VerificationStatus string
+
+const (
+	VerificationPending   VerificationStatus = "PENDING"
+	VerificationVerified  VerificationStatus = "VERIFIED"
+	VerificationSuspended VerificationStatus = "SUSPENDED"
+)
+
+func ParseVerificationStatus(value string) (VerificationStatus, error) {
+	status := VerificationStatus(value)
+	switch status {
+	case VerificationPending, VerificationVerified, VerificationSuspended:
+		return status, nil
+	default:
+		return "", fmt.Errorf("unknown verification status %s", value)
+	}
+}
+
+func init() {
+	// version 2 adds the verification status. Traders onboarded before it
+	// have to be verified by an admin before they can sell again.
+	RegisterMigration(TRADER_TYPE, func(document map[string]json.RawMessage) error {
+		if _, ok := document["verification_status"]; !ok {
+			document["verification_status"] = json.RawMessage(`"` + VerificationPending + `"`)
+		}
+		return nil
+	})
+
+	// version 3 converts the money stored before amounts had a currency.
+	// Traders upgraded to version 2 by MigrateState still had it.
+	RegisterMigration(TRADER_TYPE, moneyMigration(Trader{}))
+}
+
 type Trader struct {
-	ID             string     `json:"id"`
+	ID             string     `json:"id" validate:"required,max=64"`
 	TraderType     TraderType `json:"trader_type"`
-	PIB            string     `json:"pib"`
+	PIB            string     `json:"pib" validate:"required,pib"`
+	Currency       string     `json:"currency" validate:"currency"`
 	Products       []string   `json:"products"`
 	Receipts       []string   `json:"receipts"`
-	AccountBalance uint       `json:"account_balance"`
+	AccountBalance Money      `json:"account_balance"`
+	// OpeningBalance is the balance the trader was created with.
+	OpeningBalance Money `json:"opening_balance" metadata:",optional"`
+	// VerificationStatus is set by the admins once they checked the trader's
+	// registration.
+	VerificationStatus VerificationStatus `json:"verification_status" validate:"oneof=PENDING VERIFIED SUSPENDED" metadata:",optional"`
+	// OwnerMSP is the organization that owns the trader. Changes to the
+	// trader and its products need the endorsement of its peers and of the
+	// peers of the Endorsers.
+	OwnerMSP  string   `json:"owner_msp" metadata:",optional"`
+	Endorsers []string `json:"endorsers,omitempty" metadata:",optional"`
+
+	Lifecycle
 }
 
 func (p Trader) GetID() string {
 	return p.ID
 }
+
+// SettlementCurrency is the currency the trader is paid in. Traders stored
+// before currencies were introduced operate in DefaultCurrency.
+func (t Trader) SettlementCurrency() string {
+	if t.Currency == "" {
+		return DefaultCurrency
+	}
+
+	return t.Currency
+}
+
+// EndorsingOrgs are the organizations whose peers have to endorse changes
+// to the trader's assets, none for traders stored before they had owners.
+func (t Trader) EndorsingOrgs() []string {
+	if t.OwnerMSP == "" {
+		return nil
+	}
+
+	orgs := []string{t.OwnerMSP}
+	for _, org := range t.Endorsers {
+		if !slices.Contains(orgs, org) {
+			orgs = append(orgs, org)
+		}
+	}
+
+	return orgs
+}
+
+func (t Trader) IsVerified() bool {
+	return t.VerificationStatus == VerificationVerified
+}
+
+func (t *Trader) RemoveProduct(productId string) {
+	t.Products = slices.DeleteFunc(t.Products, func(id string) bool {
+		return id == productId
+	})
+}
+
+func (t *Trader) Credit(amount Money) error {
+	balance, err := t.AccountBalance.Add(amount)
+	if err != nil {
+		return err
+	}
+
+	t.AccountBalance = balance
+	return nil
+}
+
+func (t *Trader) Debit(amount Money) error {
+	balance, err := t.AccountBalance.Sub(amount)
+	if err != nil {
+		return err
+	}
+
+	t.AccountBalance = balance
+	return nil
+}
+
+// PIB_INDEX is the object type of the composite keys indexing the traders
+// by PIB. The value is the key of the trader registered with the PIB.
+const PIB_INDEX string = "PIB"
+
+// PIB_LENGTH is the number of digits of a Serbian tax identification number.
+const PIB_LENGTH = 9
+
+// ValidatePIB checks that the PIB has nine digits and that the last one is
+// the ISO 7064 MOD 11,10 check digit of the others.
+func ValidatePIB(pib string) error {
+	if len(pib) != PIB_LENGTH {
+		return fmt.Errorf("PIB %q must have %d digits", pib, PIB_LENGTH)
+	}
+
+	digits := make([]int, 0, PIB_LENGTH)
+	for _, c := range pib {
+		if c < '0' || c > '9' {
+			return fmt.Errorf("PIB %q must have only digits", pib)
+		}
+		digits = append(digits, int(c-'0'))
+	}
+
+	if pibCheckDigit(digits[:PIB_LENGTH-1]) != digits[PIB_LENGTH-1] {
+		return fmt.Errorf("PIB %q has an invalid check digit", pib)
+	}
+
+	return nil
+}
+
+func pibCheckDigit(digits []int) int {
+	product := 10
+	for _, digit := range digits {
+		sum := (product + digit) % 10
+		if sum == 0 {
+			sum = 10
+		}
+		product = (2 * sum) % 11
+	}
+
+	return (11 - product) % 10
+}
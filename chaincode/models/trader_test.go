@@ -0,0 +1,32 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePIB(t *testing.T) {
+	for _, pib := range []string{"100000008", "101561233", "123456788"} {
+		require.NoError(t, ValidatePIB(pib), pib)
+	}
+
+	for _, pib := range []string{"", "pib1", "10000000", "1000000080", "10000000a", "100000009", "123456789"} {
+		require.Error(t, ValidatePIB(pib), pib)
+	}
+}
+
+func TestTradersAreMigratedAsPending(t *testing.T) {
+	var trader Trader
+	require.NoError(t, DecodeModel("TRADER-tt1", []byte(`{"id":"TRADER-tt1","pib":"pib1"}`), &trader))
+	require.Equal(t, VerificationPending, trader.VerificationStatus)
+
+	stored, err := EncodeModel("TRADER-tt1", Trader{ID: "TRADER-tt1", VerificationStatus: VerificationVerified})
+	require.NoError(t, err)
+
+	var document map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(stored, &document))
+	require.JSONEq(t, "3", string(document[SCHEMA_VERSION_FIELD]))
+	require.JSONEq(t, `"VERIFIED"`, string(document["verification_status"]))
+}
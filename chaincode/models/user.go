@@ -1,14 +1,102 @@
 package models
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
+func init() {
+	// version 2 converts the money stored before amounts had a currency, and
+	// moves the single balance users had before balances were kept per
+	// currency into the balance of its currency.
+	RegisterMigration(USER_TYPE, func(document map[string]json.RawMessage) error {
+		if err := moneyMigration(User{})(document); err != nil {
+			return err
+		}
+
+		for _, legacy := range []struct{ from, to string }{{"account_balance", "balances"}, {"locked_balance", "locked_balances"}} {
+			value, ok := document[legacy.from]
+			if !ok {
+				continue
+			}
+			delete(document, legacy.from)
+
+			if current, ok := document[legacy.to]; ok && string(current) != "null" {
+				continue
+			}
+
+			upgraded, err := upgradeMoneyValue(moneyType, value)
+			if err != nil {
+				return fmt.Errorf("%s: %v", legacy.from, err)
+			}
+
+			var amount Money
+			if err := json.Unmarshal(upgraded, &amount); err != nil {
+				return fmt.Errorf("%s: %v", legacy.from, err)
+			}
+
+			var balances Balances
+			if err := balances.Credit(amount); err != nil {
+				return fmt.Errorf("%s: %v", legacy.from, err)
+			}
+
+			if document[legacy.to], err = json.Marshal(balances); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
 type User struct {
-	ID             string   `json:"id"`
-	Name           string   `json:"name"`
-	LastName       string   `json:"last_name"`
-	Email          string   `json:"email"`
+	ID             string   `json:"id" validate:"required,max=64"`
+	Name           string   `json:"name" validate:"required,max=100"`
+	LastName       string   `json:"last_name" validate:"required,max=100"`
+	Email          string   `json:"email" validate:"required,email"`
 	ReceiptsID     []string `json:"receipts_ids"`
-	AccountBalance uint     `json:"account_balance"`
+	Balances       Balances `json:"balances"`
+	LockedBalances Balances `json:"locked_balances" metadata:",optional"`
+	// Movements is the sequence of the next movement of the spendable
+	// balance. It starts after the journal entries of a purged user with the
+	// same id.
+	Movements uint64 `json:"movements" metadata:",optional"`
+	// Limits are set by the admins, Spending is counted against them.
+	Limits   SpendingLimits `json:"limits" metadata:",optional"`
+	Spending Spending       `json:"spending" metadata:",optional"`
+	// Freeze is set while an admin has frozen the account, which blocks
+	// every debit.
+	Freeze *AccountFreeze `json:"freeze,omitempty" metadata:",optional"`
+
+	Lifecycle
 }
 
 func (p User) GetID() string {
 	return p.ID
 }
+
+func (u *User) Credit(amount Money) error {
+	return u.Balances.Credit(amount)
+}
+
+func (u *User) Debit(amount Money) error {
+	return u.Balances.Debit(amount)
+}
+
+// Lock moves the amount from the spendable balance to the locked balance.
+func (u *User) Lock(amount Money) error {
+	if err := u.Balances.Debit(amount); err != nil {
+		return err
+	}
+
+	return u.LockedBalances.Credit(amount)
+}
+
+// Unlock moves the amount from the locked balance back to the spendable one.
+func (u *User) Unlock(amount Money) error {
+	if err := u.LockedBalances.Debit(amount); err != nil {
+		return err
+	}
+
+	return u.Balances.Credit(amount)
+}
@@ -0,0 +1,355 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// The validation rules of a model are declared in the validate tags of its
+// fields, such as `validate:"required,max=100"`. Rules other than required
+// only apply to values that are set. The rules are:
+//
+//	required     the value is set; strings must not be blank
+//	email        the string is an e-mail address
+//	min=N, max=N the number is within the bound, the length of a string is
+//	oneof=A B C  the value is one of the listed ones
+//	currency     the string is an ISO 4217 currency code
+//	pib          the string is a valid PIB
+//
+// Fields of nested structs and of slices of structs are validated too.
+const VALIDATE_TAG = "validate"
+
+// VALIDATION_ERROR_PREFIX starts the message of ValidationErrors, the JSON
+// list of the errors follows it.
+const VALIDATION_ERROR_PREFIX = "validation failed: "
+
+// Rule is one rule of a field, such as {Name: "max", Param: "100"}.
+type Rule struct {
+	Name  string `json:"name"`
+	Param string `json:"param,omitempty" metadata:",optional"`
+}
+
+// FieldRules are the rules of a field. Fields of nested structs are named by
+// their path, such as "price.amount" or "tiers[].unit_price.amount".
+type FieldRules struct {
+	Field string `json:"field"`
+	Rules []Rule `json:"rules"`
+}
+
+// ModelRules are the rules of the arguments of a type of model.
+type ModelRules struct {
+	Type   string       `json:"type"`
+	Fields []FieldRules `json:"fields"`
+}
+
+// FieldError is a broken rule. Fields in slices are named with their index,
+// such as "tiers[1].min_quantity".
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors are all the broken rules of a model. Its message carries
+// the errors as JSON, so clients can read them from the transaction's error.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	bytes, err := json.Marshal([]FieldError(e))
+	if err != nil {
+		return VALIDATION_ERROR_PREFIX + err.Error()
+	}
+
+	return VALIDATION_ERROR_PREFIX + string(bytes)
+}
+
+// validatedModels are the models transactions take as arguments.
+var validatedModels = []struct {
+	entityType string
+	model      any
+}{
+	{USER_TYPE, User{}},
+	{PRODUCT_TYPE, Product{}},
+	{TRADER_TYPE, Trader{}},
+	{CATEGORY_TYPE, Category{}},
+}
+
+// ValidationRules lists the rules of the models transactions take as
+// arguments.
+func ValidationRules() []ModelRules {
+	rules := make([]ModelRules, 0, len(validatedModels))
+	for _, validated := range validatedModels {
+		fields := make([]FieldRules, 0)
+		collectRules(reflect.TypeOf(validated.model), "", &fields)
+		rules = append(rules, ModelRules{Type: validated.entityType, Fields: fields})
+	}
+
+	return rules
+}
+
+// TypeRules are the rules of the fields of a struct type. The contract
+// metadata names the schema of a struct type after it, so the rules can be
+// added to the schema's properties.
+type TypeRules struct {
+	Type   string       `json:"type"`
+	Fields []FieldRules `json:"fields"`
+}
+
+// SchemaRules lists the rules of the struct types of the models transactions
+// take as arguments, the nested ones included. Each type is listed once, with
+// the fields named as in its own schema.
+func SchemaRules() []TypeRules {
+	rules := make([]TypeRules, 0)
+	seen := make(map[reflect.Type]bool)
+	for _, validated := range validatedModels {
+		collectTypeRules(reflect.TypeOf(validated.model), seen, &rules)
+	}
+
+	return rules
+}
+
+// Validate checks the model against the rules of its fields and returns
+// ValidationErrors with every broken rule, or nil.
+func Validate(model any) error {
+	errs := make(ValidationErrors, 0)
+	validateStruct(reflect.ValueOf(model), "", &errs)
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+func collectRules(structType reflect.Type, path string, fields *[]FieldRules) {
+	for structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.Anonymous || !field.IsExported() {
+			continue
+		}
+
+		name := path + fieldName(field)
+		if rules := parseRules(field.Tag.Get(VALIDATE_TAG)); len(rules) > 0 {
+			*fields = append(*fields, FieldRules{Field: name, Rules: rules})
+		}
+
+		switch elem := nestedStruct(field.Type); {
+		case elem == nil:
+		case field.Type.Kind() == reflect.Slice:
+			collectRules(elem, name+"[].", fields)
+		default:
+			collectRules(elem, name+".", fields)
+		}
+	}
+}
+
+func collectTypeRules(structType reflect.Type, seen map[reflect.Type]bool, rules *[]TypeRules) {
+	if seen[structType] {
+		return
+	}
+	seen[structType] = true
+
+	fields := make([]FieldRules, 0)
+	collectRules(structType, "", &fields)
+
+	direct := make([]FieldRules, 0, len(fields))
+	for _, field := range fields {
+		if !strings.ContainsAny(field.Field, ".[") {
+			direct = append(direct, field)
+		}
+	}
+	if len(direct) > 0 {
+		*rules = append(*rules, TypeRules{Type: structType.Name(), Fields: direct})
+	}
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.Anonymous || !field.IsExported() {
+			continue
+		}
+
+		if elem := nestedStruct(field.Type); elem != nil {
+			collectTypeRules(elem, seen, rules)
+		}
+	}
+}
+
+func validateStruct(value reflect.Value, path string, errs *ValidationErrors) {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+
+	structType := value.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.Anonymous || !field.IsExported() {
+			continue
+		}
+
+		name := path + fieldName(field)
+		fieldValue := value.Field(i)
+
+		for _, rule := range parseRules(field.Tag.Get(VALIDATE_TAG)) {
+			if message := checkRule(rule, fieldValue); message != "" {
+				*errs = append(*errs, FieldError{Field: name, Rule: rule.Name, Message: message})
+			}
+		}
+
+		switch elem := nestedStruct(field.Type); {
+		case elem == nil:
+		case field.Type.Kind() == reflect.Slice:
+			for j := 0; j < fieldValue.Len(); j++ {
+				validateStruct(fieldValue.Index(j), fmt.Sprintf("%s[%d].", name, j), errs)
+			}
+		default:
+			validateStruct(fieldValue, name+".", errs)
+		}
+	}
+}
+
+// nestedStruct returns the struct type of a struct field or of the elements
+// of a slice field, whose fields are validated too.
+func nestedStruct(fieldType reflect.Type) reflect.Type {
+	if fieldType.Kind() == reflect.Slice {
+		fieldType = fieldType.Elem()
+	}
+
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	if fieldType.Kind() != reflect.Struct || fieldType == reflect.TypeOf(time.Time{}) {
+		return nil
+	}
+
+	return fieldType
+}
+
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
+
+func parseRules(tag string) []Rule {
+	rules := make([]Rule, 0)
+	for _, part := range strings.Split(tag, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+
+		name, param, _ := strings.Cut(part, "=")
+		rules = append(rules, Rule{Name: name, Param: param})
+	}
+
+	return rules
+}
+
+// checkRule returns why the value breaks the rule, or an empty string.
+func checkRule(rule Rule, value reflect.Value) string {
+	if rule.Name == "required" {
+		if isBlank(value) {
+			return "is required"
+		}
+		return ""
+	}
+
+	if value.IsZero() {
+		return ""
+	}
+
+	switch rule.Name {
+	case "email":
+		address, err := mail.ParseAddress(value.String())
+		if err != nil || address.Address != value.String() {
+			return "must be an e-mail address"
+		}
+	case "min", "max":
+		return checkBound(rule, value)
+	case "oneof":
+		if !slices.Contains(strings.Fields(rule.Param), fmt.Sprint(value.Interface())) {
+			return fmt.Sprintf("must be one of %s", strings.Join(strings.Fields(rule.Param), ", "))
+		}
+	case "currency":
+		if !currencyPattern.MatchString(value.String()) {
+			return "must be an ISO 4217 currency code"
+		}
+	case "pib":
+		if err := ValidatePIB(value.String()); err != nil {
+			return err.Error()
+		}
+	default:
+		return fmt.Sprintf("unknown rule %s", rule.Name)
+	}
+
+	return ""
+}
+
+// isBlank reports whether a required value is missing. Money and the other
+// types with an IsZero method are missing when they're zero.
+func isBlank(value reflect.Value) bool {
+	if zero, ok := value.Interface().(interface{ IsZero() bool }); ok {
+		return zero.IsZero()
+	}
+
+	if value.Kind() == reflect.String {
+		return strings.TrimSpace(value.String()) == ""
+	}
+
+	return value.IsZero()
+}
+
+func checkBound(rule Rule, value reflect.Value) string {
+	bound, err := strconv.ParseInt(rule.Param, 10, 64)
+	if err != nil {
+		return fmt.Sprintf("invalid bound %q", rule.Param)
+	}
+
+	var actual int64
+	unit := ""
+	switch value.Kind() {
+	case reflect.String:
+		actual = int64(utf8.RuneCountInString(value.String()))
+		unit = " characters"
+	case reflect.Slice, reflect.Map:
+		actual = int64(value.Len())
+		unit = " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = value.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value.Uint() > uint64(1<<63-1) {
+			actual = 1<<63 - 1
+		} else {
+			actual = int64(value.Uint())
+		}
+	default:
+		return fmt.Sprintf("%s doesn't apply to %s", rule.Name, value.Kind())
+	}
+
+	if rule.Name == "min" && actual < bound {
+		return fmt.Sprintf("must be at least %d%s", bound, unit)
+	}
+
+	if rule.Name == "max" && actual > bound {
+		return fmt.Sprintf("must be at most %d%s", bound, unit)
+	}
+
+	return ""
+}
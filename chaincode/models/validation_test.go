@@ -0,0 +1,66 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Validate(User{ID: "u1", Name: "Ana", LastName: "Jovanović", Email: "ana@example.com"}))
+
+	err := Validate(User{ID: "u1", Name: "  ", Email: "ana@"})
+	var errs ValidationErrors
+	require.True(t, errors.As(err, &errs))
+	require.Equal(t, ValidationErrors{
+		{Field: "name", Rule: "required", Message: "is required"},
+		{Field: "last_name", Rule: "required", Message: "is required"},
+		{Field: "email", Rule: "email", Message: "must be an e-mail address"},
+	}, errs)
+	require.Contains(t, err.Error(), VALIDATION_ERROR_PREFIX+`[{"field":"name"`)
+
+	product := Product{
+		ID:       "m1",
+		Name:     "Milk",
+		Price:    NewMoney(0, "RSD"),
+		Tiers:    []PriceTier{{MinQuantity: 1, UnitPrice: NewMoney(-5, "rsd")}},
+		TraderID: "tt1",
+	}
+	require.Equal(t, ValidationErrors{
+		{Field: "price", Rule: "required", Message: "is required"},
+		{Field: "tiers[0].min_quantity", Rule: "min", Message: "must be at least 2"},
+		{Field: "tiers[0].unit_price.amount", Rule: "min", Message: "must be at least 0"},
+		{Field: "tiers[0].unit_price.currency", Rule: "currency", Message: "must be an ISO 4217 currency code"},
+	}, Validate(&product))
+
+	err = Validate(Trader{ID: "tt1", PIB: "100000009", VerificationStatus: "APPROVED"})
+	require.ErrorContains(t, err, `"field":"pib"`)
+	require.ErrorContains(t, err, "must be one of PENDING, VERIFIED, SUSPENDED")
+}
+
+func TestValidationRules(t *testing.T) {
+	rules := ValidationRules()
+	require.Len(t, rules, len(validatedModels))
+	require.Equal(t, USER_TYPE, rules[0].Type)
+	require.Contains(t, rules[0].Fields, FieldRules{Field: "email", Rules: []Rule{{Name: "required"}, {Name: "email"}}})
+
+	require.Equal(t, PRODUCT_TYPE, rules[1].Type)
+	require.Contains(t, rules[1].Fields, FieldRules{Field: "name", Rules: []Rule{{Name: "required"}, {Name: "max", Param: "200"}}})
+	require.Contains(t, rules[1].Fields, FieldRules{Field: "tiers[].unit_price.amount", Rules: []Rule{{Name: "min", Param: "0"}}})
+}
+
+func TestSchemaRules(t *testing.T) {
+	rules := SchemaRules()
+
+	types := make([]string, 0, len(rules))
+	for _, typeRules := range rules {
+		types = append(types, typeRules.Type)
+	}
+	require.Equal(t, []string{"User", "Product", "Money", "PriceTier", "Trader", "Category"}, types)
+
+	require.Contains(t, rules[2].Fields, FieldRules{Field: "amount", Rules: []Rule{{Name: "min", Param: "0"}}})
+	for _, field := range rules[1].Fields {
+		require.NotContains(t, field.Field, ".")
+	}
+}
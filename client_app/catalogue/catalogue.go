@@ -0,0 +1,191 @@
+// Package catalogue reads product catalogues uploaded as CSV or JSON and
+// splits them into batches for the CreateProducts and UpdateProducts
+// transactions.
+package catalogue
+
+import (
+	"clientapp/models"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// MaxBatchItems is the most products the chaincode accepts in one batch.
+const MaxBatchItems = 500
+
+// MaxBatchBytes keeps the arguments of a batch transaction well below the
+// size of a block.
+const MaxBatchBytes = 512 * 1024
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// FormatOf tells the format from the content type of the upload, or from
+// the extension of its file name.
+func FormatOf(contentType string, fileName string) (Format, error) {
+	switch {
+	case strings.Contains(contentType, "csv"), strings.HasSuffix(strings.ToLower(fileName), ".csv"):
+		return FormatCSV, nil
+	case strings.Contains(contentType, "json"), strings.HasSuffix(strings.ToLower(fileName), ".json"):
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("catalogues must be uploaded as CSV or JSON")
+	}
+}
+
+// Parse reads the catalogue. JSON catalogues are an array of products. CSV
+// catalogues have a header row naming their columns: id, name, price and
+// currency are required, expiration_date, quantity, trader_id, category,
+// tiers and attributes are optional. Prices are decimal amounts in major
+// units, tiers and attributes hold JSON.
+func Parse(format Format, reader io.Reader) ([]models.Product, error) {
+	switch format {
+	case FormatJSON:
+		var products []models.Product
+		if err := json.NewDecoder(reader).Decode(&products); err != nil {
+			return nil, fmt.Errorf("invalid JSON catalogue: %v", err)
+		}
+
+		// the chaincode's schema doesn't take null lists
+		for i := range products {
+			if products[i].Tiers == nil {
+				products[i].Tiers = []models.PriceTier{}
+			}
+			if products[i].Reservations == nil {
+				products[i].Reservations = []string{}
+			}
+		}
+		return products, nil
+	case FormatCSV:
+		return parseCSV(reader)
+	default:
+		return nil, fmt.Errorf("unknown catalogue format %s", format)
+	}
+}
+
+var requiredColumns = []string{"id", "name", "price", "currency"}
+
+func parseCSV(reader io.Reader) ([]models.Product, error) {
+	rows, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV catalogue: %v", err)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("the CSV catalogue has no header row")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range requiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("the CSV catalogue has no %s column", name)
+		}
+	}
+
+	products := make([]models.Product, 0, len(rows)-1)
+	for i, row := range rows[1:] {
+		product, err := parseRow(columns, row)
+		if err != nil {
+			// the header is line 1
+			return nil, fmt.Errorf("line %d: %v", i+2, err)
+		}
+		products = append(products, product)
+	}
+
+	return products, nil
+}
+
+func parseRow(columns map[string]int, row []string) (models.Product, error) {
+	value := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	price, err := models.ParseMoney(value("price"), strings.ToUpper(value("currency")))
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	product := models.Product{
+		ID:             value("id"),
+		Name:           value("name"),
+		ExpirationDate: value("expiration_date"),
+		Price:          price,
+		Tiers:          []models.PriceTier{},
+		Reservations:   []string{},
+		TraderID:       value("trader_id"),
+		Category:       value("category"),
+	}
+
+	if quantity := value("quantity"); quantity != "" {
+		parsed, err := strconv.ParseUint(quantity, 10, 32)
+		if err != nil {
+			return models.Product{}, fmt.Errorf("invalid quantity %q", quantity)
+		}
+		product.Quantity = uint(parsed)
+	}
+
+	if tiers := value("tiers"); tiers != "" {
+		if err := json.Unmarshal([]byte(tiers), &product.Tiers); err != nil {
+			return models.Product{}, fmt.Errorf("invalid tiers: %v", err)
+		}
+	}
+
+	if attributes := value("attributes"); attributes != "" {
+		if err := json.Unmarshal([]byte(attributes), &product.Attributes); err != nil {
+			return models.Product{}, fmt.Errorf("invalid attributes: %v", err)
+		}
+	}
+
+	return product, nil
+}
+
+// Chunk splits the products into batches of at most maxItems products whose
+// JSON stays below maxBytes.
+func Chunk(products []models.Product, maxItems int, maxBytes int) ([][]models.Product, error) {
+	batches := make([][]models.Product, 0)
+	batch := make([]models.Product, 0)
+	// the brackets of the array
+	size := 2
+
+	for _, product := range products {
+		productJson, err := json.Marshal(product)
+		if err != nil {
+			return nil, err
+		}
+
+		// and a comma between items
+		itemSize := len(productJson) + 1
+		if itemSize+2 > maxBytes {
+			return nil, fmt.Errorf("product %s alone is larger than a batch", product.ID)
+		}
+
+		if len(batch) == maxItems || size+itemSize > maxBytes {
+			batches = append(batches, batch)
+			batch = make([]models.Product, 0)
+			size = 2
+		}
+
+		batch = append(batch, product)
+		size += itemSize
+	}
+
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches, nil
+}
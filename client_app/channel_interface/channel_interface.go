@@ -11,6 +11,13 @@ type ChannelInterace struct {
 	Gateway  *gateway.Gateway
 	Contract *gateway.Contract
 	Network  *gateway.Network
+
+	// The namespaced contracts of the chaincode, Contract is its default
+	// contract.
+	Users    *gateway.Contract
+	Products *gateway.Contract
+	Traders  *gateway.Contract
+	Receipts *gateway.Contract
 }
 
 func New(channel string, chainCodeId string, userID string, organization string) (*ChannelInterace, error) {
@@ -37,12 +44,20 @@ func New(channel string, chainCodeId string, userID string, organization string)
 		Gateway:  gateway,
 		Contract: contract,
 		Network:  network,
+		Users:    network.GetContractWithName(chainCodeId, "UserContract"),
+		Products: network.GetContractWithName(chainCodeId, "ProductContract"),
+		Traders:  network.GetContractWithName(chainCodeId, "TraderContract"),
+		Receipts: network.GetContractWithName(chainCodeId, "ReceiptContract"),
 	}, nil
 }
 
 func (ci *ChannelInterace) Close() {
 	ci.Gateway.Close()
 	ci.Contract = nil
+	ci.Users = nil
+	ci.Products = nil
+	ci.Traders = nil
+	ci.Receipts = nil
 	ci.Wallet = nil
 	ci.Network = nil
 }
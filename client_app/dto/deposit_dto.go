@@ -0,0 +1,7 @@
+package dto
+
+import "clientapp/models"
+
+type DepositDto struct {
+	Amount models.Money `json:"amount" binding:"required"`
+}
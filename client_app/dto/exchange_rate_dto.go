@@ -0,0 +1,10 @@
+package dto
+
+type ExchangeRateDto struct {
+	Base  string `json:"base" binding:"required"`
+	Quote string `json:"quote" binding:"required"`
+	// Rate is the decimal price of one unit of Base in Quote, e.g. "117.2".
+	Rate string `json:"rate" binding:"required"`
+	// MaxAge is the number of seconds the rate may be used for.
+	MaxAge int64 `json:"max_age" binding:"required"`
+}
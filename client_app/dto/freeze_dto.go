@@ -0,0 +1,5 @@
+package dto
+
+type FreezeDto struct {
+	Reason string `json:"reason" binding:"required"`
+}
@@ -0,0 +1,5 @@
+package dto
+
+type ReservationDto struct {
+	Quantity uint `json:"quantity" binding:"required"`
+}
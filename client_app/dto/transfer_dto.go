@@ -0,0 +1,8 @@
+package dto
+
+import "clientapp/models"
+
+type TransferDto struct {
+	RecipientID string       `json:"recipient_id"`
+	Amount      models.Money `json:"amount" binding:"required"`
+}
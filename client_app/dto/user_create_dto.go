@@ -1,9 +1,11 @@
 package dto
 
+import "clientapp/models"
+
 type UserCreateDto struct {
-	ID             string `json:"id"`
-	Name           string `json:"name"`
-	LastName       string `json:"last_name"`
-	Email          string `json:"email"`
-	AccountBalance uint   `json:"account_balance"`
+	ID       string          `json:"id"`
+	Name     string          `json:"name"`
+	LastName string          `json:"last_name"`
+	Email    string          `json:"email"`
+	Balances models.Balances `json:"balances"`
 }
@@ -0,0 +1,44 @@
+package export
+
+import (
+	"bytes"
+	"clientapp/models"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+)
+
+// decimal formats the amount in major units without the currency.
+func decimal(m models.Money) string {
+	return fmt.Sprintf("%d.%02d", m.Amount/100, m.Amount%100)
+}
+
+// SalesReportCSV writes the report as one table. The section column tells
+// the totals, products, customers and days apart.
+func SalesReportCSV(report *models.SalesReport) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	rows := [][]string{
+		{"section", "key", "receipts", "units", "revenue", "refunds", "currency"},
+		{"total", report.From + "/" + report.To, strconv.Itoa(report.Receipts), strconv.FormatUint(uint64(report.Units), 10), decimal(report.Revenue), decimal(report.Refunds), report.Revenue.Currency},
+	}
+
+	for _, product := range report.Products {
+		rows = append(rows, []string{"product", product.ProductID, "", strconv.FormatUint(uint64(product.Units), 10), decimal(product.Revenue), "", product.Revenue.Currency})
+	}
+
+	for _, customer := range report.TopCustomers {
+		rows = append(rows, []string{"customer", customer.UserID, strconv.Itoa(customer.Receipts), "", decimal(customer.Spent), "", customer.Spent.Currency})
+	}
+
+	for _, day := range report.Daily {
+		rows = append(rows, []string{"day", day.Date, "", strconv.FormatUint(uint64(day.Units), 10), decimal(day.Revenue), decimal(day.Refunds), report.Revenue.Currency})
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
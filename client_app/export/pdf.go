@@ -0,0 +1,88 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	pdfLinesPerPage = 38
+	pdfFontSize     = 9
+	pdfLineHeight   = 12
+	pdfPageHeight   = 595
+	pdfPageWidth    = 842
+	pdfMargin       = 40
+)
+
+// pdfEscape escapes the characters with a meaning inside PDF strings and
+// replaces the ones the standard Courier font can't show.
+func pdfEscape(text string) string {
+	var escaped strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			escaped.WriteRune('\\')
+			escaped.WriteRune(r)
+		case r < 32 || r > 126:
+			escaped.WriteRune('?')
+		default:
+			escaped.WriteRune(r)
+		}
+	}
+
+	return escaped.String()
+}
+
+// TextPDF lays the lines out in a monospaced font on as many landscape A4
+// pages as needed, with the title on top of every page.
+func TextPDF(title string, lines []string) []byte {
+	pages := make([][]string, 0)
+	for start := 0; start < len(lines) || start == 0; start += pdfLinesPerPage {
+		end := min(start+pdfLinesPerPage, len(lines))
+		pages = append(pages, lines[start:end])
+	}
+
+	// objects 1 and 2 are the catalog and the page tree, 3 is the font and
+	// every page takes two objects, the page and its content stream
+	objects := make([]string, 3+2*len(pages))
+	kids := make([]string, 0, len(pages))
+
+	for i, page := range pages {
+		pageObject := 4 + 2*i
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageObject))
+
+		var content strings.Builder
+		fmt.Fprintf(&content, "BT /F1 %d Tf %d TL %d %d Td\n", pdfFontSize, pdfLineHeight, pdfMargin, pdfPageHeight-pdfMargin)
+		fmt.Fprintf(&content, "(%s - page %d of %d) Tj T* T*\n", pdfEscape(title), i+1, len(pages))
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) Tj T*\n", pdfEscape(line))
+		}
+		content.WriteString("ET")
+
+		objects[pageObject-1] = fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", pdfPageWidth, pdfPageHeight, pageObject+1)
+		objects[pageObject] = fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String())
+	}
+
+	objects[0] = "<< /Type /Catalog /Pages 2 0 R >>"
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+	objects[2] = "<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>"
+
+	var buffer bytes.Buffer
+	buffer.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buffer.Len()
+		fmt.Fprintf(&buffer, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buffer.Len()
+	fmt.Fprintf(&buffer, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buffer, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buffer, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buffer.Bytes()
+}
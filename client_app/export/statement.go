@@ -0,0 +1,60 @@
+package export
+
+import (
+	"bytes"
+	"clientapp/models"
+	"encoding/csv"
+	"fmt"
+)
+
+// signed returns the debit and credit columns of the movement.
+func signed(movement *models.Movement) (string, string) {
+	if movement.Direction == "CREDIT" {
+		return "", decimal(movement.Amount)
+	}
+
+	return decimal(movement.Amount), ""
+}
+
+func StatementCSV(statement *models.Statement) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	rows := [][]string{
+		{"timestamp", "kind", "reference", "debit", "credit", "balance", "currency"},
+		{statement.From, "OPENING_BALANCE", "", "", "", decimal(statement.Opening), statement.Currency},
+	}
+
+	for _, entry := range statement.Entries {
+		debit, credit := signed(entry)
+		rows = append(rows, []string{entry.Timestamp, entry.Kind, entry.Reference, debit, credit, decimal(entry.Balance), statement.Currency})
+	}
+
+	rows = append(rows, []string{statement.To, "CLOSING_BALANCE", "", "", "", decimal(statement.Closing), statement.Currency})
+
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func StatementPDF(statement *models.Statement) []byte {
+	row := "%-20s  %-16s  %-28s  %10s  %10s  %10s"
+	lines := []string{
+		fmt.Sprintf("User: %s", statement.UserID),
+		fmt.Sprintf("Period: %s - %s", statement.From, statement.To),
+		fmt.Sprintf("Opening balance: %s", statement.Opening),
+		"",
+		fmt.Sprintf(row, "Time", "Kind", "Reference", "Debit", "Credit", "Balance"),
+	}
+
+	for _, entry := range statement.Entries {
+		debit, credit := signed(entry)
+		lines = append(lines, fmt.Sprintf(row, entry.Timestamp, entry.Kind, entry.Reference, debit, credit, decimal(entry.Balance)))
+	}
+
+	lines = append(lines, "", fmt.Sprintf("Closing balance: %s", statement.Closing))
+
+	return TextPDF(fmt.Sprintf("Statement in %s", statement.Currency), lines)
+}
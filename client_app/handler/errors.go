@@ -11,3 +11,4 @@ var failedToCreatePopulateWalletError = gin.H{"status": "internal server error -
 var failedToConnectGateway = gin.H{"status": "internal server error - failed to connect gateway"}
 var failedToGetGatewayNetwork = gin.H{"status": "internal server error - failed to get the gateway network"}
 var failedToSubmitTx = gin.H{"status": "internal server error - failed to submit tx"}
+var failedToEvaluateTx = gin.H{"status": "internal server error - failed to evaluate tx"}
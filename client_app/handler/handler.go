@@ -1,14 +1,25 @@
 package handler
 
 import (
+	"bytes"
+	"clientapp/catalogue"
 	channelinterface "clientapp/channel_interface"
 	"clientapp/data"
 	"clientapp/dto"
+	"clientapp/export"
+	"clientapp/htlc"
 	"clientapp/jwt"
 	"clientapp/models"
+	"clientapp/snapshot"
+	"clientapp/validation"
 	"encoding/json"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -105,9 +116,14 @@ func (h *Handler) InitLedger(ctx *gin.Context) {
 	if !ok {
 		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
 	}
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
 	log.Println("[HANDLER] [SUBMIT TX] InitLedger")
 
-	if _, err := chi.Contract.SubmitTransaction("InitLedger"); err != nil {
+	if _, err := submitIdempotent(chi.Contract, key, "InitLedger"); err != nil {
 		ctx.JSON(http.StatusInternalServerError, failedToSubmitTx)
 		return
 	}
@@ -139,7 +155,7 @@ func (h *Handler) GetAllProducts(ctx *gin.Context) {
 	}
 
 	log.Println("[HANDLER] [SUBMIT TX] GetAllProducts")
-	response, err := chi.Contract.SubmitTransaction("GetAllProducts")
+	response, err := chi.Products.SubmitTransaction("GetAllProducts")
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, failedToSubmitTx)
@@ -193,6 +209,24 @@ func (h *Handler) AddUser(ctx *gin.Context) {
 		return
 	}
 
+	newUser := models.User{
+		ID:         user.ID,
+		Name:       user.Name,
+		LastName:   user.LastName,
+		Email:      user.Email,
+		Balances:   user.Balances,
+		ReceiptsID: make([]string, 0),
+	}
+
+	if !validateModel(ctx, chi, models.USER_TYPE, newUser) {
+		return
+	}
+
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
 	newUserInfo := models.UserInfo{
 		UserID:            user.ID,
 		Organization:      adminUserInfo.Organization,
@@ -203,21 +237,13 @@ func (h *Handler) AddUser(ctx *gin.Context) {
 	h.users[user.ID] = &newUserInfo
 	log.Println("[HANDLER] [SUBMIT TX] CreateUser")
 
-	newUser := models.User{
-		ID:             user.ID,
-		Name:           user.Name,
-		LastName:       user.LastName,
-		Email:          user.Email,
-		AccountBalance: user.AccountBalance,
-		ReceiptsID:     make([]string, 0),
-	}
-
 	newUserBytes, _ := json.Marshal(newUser)
-	_, err := chi.Contract.SubmitTransaction("CreateUser", string(newUserBytes))
+	_, err := submitIdempotent(chi.Users, key, "CreateUser", string(newUserBytes))
 
 	if err != nil {
 		log.Println("[ERROR]", err)
-		ctx.JSON(http.StatusInternalServerError, failedToSubmitTx)
+		delete(h.users, user.ID)
+		respondSubmitError(ctx, err)
 		return
 	}
 
@@ -253,15 +279,1834 @@ func (h *Handler) BuyProduct(ctx *gin.Context) {
 		return
 	}
 
-	log.Println("[HANDLER] [SUBMIT TX] BuyProduct")
-	_, err := chi.Contract.SubmitTransaction("BuyProduct", product_id, user_id)
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
+	// the price is converted when the user pays in another currency
+	var err error
+	currency := ctx.Query("currency")
+	if quantity := ctx.Query("quantity"); quantity != "" {
+		if _, parseErr := strconv.ParseUint(quantity, 10, 32); parseErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "invalid quantity"})
+			return
+		}
+
+		log.Println("[HANDLER] [SUBMIT TX] BuyProductQuantity")
+		_, err = submitIdempotent(chi.Products, key, "BuyProductQuantity", product_id, user_id, quantity, currency)
+	} else if currency != "" {
+		log.Println("[HANDLER] [SUBMIT TX] BuyProductWithCurrency")
+		_, err = submitIdempotent(chi.Products, key, "BuyProductWithCurrency", product_id, user_id, currency)
+	} else {
+		log.Println("[HANDLER] [SUBMIT TX] BuyProduct")
+		_, err = submitIdempotent(chi.Products, key, "BuyProduct", product_id, user_id)
+	}
+
+	if err != nil {
+		log.Println("[ERROR]", err)
+		respondSubmitError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+
+}
+
+// GetUserOverview asks the chaincode on one channel for the user's overview,
+// which reads the remaining installed channels through cross-channel queries.
+func (h *Handler) GetUserOverview(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	user_id := userIdEntry.(string)
+	userInfo := h.users[user_id]
+
+	channels := make([]string, 0, len(h.installedChainCode))
+	for channel := range h.installedChainCode {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+
+	var chi *channelinterface.ChannelInterace
+	for _, channel := range channels {
+		if chi = userInfo.ChannelInterfaces[channel]; chi != nil {
+			break
+		}
+	}
+
+	if chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	remotes, _ := json.Marshal(h.installedChainCode)
+
+	log.Println("[HANDLER] [EVALUATE TX] GetUserOverview")
+	response, err := chi.Contract.EvaluateTransaction("GetUserOverview", user_id, string(remotes))
+	if err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToEvaluateTx)
+		return
+	}
+
+	var overview models.UserOverview
+	if err := json.Unmarshal(response, &overview); err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed to read the chain response"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": overview})
+}
+
+func (h *Handler) TransferFunds(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	user_id := userIdEntry.(string)
+	userInfo := h.users[user_id]
+
+	from := ctx.Param("from")
+	to := ctx.Param("to")
+	if from == "" || to == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	if from == to {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - channels must differ"})
+		return
+	}
+
+	source, sourceOk := userInfo.ChannelInterfaces[from]
+	target, targetOk := userInfo.ChannelInterfaces[to]
+	if !sourceOk || !targetOk || source == nil || target == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	var transferDto dto.TransferDto
+	if err := ctx.ShouldBindJSON(&transferDto); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "couldn't resolve body"})
+		return
+	}
+
+	if transferDto.RecipientID == "" {
+		transferDto.RecipientID = user_id
+	}
+
+	coordinator := htlc.Coordinator{
+		Source:          source,
+		SourceChannel:   from,
+		SourceChaincode: h.installedChainCode[from],
+		Target:          target,
+		TargetChannel:   to,
+		TargetChaincode: h.installedChainCode[to],
+	}
+
+	transfer, err := coordinator.Transfer(user_id, transferDto.RecipientID, transferDto.Amount)
+	if err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "transfer didn't complete", "data": transfer})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": transfer})
+}
+
+func (h *Handler) RefundTransfer(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	user_id := userIdEntry.(string)
+	userInfo := h.users[user_id]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	lockId := ctx.Param("lock_id")
+	if lockId == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "missing lock_id"})
+		return
+	}
+
+	chi, ok := userInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	coordinator := htlc.Coordinator{Source: chi, SourceChannel: channel, SourceChaincode: h.installedChainCode[channel]}
+	if err := coordinator.Refund(lockId); err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToSubmitTx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+func (h *Handler) SetExchangeRate(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	var rate dto.ExchangeRateDto
+	if err := ctx.ShouldBindJSON(&rate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "couldn't resolve body"})
+		return
+	}
+
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
+	log.Println("[HANDLER] [SUBMIT TX] SetExchangeRate")
+	_, err := submitIdempotent(chi.Contract, key, "SetExchangeRate", rate.Base, rate.Quote, rate.Rate, strconv.FormatInt(rate.MaxAge, 10))
+	if err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToSubmitTx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "exchange rate set"})
+}
+
+func (h *Handler) ReserveProduct(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	user_id := userIdEntry.(string)
+	userInfo := h.users[user_id]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	product_id := ctx.Param("product_id")
+	if product_id == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "missing product_id"})
+		return
+	}
+
+	chi, ok := userInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	var reservation dto.ReservationDto
+	if err := ctx.ShouldBindJSON(&reservation); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "couldn't resolve body"})
+		return
+	}
+
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
+	// a retried request reserves under the same id
+	reservationId := idFromKey(key)
 
+	log.Println("[HANDLER] [SUBMIT TX] ReserveProduct")
+	_, err := submitIdempotent(chi.Contract, key, "ReserveProduct", reservationId, product_id, user_id, strconv.FormatUint(uint64(reservation.Quantity), 10))
 	if err != nil {
 		log.Println("[ERROR]", err)
 		ctx.JSON(http.StatusInternalServerError, failedToSubmitTx)
 		return
 	}
 
+	ctx.JSON(http.StatusOK, gin.H{"data": gin.H{"reservation_id": reservationId}})
+}
+
+func (h *Handler) ConfirmReservation(ctx *gin.Context) {
+	h.submitReservationTx(ctx, "ConfirmReservation")
+}
+
+func (h *Handler) ReleaseReservation(ctx *gin.Context) {
+	h.submitReservationTx(ctx, "ReleaseReservation")
+}
+
+func (h *Handler) submitReservationTx(ctx *gin.Context, function string) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	userInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	reservationId := ctx.Param("reservation_id")
+	if reservationId == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "missing reservation_id"})
+		return
+	}
+
+	chi, ok := userInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
+	log.Println("[HANDLER] [SUBMIT TX]", function)
+	if _, err := submitIdempotent(chi.Contract, key, function, reservationId); err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToSubmitTx)
+		return
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+// GetTraderSalesReport returns the trader's sales between the from and to
+// dates, given as YYYY-MM-DD, as JSON or as CSV with ?format=csv.
+func (h *Handler) GetTraderSalesReport(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	traderId := ctx.Param("trader_id")
+	from := ctx.Query("from")
+	to := ctx.Query("to")
+	if traderId == "" || from == "" || to == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - trader_id, from and to are required"})
+		return
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	log.Println("[HANDLER] [EVALUATE TX] GetTraderSalesReport")
+	response, err := chi.Traders.EvaluateTransaction("GetTraderSalesReport", traderId, from, to)
+	if err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToEvaluateTx)
+		return
+	}
+
+	var report models.SalesReport
+	if err := json.Unmarshal(response, &report); err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed to read the chain response"})
+		return
+	}
+
+	if ctx.Query("format") != "csv" {
+		ctx.JSON(http.StatusOK, gin.H{"data": report})
+		return
+	}
+
+	csv, err := export.SalesReportCSV(&report)
+	if err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed to export the report"})
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=sales-%s-%s-%s.csv", traderId, from, to))
+	ctx.Data(http.StatusOK, "text/csv", csv)
+}
+
+func (h *Handler) RefundReceipt(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	receiptId := ctx.Param("receipt_id")
+	if receiptId == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "missing receipt_id"})
+		return
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
+	log.Println("[HANDLER] [SUBMIT TX] RefundReceipt")
+	if _, err := submitIdempotent(chi.Receipts, key, "RefundReceipt", receiptId); err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToSubmitTx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "receipt refunded"})
+}
+
+// GetReceiptByNumber returns the caller's receipt with the fiscal number.
+func (h *Handler) GetReceiptByNumber(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	user_id := userIdEntry.(string)
+	userInfo := h.users[user_id]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	number := ctx.Param("number")
+	if number == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "missing number"})
+		return
+	}
+
+	chi, ok := userInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	log.Println("[HANDLER] [EVALUATE TX] GetReceiptByNumber")
+	response, err := chi.Receipts.EvaluateTransaction("GetReceiptByNumber", number)
+	if err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToEvaluateTx)
+		return
+	}
+
+	var receipt models.Receipt
+	if err := json.Unmarshal(response, &receipt); err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed to read the chain response"})
+		return
+	}
+
+	// users only see their own receipts
+	if receipt.UserID != user_id {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "receipt doesn't exist"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": receipt})
+}
+
+// CheckReceiptSequence reports the gaps in the fiscal numbers of the trader's
+// receipts in the year.
+func (h *Handler) CheckReceiptSequence(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	traderId := ctx.Param("trader_id")
+	year := ctx.Param("year")
+	if _, err := strconv.Atoi(year); traderId == "" || err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - trader_id and a numeric year are required"})
+		return
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	log.Println("[HANDLER] [EVALUATE TX] CheckReceiptSequence")
+	response, err := chi.Receipts.EvaluateTransaction("CheckReceiptSequence", traderId, year)
+	if err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToEvaluateTx)
+		return
+	}
+
+	var report models.SequenceReport
+	if err := json.Unmarshal(response, &report); err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed to read the chain response"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// GetStatement returns the user's statement on ?channel= for one currency
+// (RSD by default) between ?from= and ?to=, given as YYYY-MM-DD. ?format=
+// selects csv or pdf instead of JSON.
+func (h *Handler) GetStatement(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	user_id := userIdEntry.(string)
+	userInfo := h.users[user_id]
+
+	channel := ctx.Query("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	from := ctx.Query("from")
+	to := ctx.Query("to")
+	if from == "" || to == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - from and to are required"})
+		return
+	}
+
+	currency := ctx.DefaultQuery("currency", models.DefaultCurrency)
+
+	chi, ok := userInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	log.Println("[HANDLER] [EVALUATE TX] GetUserStatement")
+	response, err := chi.Users.EvaluateTransaction("GetUserStatement", user_id, currency, from, to)
+	if err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToEvaluateTx)
+		return
+	}
+
+	var statement models.Statement
+	if err := json.Unmarshal(response, &statement); err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed to read the chain response"})
+		return
+	}
+
+	filename := fmt.Sprintf("statement-%s-%s-%s-%s", user_id, currency, from, to)
+
+	switch ctx.Query("format") {
+	case "csv":
+		csv, err := export.StatementCSV(&statement)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed to export the statement"})
+			return
+		}
+
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.csv", filename))
+		ctx.Data(http.StatusOK, "text/csv", csv)
+	case "pdf":
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.pdf", filename))
+		ctx.Data(http.StatusOK, "application/pdf", export.StatementPDF(&statement))
+	default:
+		ctx.JSON(http.StatusOK, gin.H{"data": statement})
+	}
+}
+
+func (h *Handler) DepositFunds(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	userId := ctx.Param("user_id")
+	if userId == "" {
+		ctx.JSON(http.StatusBadRequest, missingUserIDError)
+		return
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	var deposit dto.DepositDto
+	if err := ctx.ShouldBindJSON(&deposit); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "couldn't resolve body"})
+		return
+	}
+
+	amount, _ := json.Marshal(deposit.Amount)
+
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
+	log.Println("[HANDLER] [SUBMIT TX] DepositFunds")
+	if _, err := submitIdempotent(chi.Users, key, "DepositFunds", userId, string(amount)); err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToSubmitTx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "funds deposited"})
+}
+
+// SetSpendingLimits replaces the daily and monthly spending limits of a user.
+func (h *Handler) SetSpendingLimits(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	userId := ctx.Param("user_id")
+	if userId == "" {
+		ctx.JSON(http.StatusBadRequest, missingUserIDError)
+		return
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	var limits models.SpendingLimits
+	if err := ctx.ShouldBindJSON(&limits); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "couldn't resolve body"})
+		return
+	}
+
+	limitsJson, _ := json.Marshal(limits)
+
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
+	log.Println("[HANDLER] [SUBMIT TX] SetSpendingLimits")
+	if _, err := submitIdempotent(chi.Users, key, "SetSpendingLimits", userId, string(limitsJson)); err != nil {
+		log.Println("[ERROR]", err)
+		respondSubmitError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "spending limits set"})
+}
+
+// FreezeAccount blocks every debit of a user's account until it's unfrozen.
+func (h *Handler) FreezeAccount(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	userId := ctx.Param("user_id")
+	if userId == "" {
+		ctx.JSON(http.StatusBadRequest, missingUserIDError)
+		return
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	var freeze dto.FreezeDto
+	if err := ctx.ShouldBindJSON(&freeze); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "couldn't resolve body"})
+		return
+	}
+
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
+	log.Println("[HANDLER] [SUBMIT TX] FreezeAccount")
+	if _, err := submitIdempotent(chi.Users, key, "FreezeAccount", userId, freeze.Reason); err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToSubmitTx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "account frozen"})
+}
+
+func (h *Handler) UnfreezeAccount(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	userId := ctx.Param("user_id")
+	if userId == "" {
+		ctx.JSON(http.StatusBadRequest, missingUserIDError)
+		return
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
+	log.Println("[HANDLER] [SUBMIT TX] UnfreezeAccount")
+	if _, err := submitIdempotent(chi.Users, key, "UnfreezeAccount", userId); err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToSubmitTx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "account unfrozen"})
+}
+
+// AuditLedger runs the chaincode's integrity checks over the whole world
+// state and returns the violations it found.
+func (h *Handler) AuditLedger(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	log.Println("[HANDLER] [EVALUATE TX] AuditLedger")
+	response, err := chi.Contract.EvaluateTransaction("AuditLedger")
+	if err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToEvaluateTx)
+		return
+	}
+
+	var report models.AuditReport
+	if err := json.Unmarshal(response, &report); err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed to read the chain response"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// GetTrialBalance returns the debits and credits of every journal account and
+// whether the books balance.
+func (h *Handler) GetTrialBalance(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	log.Println("[HANDLER] [EVALUATE TX] GetTrialBalance")
+	response, err := chi.Contract.EvaluateTransaction("GetTrialBalance")
+	if err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToEvaluateTx)
+		return
+	}
+
+	var trial models.TrialBalance
+	if err := json.Unmarshal(response, &trial); err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed to read the chain response"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": trial})
+}
+
+const defaultMigrationPageSize = 100
+
+// MigrateState upgrades the stored documents of :entity_type to their
+// current schema version and builds the index entries they're missing, such
+// as those of records stored before the reference and PIB indexes. Every page is its own transaction; the handler
+// keeps submitting until the chaincode reports the type as done. ?page_size=
+// sets how many documents one transaction scans.
+func (h *Handler) MigrateState(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	entityType := ctx.Param("entity_type")
+	if entityType == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "missing entity_type"})
+		return
+	}
+
+	pageSize := defaultMigrationPageSize
+	if value := ctx.Query("page_size"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - page_size must be a positive number"})
+			return
+		}
+		pageSize = size
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
+	pages := make([]models.MigrationPage, 0)
+	startKey := ""
+	for {
+		log.Println("[HANDLER] [SUBMIT TX] MigrateState", entityType, startKey)
+		response, err := submitIdempotent(chi.Contract, subKey(key, len(pages)), "MigrateState", entityType, strconv.Itoa(pageSize), startKey)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": failedToSubmitTx["status"], "pages": pages})
+			return
+		}
+
+		var page models.MigrationPage
+		if err := json.Unmarshal(response, &page); err != nil {
+			log.Println("[ERROR]", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed to read the chain response", "pages": pages})
+			return
+		}
+		pages = append(pages, page)
+
+		if page.Done {
+			break
+		}
+		startKey = page.NextKey
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": pages})
+}
+
+const defaultCompactionLimit = 500
+
+// CompactTraderDeltas folds the deltas the sales and refunds left into the
+// traders' documents. It's meant to be scheduled periodically; every batch of
+// deltas is its own transaction, the handler runs them until none are left.
+func (h *Handler) CompactTraderDeltas(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	limit := defaultCompactionLimit
+	if value := ctx.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - limit must be a positive number"})
+			return
+		}
+		limit = parsed
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
+	results := make([]models.CompactionResult, 0)
+	for {
+		log.Println("[HANDLER] [SUBMIT TX] CompactTraderDeltas", limit)
+		response, err := submitIdempotent(chi.Traders, subKey(key, len(results)), "CompactTraderDeltas", strconv.Itoa(limit))
+		if err != nil {
+			log.Println("[ERROR]", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": failedToSubmitTx["status"], "results": results})
+			return
+		}
+
+		var result models.CompactionResult
+		if err := json.Unmarshal(response, &result); err != nil {
+			log.Println("[ERROR]", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed to read the chain response", "results": results})
+			return
+		}
+		results = append(results, result)
+
+		if result.Done {
+			break
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": results})
+}
+
+// GetArchivedRecords lists the archived records of :entity_type (PRODUCT,
+// USER, TRADER or RECEIPT), which the other listings leave out.
+func (h *Handler) GetArchivedRecords(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	log.Println("[HANDLER] [EVALUATE TX] GetArchivedRecords")
+	response, err := chi.Contract.EvaluateTransaction("GetArchivedRecords", ctx.Param("entity_type"))
+	if err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToEvaluateTx)
+		return
+	}
+
+	var records []models.ArchivedRecord
+	if err := json.Unmarshal(response, &records); err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed to read the chain response"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": records})
+}
+
+func (h *Handler) RestoreRecord(ctx *gin.Context) {
+	h.submitArchiveTx(ctx, "RestoreRecord", "record restored")
+}
+
+// PurgeRecord removes an archived record for good. The chaincode refuses
+// while other records still refer to it.
+func (h *Handler) PurgeRecord(ctx *gin.Context) {
+	h.submitArchiveTx(ctx, "PurgeRecord", "record purged")
+}
+
+func (h *Handler) submitArchiveTx(ctx *gin.Context, function string, done string) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	entityType := ctx.Param("entity_type")
+	id := ctx.Param("id")
+	if entityType == "" || id == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - entity_type and id are required"})
+		return
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
+	log.Println("[HANDLER] [SUBMIT TX]", function)
+	if _, err := submitIdempotent(chi.Contract, key, function, entityType, id); err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToSubmitTx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": done})
+}
+
+func (h *Handler) GetIntegrityRules(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	userInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	chi, ok := userInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	log.Println("[HANDLER] [EVALUATE TX] GetIntegrityRules")
+	response, err := chi.Contract.EvaluateTransaction("GetIntegrityRules")
+	if err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToEvaluateTx)
+		return
+	}
+
+	var rules []models.IntegrityRule
+	if err := json.Unmarshal(response, &rules); err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed to read the chain response"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": rules})
+}
+
+// SetDeleteAction chooses what deleting a record does to the records that
+// refer to it, one of RESTRICT, CASCADE or KEEP.
+func (h *Handler) SetDeleteAction(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	var body struct {
+		Action string `json:"action"`
+	}
+	if err := ctx.ShouldBindJSON(&body); err != nil || body.Action == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - action is required"})
+		return
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
+	log.Println("[HANDLER] [SUBMIT TX] SetDeleteAction")
+	if _, err := submitIdempotent(chi.Contract, key, "SetDeleteAction", ctx.Param("relation"), body.Action); err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToSubmitTx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "delete action set"})
+}
+
+// SetTraderVerification records whether the trader passed the admins' check
+// of its PIB, one of PENDING, VERIFIED or SUSPENDED.
+func (h *Handler) SetTraderVerification(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	var body struct {
+		Status models.VerificationStatus `json:"status"`
+	}
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - status is required"})
+		return
+	}
+
+	switch body.Status {
+	case models.VerificationPending, models.VerificationVerified, models.VerificationSuspended:
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - status must be PENDING, VERIFIED or SUSPENDED"})
+		return
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
+	log.Println("[HANDLER] [SUBMIT TX] SetTraderVerification")
+	if _, err := submitIdempotent(chi.Traders, key, "SetTraderVerification", ctx.Param("trader_id"), string(body.Status)); err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToSubmitTx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "trader verification updated"})
+}
+
+// TransferTraderOwnership moves the trader and its products to another
+// organization. The previous owner's peers have to endorse the transfer.
+func (h *Handler) TransferTraderOwnership(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	var body struct {
+		MSPID string `json:"msp_id" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - msp_id is required"})
+		return
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
+	log.Println("[HANDLER] [SUBMIT TX] TransferTraderOwnership")
+	if _, err := submitIdempotent(chi.Traders, key, "TransferTraderOwnership", ctx.Param("trader_id"), body.MSPID); err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToSubmitTx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "trader ownership transferred"})
+}
+
+// SetTraderEndorsers replaces the organizations that endorse changes to the
+// trader's assets along with its owner.
+func (h *Handler) SetTraderEndorsers(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	var body struct {
+		Endorsers []string `json:"endorsers"`
+	}
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - endorsers are required"})
+		return
+	}
+
+	if body.Endorsers == nil {
+		body.Endorsers = make([]string, 0)
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	endorsers, _ := json.Marshal(body.Endorsers)
+
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
+	log.Println("[HANDLER] [SUBMIT TX] SetTraderEndorsers")
+	if _, err := submitIdempotent(chi.Traders, key, "SetTraderEndorsers", ctx.Param("trader_id"), string(endorsers)); err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToSubmitTx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "trader endorsers updated"})
+}
+
+func (h *Handler) GetAllCategories(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	userInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	chi, ok := userInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	log.Println("[HANDLER] [EVALUATE TX] GetAllCategories")
+	response, err := chi.Contract.EvaluateTransaction("GetAllCategories")
+	if err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToEvaluateTx)
+		return
+	}
+
+	var categories []models.Category
+	if err := json.Unmarshal(response, &categories); err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed to read the chain response"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": categories})
+}
+
+func (h *Handler) CreateCategory(ctx *gin.Context) {
+	h.submitCategoryTx(ctx, "CreateCategory", "category created")
+}
+
+// UpdateCategory replaces the category's schema, products created before
+// keep their attributes.
+func (h *Handler) UpdateCategory(ctx *gin.Context) {
+	h.submitCategoryTx(ctx, "UpdateCategory", "category updated")
+}
+
+func (h *Handler) submitCategoryTx(ctx *gin.Context, function string, done string) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	var category models.Category
+	if err := ctx.ShouldBindJSON(&category); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - invalid category"})
+		return
+	}
+
+	if id := ctx.Param("category_id"); id != "" {
+		category.ID = id
+	}
+
+	if category.ID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - category id is required"})
+		return
+	}
+
+	categoryJson, err := json.Marshal(category)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - invalid category"})
+		return
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	if !validateModel(ctx, chi, models.CATEGORY_TYPE, category) {
+		return
+	}
+
+	args := []string{string(categoryJson)}
+	if function == "UpdateCategory" {
+		args = []string{category.ID, string(categoryJson)}
+	}
+
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
+	log.Println("[HANDLER] [SUBMIT TX]", function)
+	if _, err := submitIdempotent(chi.Contract, key, function, args...); err != nil {
+		log.Println("[ERROR]", err)
+		respondSubmitError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": done})
+}
+
+const defaultImportBatchSize = 100
+
+// ImportProducts uploads a CSV or JSON catalogue, either as the request body
+// or as the file field of a multipart form, and creates its products with
+// CreateProducts, or updates them with UpdateProducts when update=true. The
+// catalogue is split into batches of at most batch_size products and the
+// progress is streamed as one JSON line per batch.
+//
+// A batch is one transaction, so mode=ALL_OR_NOTHING holds for each batch:
+// the import stops at the first batch that isn't committed, and the batches
+// before it stay written.
+func (h *Handler) ImportProducts(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	mode := models.BatchMode(ctx.DefaultQuery("mode", string(models.BatchAllOrNothing)))
+	if mode != models.BatchAllOrNothing && mode != models.BatchBestEffort {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - mode must be ALL_OR_NOTHING or BEST_EFFORT"})
+		return
+	}
+
+	batchSize := defaultImportBatchSize
+	if value := ctx.Query("batch_size"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size <= 0 || size > catalogue.MaxBatchItems {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": fmt.Sprintf("bad-request - batch_size must be between 1 and %d", catalogue.MaxBatchItems)})
+			return
+		}
+		batchSize = size
+	}
+
+	function := "CreateProducts"
+	if ctx.Query("update") == "true" {
+		function = "UpdateProducts"
+	}
+
+	var upload io.Reader = ctx.Request.Body
+	fileName := ""
+	if file, header, err := ctx.Request.FormFile("file"); err == nil {
+		defer file.Close()
+		upload = file
+		fileName = header.Filename
+	}
+
+	format, err := catalogue.FormatOf(ctx.ContentType(), fileName)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - " + err.Error()})
+		return
+	}
+
+	products, err := catalogue.Parse(format, upload)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - " + err.Error()})
+		return
+	}
+
+	batches, err := catalogue.Chunk(products, batchSize, catalogue.MaxBatchBytes)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - " + err.Error()})
+		return
+	}
+
+	if len(batches) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - the catalogue is empty"})
+		return
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.Header("Content-Type", "application/x-ndjson")
+	ctx.Status(http.StatusOK)
+	encoder := json.NewEncoder(ctx.Writer)
+
+	progress := models.ImportProgress{Batches: len(batches), Total: len(products)}
+	for i, batch := range batches {
+		progress.Batch = i + 1
+		progress.Items = nil
+
+		result, err := submitBatch(chi, subKey(key, i), function, batch, mode)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			progress.Error = fmt.Sprint(failedToSubmitTx["status"])
+		} else {
+			for _, item := range result.Items {
+				item.Index += progress.Processed
+				progress.Items = append(progress.Items, item)
+			}
+			progress.Committed = result.Committed
+			progress.Failed += result.Failed
+			if result.Committed {
+				progress.Written += result.Valid
+			}
+		}
+		progress.Processed += len(batch)
+
+		if err := encoder.Encode(progress); err != nil {
+			log.Println("[ERROR]", err)
+			return
+		}
+		ctx.Writer.Flush()
+
+		if err != nil || (mode == models.BatchAllOrNothing && !result.Committed) {
+			return
+		}
+	}
+}
+
+func submitBatch(chi *channelinterface.ChannelInterace, key string, function string, batch []models.Product, mode models.BatchMode) (*models.BatchResult, error) {
+	batchJson, err := json.Marshal(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("[HANDLER] [SUBMIT TX]", function, len(batch))
+	response, err := submitIdempotent(chi.Products, key, function, string(batchJson), string(mode))
+	if err != nil {
+		return nil, err
+	}
+
+	var result models.BatchResult
+	if err := json.Unmarshal(response, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+const defaultExportPageSize = 200
+
+// importChunkLines matches the batch limit of the chaincode.
+const importChunkLines = 500
+
+const importChunkBytes = 512 * 1024
+
+// ExportState downloads the business state of the channel as a snapshot
+// file. The checksum of every page is verified as it arrives, and the file
+// ends with the checksum of all its entries, which is also sent in the
+// X-Snapshot-Checksum header.
+func (h *Handler) ExportState(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	pageSize := defaultExportPageSize
+	if value := ctx.Query("page_size"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - page_size must be a positive number"})
+			return
+		}
+		pageSize = size
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	exportedAt := time.Now().UTC()
+	var file bytes.Buffer
+	writer, err := snapshot.NewWriter(&file, snapshot.Header{FormatVersion: 1, Channel: channel, ExportedAt: exportedAt.Format(time.RFC3339)})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed to write the snapshot"})
+		return
+	}
+
+	bookmark := ""
+	for {
+		log.Println("[HANDLER] [EVALUATE TX] ExportState", bookmark)
+		response, err := chi.Contract.EvaluateTransaction("ExportState", strconv.Itoa(pageSize), bookmark)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			ctx.JSON(http.StatusInternalServerError, failedToEvaluateTx)
+			return
+		}
+
+		var page models.ExportPage
+		if err := json.Unmarshal(response, &page); err != nil {
+			log.Println("[ERROR]", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed to read the chain response"})
+			return
+		}
+
+		if snapshot.Checksum(page.Data) != page.Checksum {
+			ctx.JSON(http.StatusBadGateway, gin.H{"status": fmt.Sprintf("the %s page doesn't match its checksum", page.EntityType)})
+			return
+		}
+
+		if err := writer.WriteData(page.Data); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed to write the snapshot"})
+			return
+		}
+
+		if page.Done {
+			break
+		}
+		bookmark = page.Bookmark
+	}
+
+	trailer, err := writer.Close()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed to write the snapshot"})
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s-%s.ndjson", channel, exportedAt.Format("20060102T150405Z")))
+	ctx.Header("X-Snapshot-Checksum", trailer.Checksum)
+	ctx.Data(http.StatusOK, "application/x-ndjson", file.Bytes())
+}
+
+// ImportState loads a snapshot file, sent as the request body or as the file
+// field of a multipart form, into the channel. The file is checked against
+// its checksum before anything is submitted, and every chunk is submitted
+// with its own checksum. ?overwrite=true replaces documents that are
+// already stored, otherwise the import stops at the first one.
+func (h *Handler) ImportState(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	adminUserInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	overwrite := ctx.Query("overwrite") == "true"
+
+	var upload io.Reader = ctx.Request.Body
+	if file, _, err := ctx.Request.FormFile("file"); err == nil {
+		defer file.Close()
+		upload = file
+	}
+
+	header, entries, err := snapshot.Read(upload)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - " + err.Error()})
+		return
+	}
+
+	chi, ok := adminUserInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
+	results := make([]models.ImportResult, 0)
+	for i, chunk := range snapshot.Chunk(entries, importChunkLines, importChunkBytes) {
+		checksum := snapshot.Checksum(chunk)
+
+		log.Println("[HANDLER] [SUBMIT TX] ImportState", checksum)
+		response, err := submitIdempotent(chi.Contract, subKey(key, i), "ImportState", chunk, checksum, strconv.FormatBool(overwrite))
+		if err != nil {
+			log.Println("[ERROR]", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": failedToSubmitTx["status"], "chunks": results})
+			return
+		}
+
+		var result models.ImportResult
+		if err := json.Unmarshal(response, &result); err != nil || result.Checksum != checksum {
+			log.Println("[ERROR]", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed to read the chain response", "chunks": results})
+			return
+		}
+		results = append(results, result)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"source_channel": header.Channel, "exported_at": header.ExportedAt, "entries": len(entries), "chunks": results})
+}
+
+// GetValidationRules returns the rules the chaincode checks the users,
+// products, traders and categories it's sent against.
+func (h *Handler) GetValidationRules(ctx *gin.Context) {
+	userIdEntry, ok := ctx.Get("user_id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, jwt.BadRequestNoAuthParamsError)
+		return
+	}
+	userInfo := h.users[userIdEntry.(string)]
+
+	channel := ctx.Param("channel")
+	if channel == "" {
+		ctx.JSON(http.StatusBadRequest, missingChannelError)
+		return
+	}
+
+	chi, ok := userInfo.ChannelInterfaces[channel]
+	if !ok || chi == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "channel doesn't exist"})
+		return
+	}
+
+	rules, err := readValidationRules(chi)
+	if err != nil {
+		log.Println("[ERROR]", err)
+		ctx.JSON(http.StatusInternalServerError, failedToEvaluateTx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": rules})
+}
+
+func readValidationRules(chi *channelinterface.ChannelInterace) ([]models.ModelRules, error) {
+	log.Println("[HANDLER] [EVALUATE TX] GetValidationRules")
+	response, err := chi.Contract.EvaluateTransaction("GetValidationRules")
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []models.ModelRules
+	if err := json.Unmarshal(response, &rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// validateModel checks the model against the chaincode's rules for its type
+// before it's submitted. It responds with the broken rules and returns false
+// when there are any. The model is submitted unchecked when the rules can't
+// be read, the chaincode checks it anyway.
+func validateModel(ctx *gin.Context, chi *channelinterface.ChannelInterace, entityType string, model any) bool {
+	rules, err := readValidationRules(chi)
+	if err != nil {
+		log.Println("[ERROR]", err)
+		return true
+	}
+
+	for _, modelRules := range rules {
+		if modelRules.Type != entityType {
+			continue
+		}
+
+		errs, err := validation.Validate(modelRules, model)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			return true
+		}
+
+		if len(errs) > 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - validation failed", "errors": errs})
+			return false
+		}
+	}
+
+	return true
+}
+
+// respondSubmitError responds with the broken rules when the chaincode
+// rejected the transaction's arguments.
+func respondSubmitError(ctx *gin.Context, err error) {
+	if errs, ok := models.ParseValidationErrors(err); ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "bad-request - validation failed", "errors": errs})
+		return
+	}
 
+	ctx.JSON(http.StatusInternalServerError, failedToSubmitTx)
 }
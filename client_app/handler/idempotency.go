@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"clientapp/models"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// idempotencyKeyField is the field of the transient data the chaincode reads
+// the key from.
+const idempotencyKeyField = "idempotency_key"
+
+// maxIdempotencyKeyLength leaves room for the suffixes of subKey within the
+// 128 characters the chaincode accepts.
+const maxIdempotencyKeyLength = 100
+
+// submitAttempts bounds how often a transaction is submitted. Retries carry
+// the same idempotency key, so one that already committed isn't run twice.
+const submitAttempts = 2
+
+// idempotencyKey returns the request's Idempotency-Key header, or a new key
+// when there's none, and echoes it in the response so the caller can retry
+// the request with it. It responds with an error and returns false when the
+// header isn't a valid key.
+func idempotencyKey(ctx *gin.Context) (string, bool) {
+	key := ctx.GetHeader(idempotencyKeyHeader)
+	if key == "" {
+		keyBytes := make([]byte, 16)
+		if _, err := rand.Read(keyBytes); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed to generate an idempotency key"})
+			return "", false
+		}
+		key = hex.EncodeToString(keyBytes)
+	}
+
+	if len(key) > maxIdempotencyKeyLength {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": fmt.Sprintf("bad-request - %s must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength)})
+		return "", false
+	}
+
+	for _, c := range key {
+		if c < '!' || c > '~' {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": fmt.Sprintf("bad-request - %s must be printable ascii without spaces", idempotencyKeyHeader)})
+			return "", false
+		}
+	}
+
+	ctx.Header(idempotencyKeyHeader, key)
+	return key, true
+}
+
+// subKey derives the key of the n-th of several transactions one request
+// submits.
+func subKey(key string, n int) string {
+	return fmt.Sprintf("%s-%d", key, n)
+}
+
+// idFromKey derives the id of a record a request creates from its key, so a
+// retried request creates the same record.
+func idFromKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:16])
+}
+
+// submitIdempotent submits the transaction with the idempotency key in its
+// transient data and retries it once when the submission fails for a reason
+// other than invalid arguments. If the first submission committed, the
+// chaincode answers the retry with its response.
+func submitIdempotent(contract *gateway.Contract, key string, name string, args ...string) ([]byte, error) {
+	var err error
+	for attempt := 1; attempt <= submitAttempts; attempt++ {
+		var transaction *gateway.Transaction
+		transaction, err = contract.CreateTransaction(name, gateway.WithTransient(map[string][]byte{idempotencyKeyField: []byte(key)}))
+		if err != nil {
+			return nil, err
+		}
+
+		var response []byte
+		response, err = transaction.Submit(args...)
+		if err == nil {
+			return response, nil
+		}
+
+		if _, invalid := models.ParseValidationErrors(err); invalid {
+			return nil, err
+		}
+
+		log.Println("[HANDLER] [SUBMIT TX] attempt", attempt, "of", name, "failed:", err)
+	}
+
+	return nil, err
+}
@@ -0,0 +1,134 @@
+package htlc
+
+import (
+	channelinterface "clientapp/channel_interface"
+	"clientapp/models"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+const DefaultTimeout = time.Hour
+
+type Phase string
+
+const (
+	PhaseStarted     Phase = "STARTED"
+	PhaseLocked      Phase = "LOCKED"
+	PhaseClaimMinted Phase = "CLAIM_MINTED"
+	PhaseRedeemed    Phase = "REDEEMED"
+	PhaseCompleted   Phase = "COMPLETED"
+)
+
+// Coordinator drives a hash-time-locked transfer between two channels. Funds
+// are locked on the source channel, a claim is minted on the target channel
+// and the preimage is revealed on the target first and then on the source.
+type Coordinator struct {
+	Source          *channelinterface.ChannelInterace
+	SourceChannel   string
+	SourceChaincode string
+	Target          *channelinterface.ChannelInterace
+	TargetChannel   string
+	TargetChaincode string
+	Timeout         time.Duration
+}
+
+type Transfer struct {
+	LockID        string       `json:"lock_id"`
+	SourceChannel string       `json:"source_channel"`
+	TargetChannel string       `json:"target_channel"`
+	UserID        string       `json:"user_id"`
+	RecipientID   string       `json:"recipient_id"`
+	Amount        models.Money `json:"amount"`
+	Timeout       string       `json:"timeout"`
+	Phase         Phase        `json:"phase"`
+}
+
+func randomHex(size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
+
+// Transfer runs both phases of the protocol. When a step fails the returned
+// transfer reports the last phase that committed; a transfer stuck in
+// PhaseLocked or PhaseClaimMinted can be refunded after its timeout.
+func (c *Coordinator) Transfer(userId string, recipientId string, amount models.Money) (*Transfer, error) {
+	lockId, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+
+	preimage, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+
+	amountJson, err := json.Marshal(amount)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256([]byte(preimage))
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	transfer := &Transfer{
+		LockID:        lockId,
+		SourceChannel: c.SourceChannel,
+		TargetChannel: c.TargetChannel,
+		UserID:        userId,
+		RecipientID:   recipientId,
+		Amount:        amount,
+		Timeout:       time.Now().Add(timeout).UTC().Format(time.RFC3339),
+		Phase:         PhaseStarted,
+	}
+
+	log.Println("[HTLC] [SUBMIT TX] LockFunds", lockId)
+	if _, err := c.Source.Contract.SubmitTransaction("LockFunds", lockId, userId, recipientId, string(amountJson), hex.EncodeToString(hash[:]), transfer.Timeout, c.TargetChannel, c.TargetChaincode); err != nil {
+		return transfer, fmt.Errorf("failed to lock the funds: %v", err)
+	}
+	transfer.Phase = PhaseLocked
+
+	log.Println("[HTLC] [SUBMIT TX] MintClaim", lockId)
+	if _, err := c.Target.Contract.SubmitTransaction("MintClaim", lockId, c.SourceChannel, c.SourceChaincode); err != nil {
+		return transfer, fmt.Errorf("failed to mint the claim: %v", err)
+	}
+	transfer.Phase = PhaseClaimMinted
+
+	log.Println("[HTLC] [SUBMIT TX] RedeemClaim", lockId)
+	if _, err := c.Target.Contract.SubmitTransaction("RedeemClaim", lockId, preimage); err != nil {
+		return transfer, fmt.Errorf("failed to redeem the claim: %v", err)
+	}
+	transfer.Phase = PhaseRedeemed
+
+	log.Println("[HTLC] [SUBMIT TX] CompleteLock", lockId)
+	if _, err := c.Source.Contract.SubmitTransaction("CompleteLock", lockId, preimage); err != nil {
+		// the preimage is public on the target channel now, so RefundLock
+		// will complete the lock instead of refunding it
+		return transfer, fmt.Errorf("failed to complete the lock: %v", err)
+	}
+	transfer.Phase = PhaseCompleted
+
+	return transfer, nil
+}
+
+// Refund releases a lock whose timeout passed. If the claim on the target
+// channel was redeemed the chaincode completes the lock instead.
+func (c *Coordinator) Refund(lockId string) error {
+	log.Println("[HTLC] [SUBMIT TX] RefundLock", lockId)
+	if _, err := c.Source.Contract.SubmitTransaction("RefundLock", lockId); err != nil {
+		return fmt.Errorf("failed to refund the lock: %v", err)
+	}
+
+	return nil
+}
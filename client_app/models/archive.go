@@ -0,0 +1,7 @@
+package models
+
+type ArchivedRecord struct {
+	ID         string `json:"id"`
+	EntityType string `json:"entity_type"`
+	DeletedAt  string `json:"deleted_at"`
+}
@@ -0,0 +1,12 @@
+package models
+
+type Violation struct {
+	Rule     string `json:"rule"`
+	EntityID string `json:"entity_id"`
+	Message  string `json:"message"`
+}
+
+type AuditReport struct {
+	Checked    map[string]int `json:"checked"`
+	Violations []*Violation   `json:"violations"`
+}
@@ -0,0 +1,37 @@
+package models
+
+type BatchMode string
+
+const (
+	BatchAllOrNothing BatchMode = "ALL_OR_NOTHING"
+	BatchBestEffort   BatchMode = "BEST_EFFORT"
+)
+
+type BatchItemResult struct {
+	Index int    `json:"index"`
+	ID    string `json:"id"`
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+type BatchResult struct {
+	Mode      BatchMode         `json:"mode"`
+	Committed bool              `json:"committed"`
+	Valid     int               `json:"valid"`
+	Failed    int               `json:"failed"`
+	Items     []BatchItemResult `json:"items"`
+}
+
+// ImportProgress is reported after every batch of a catalogue import. Items
+// are numbered by their position in the whole catalogue.
+type ImportProgress struct {
+	Batch     int               `json:"batch"`
+	Batches   int               `json:"batches"`
+	Processed int               `json:"processed"`
+	Total     int               `json:"total"`
+	Committed bool              `json:"committed"`
+	Written   int               `json:"written"`
+	Failed    int               `json:"failed"`
+	Error     string            `json:"error,omitempty"`
+	Items     []BatchItemResult `json:"items,omitempty"`
+}
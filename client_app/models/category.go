@@ -0,0 +1,10 @@
+package models
+
+// Category is a kind of trader, with the JSON schema of the attributes of
+// its products.
+type Category struct {
+	ID               string         `json:"id"`
+	Name             string         `json:"name"`
+	Description      string         `json:"description"`
+	AttributesSchema map[string]any `json:"attributes_schema"`
+}
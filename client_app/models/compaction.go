@@ -0,0 +1,7 @@
+package models
+
+type CompactionResult struct {
+	Deltas  int  `json:"deltas"`
+	Traders int  `json:"traders"`
+	Done    bool `json:"done"`
+}
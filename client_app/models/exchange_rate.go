@@ -0,0 +1,20 @@
+package models
+
+type ExchangeRate struct {
+	ID        string `json:"id"`
+	Base      string `json:"base"`
+	Quote     string `json:"quote"`
+	Rate      int64  `json:"rate"`
+	Version   uint64 `json:"version"`
+	UpdatedAt string `json:"updated_at"`
+	MaxAge    int64  `json:"max_age"`
+}
+
+// AppliedRate is the exchange rate a receipt was paid at. Rate is scaled by
+// one million.
+type AppliedRate struct {
+	Base    string `json:"base"`
+	Quote   string `json:"quote"`
+	Rate    int64  `json:"rate"`
+	Version uint64 `json:"version"`
+}
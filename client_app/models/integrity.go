@@ -0,0 +1,10 @@
+package models
+
+type IntegrityRule struct {
+	Relation      string `json:"relation"`
+	From          string `json:"from"`
+	Field         string `json:"field"`
+	To            string `json:"to"`
+	Action        string `json:"action"`
+	DefaultAction string `json:"default_action"`
+}
@@ -0,0 +1,25 @@
+package models
+
+type AccountTotals struct {
+	Account  string `json:"account"`
+	Currency string `json:"currency"`
+	Debits   Money  `json:"debits"`
+	Credits  Money  `json:"credits"`
+}
+
+type CurrencyTotals struct {
+	Currency string `json:"currency"`
+	Debits   Money  `json:"debits"`
+	Credits  Money  `json:"credits"`
+	Balanced bool   `json:"balanced"`
+}
+
+// TrialBalance adds up the journal per account. The books balance when every
+// currency debits as much as it credits.
+type TrialBalance struct {
+	Entries    int               `json:"entries"`
+	Accounts   []*AccountTotals  `json:"accounts"`
+	Totals     []*CurrencyTotals `json:"totals"`
+	Unbalanced []string          `json:"unbalanced"`
+	Balanced   bool              `json:"balanced"`
+}
@@ -0,0 +1,19 @@
+package models
+
+type SpendingLimits struct {
+	Daily   Balances `json:"daily"`
+	Monthly Balances `json:"monthly"`
+}
+
+type Spending struct {
+	Day     string   `json:"day"`
+	Daily   Balances `json:"daily"`
+	Month   string   `json:"month"`
+	Monthly Balances `json:"monthly"`
+}
+
+type AccountFreeze struct {
+	Reason   string `json:"reason"`
+	FrozenAt string `json:"frozen_at"`
+	TxID     string `json:"tx_id"`
+}
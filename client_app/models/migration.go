@@ -0,0 +1,11 @@
+package models
+
+type MigrationPage struct {
+	EntityType    string `json:"entity_type"`
+	SchemaVersion int    `json:"schema_version"`
+	Scanned       int    `json:"scanned"`
+	Migrated      int    `json:"migrated"`
+	Indexed       int    `json:"indexed"`
+	NextKey       string `json:"next_key"`
+	Done          bool   `json:"done"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const DefaultCurrency string = "RSD"
+
+// MinorUnits is the number of minor units (para, cent) in one major unit.
+const MinorUnits int64 = 100
+
+var currencyPattern = regexp.MustCompile("^[A-Z]{3}$")
+
+var amountPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]{1,2})?$`)
+
+// Money mirrors the chaincode money type: an amount in integer minor units of
+// an ISO 4217 currency.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// ParseMoney reads a decimal amount such as "2.49" in the given currency, the
+// same way the chaincode does.
+func ParseMoney(value string, currency string) (Money, error) {
+	value = strings.TrimSpace(value)
+	if !amountPattern.MatchString(value) {
+		return Money{}, fmt.Errorf("invalid amount %q: expected digits with at most two decimal places", value)
+	}
+
+	if !currencyPattern.MatchString(currency) {
+		return Money{}, fmt.Errorf("invalid currency code %q", currency)
+	}
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	major, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q", value)
+	}
+
+	fraction += strings.Repeat("0", 2-len(fraction))
+	minor, err := strconv.ParseInt(fraction, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q", value)
+	}
+
+	if major > (math.MaxInt64-minor)/MinorUnits {
+		return Money{}, fmt.Errorf("amount %q is too large", value)
+	}
+
+	return Money{Amount: major*MinorUnits + minor, Currency: currency}, nil
+}
+
+// Balances holds one balance per currency, keyed by the ISO currency code.
+type Balances map[string]Money
+
+func (m Money) String() string {
+	return fmt.Sprintf("%d.%02d %s", m.Amount/100, m.Amount%100, m.Currency)
+}
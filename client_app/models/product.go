@@ -1,12 +1,24 @@
 package models
 
+type PriceTier struct {
+	MinQuantity uint  `json:"min_quantity"`
+	UnitPrice   Money `json:"unit_price"`
+}
+
 type Product struct {
-	ID             string `json:"id"`
-	Name           string `json:"name"`
-	ExpirationDate string `json:"expiration_date"`
-	Price          uint   `json:"price"`
-	Quantity       uint   `json:"quantity"`
-	TraderID       string `json:"trader_id"`
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	ExpirationDate string         `json:"expiration_date"`
+	Price          Money          `json:"price"`
+	Tiers          []PriceTier    `json:"tiers"`
+	Quantity       uint           `json:"quantity"`
+	Reserved       uint           `json:"reserved"`
+	Reservations   []string       `json:"reservations"`
+	TraderID       string         `json:"trader_id"`
+	Category       string         `json:"category,omitempty"`
+	Attributes     map[string]any `json:"attributes,omitempty"`
+	Status         string         `json:"status,omitempty"`
+	DeletedAt      string         `json:"deleted_at,omitempty"`
 }
 
 func (p Product) GetID() string {
@@ -1,13 +1,22 @@
 package models
 
-import "time"
-
 type Receipt struct {
-	ID        string    `json:"id"`
-	TraderID  string    `json:"trader"`
-	UserID    string    `json:"user_id"`
-	ProductID string    `json:"product_id"`
-	Date      time.Time `json:"date"`
+	ID        string `json:"id"`
+	TraderID  string `json:"trader"`
+	UserID    string `json:"user_id"`
+	ProductID string `json:"product_id"`
+	Price     Money  `json:"price"`
+	Quantity  uint   `json:"quantity"`
+	// Tier is the price tier the units were sold at, if any.
+	Tier *PriceTier `json:"tier,omitempty"`
+	// Paid differs from Price when the user paid in another currency.
+	Paid         Money        `json:"paid"`
+	ExchangeRate *AppliedRate `json:"exchange_rate,omitempty"`
+	// Date is the day of the sale, formatted as 02-01-2006.
+	Date       string `json:"date"`
+	RefundedAt string `json:"refunded_at,omitempty"`
+	Status     string `json:"status,omitempty"`
+	DeletedAt  string `json:"deleted_at,omitempty"`
 }
 
 func (r Receipt) GetID() string {
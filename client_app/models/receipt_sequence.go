@@ -0,0 +1,13 @@
+package models
+
+// SequenceReport is the check of the fiscal numbers of a trader's receipts in
+// a year.
+type SequenceReport struct {
+	TraderID   string   `json:"trader_id"`
+	Year       int      `json:"year"`
+	Last       uint64   `json:"last"`
+	Issued     int      `json:"issued"`
+	Missing    []string `json:"missing"`
+	Unexpected []string `json:"unexpected"`
+	Complete   bool     `json:"complete"`
+}
@@ -0,0 +1,34 @@
+package models
+
+type ProductSales struct {
+	ProductID string `json:"product_id"`
+	Units     uint   `json:"units"`
+	Revenue   Money  `json:"revenue"`
+}
+
+type CustomerSales struct {
+	UserID   string `json:"user_id"`
+	Receipts int    `json:"receipts"`
+	Spent    Money  `json:"spent"`
+}
+
+type DailySales struct {
+	Date    string `json:"date"`
+	Units   uint   `json:"units"`
+	Revenue Money  `json:"revenue"`
+	Refunds Money  `json:"refunds"`
+}
+
+type SalesReport struct {
+	TraderID     string           `json:"trader_id"`
+	From         string           `json:"from"`
+	To           string           `json:"to"`
+	Receipts     int              `json:"receipts"`
+	Units        uint             `json:"units"`
+	Revenue      Money            `json:"revenue"`
+	Refunds      Money            `json:"refunds"`
+	NetRevenue   Money            `json:"net_revenue"`
+	Products     []*ProductSales  `json:"products"`
+	TopCustomers []*CustomerSales `json:"top_customers"`
+	Daily        []*DailySales    `json:"daily"`
+}
@@ -0,0 +1,17 @@
+package models
+
+type ExportPage struct {
+	FormatVersion int    `json:"format_version"`
+	EntityType    string `json:"entity_type"`
+	Data          string `json:"data"`
+	Count         int    `json:"count"`
+	Checksum      string `json:"checksum"`
+	Bookmark      string `json:"bookmark"`
+	Done          bool   `json:"done"`
+}
+
+type ImportResult struct {
+	Imported    int    `json:"imported"`
+	Overwritten int    `json:"overwritten"`
+	Checksum    string `json:"checksum"`
+}
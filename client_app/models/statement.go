@@ -0,0 +1,23 @@
+package models
+
+type Movement struct {
+	ID        string `json:"id"`
+	UserID    string `json:"user_id"`
+	Kind      string `json:"kind"`
+	Direction string `json:"direction"`
+	Amount    Money  `json:"amount"`
+	Balance   Money  `json:"balance"`
+	Reference string `json:"reference"`
+	Timestamp string `json:"timestamp"`
+	TxID      string `json:"tx_id"`
+}
+
+type Statement struct {
+	UserID   string      `json:"user_id"`
+	Currency string      `json:"currency"`
+	From     string      `json:"from"`
+	To       string      `json:"to"`
+	Opening  Money       `json:"opening_balance"`
+	Closing  Money       `json:"closing_balance"`
+	Entries  []*Movement `json:"entries"`
+}
@@ -0,0 +1,16 @@
+package models
+
+type UserSummary struct {
+	Channel  string     `json:"channel"`
+	User     *User      `json:"user"`
+	Receipts []*Receipt `json:"receipts"`
+	Products []*Product `json:"products"`
+}
+
+type UserOverview struct {
+	UserID         string         `json:"user_id"`
+	TotalBalance   Balances       `json:"total_balance"`
+	TotalLocked    Balances       `json:"total_locked"`
+	TotalReceipts  int            `json:"total_receipts"`
+	ChannelSummary []*UserSummary `json:"channels"`
+}
@@ -8,13 +8,30 @@ const (
 	MotorcycleParts TraderType = "MOTOPARTS"
 )
 
+type VerificationStatus string
+
+const (
+	VerificationPending   VerificationStatus = "PENDING"
+	VerificationVerified  VerificationStatus = "VERIFIED"
+	VerificationSuspended VerificationStatus = "SUSPENDED"
+)
+
 type Trader struct {
 	ID             string     `json:"id"`
 	TraderType     TraderType `json:"trader_type"`
 	PIB            string     `json:"pib"`
+	Currency       string     `json:"currency"`
 	Products       []string   `json:"products"`
 	Receipts       []string   `json:"receipts"`
-	AccountBalance uint       `json:"account_balance"`
+	AccountBalance Money      `json:"account_balance"`
+	// VerificationStatus is set by the admins, only verified traders sell.
+	VerificationStatus VerificationStatus `json:"verification_status"`
+	// OwnerMSP is the organization whose peers, with those of the
+	// Endorsers, endorse changes to the trader and its products.
+	OwnerMSP  string   `json:"owner_msp"`
+	Endorsers []string `json:"endorsers,omitempty"`
+	Status    string   `json:"status,omitempty"`
+	DeletedAt string   `json:"deleted_at,omitempty"`
 }
 
 func (p Trader) GetID() string {
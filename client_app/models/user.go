@@ -1,12 +1,18 @@
 package models
 
 type User struct {
-	ID             string   `json:"id"`
-	Name           string   `json:"name"`
-	LastName       string   `json:"last_name"`
-	Email          string   `json:"email"`
-	ReceiptsID     []string `json:"receipts_ids"`
-	AccountBalance uint     `json:"account_balance"`
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	LastName       string         `json:"last_name"`
+	Email          string         `json:"email"`
+	ReceiptsID     []string       `json:"receipts_ids"`
+	Balances       Balances       `json:"balances"`
+	LockedBalances Balances       `json:"locked_balances"`
+	Status         string         `json:"status,omitempty"`
+	DeletedAt      string         `json:"deleted_at,omitempty"`
+	Limits         SpendingLimits `json:"limits"`
+	Spending       Spending       `json:"spending"`
+	Freeze         *AccountFreeze `json:"freeze,omitempty"`
 }
 
 func (p User) GetID() string {
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// VALIDATION_ERROR_PREFIX starts the message of the chaincode's validation
+// errors, the JSON list of the broken rules follows it.
+const VALIDATION_ERROR_PREFIX = "validation failed: "
+
+// The entity types the chaincode has validation rules for.
+const (
+	USER_TYPE     string = "USER"
+	PRODUCT_TYPE  string = "PRODUCT"
+	TRADER_TYPE   string = "TRADER"
+	CATEGORY_TYPE string = "CATEGORY"
+)
+
+type Rule struct {
+	Name  string `json:"name"`
+	Param string `json:"param,omitempty"`
+}
+
+// FieldRules are the rules of a field, nested fields are named by their
+// path, such as "price.amount" or "tiers[].unit_price.amount".
+type FieldRules struct {
+	Field string `json:"field"`
+	Rules []Rule `json:"rules"`
+}
+
+type ModelRules struct {
+	Type   string       `json:"type"`
+	Fields []FieldRules `json:"fields"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ParseValidationErrors reads the broken rules from the error of a
+// transaction the chaincode rejected because of its arguments.
+func ParseValidationErrors(err error) ([]FieldError, bool) {
+	if err == nil {
+		return nil, false
+	}
+
+	message := err.Error()
+	start := strings.Index(message, VALIDATION_ERROR_PREFIX)
+	if start < 0 {
+		return nil, false
+	}
+
+	// the peers' responses follow the list, so only the first value is read
+	var errs []FieldError
+	decoder := json.NewDecoder(strings.NewReader(message[start+len(VALIDATION_ERROR_PREFIX):]))
+	if err := decoder.Decode(&errs); err != nil || len(errs) == 0 {
+		return nil, false
+	}
+
+	return errs, true
+}
@@ -58,6 +58,41 @@ func (s *Server) createRoutersAndSetRoutes() error {
 	router.GET("/products/:channel", jwt.AuthorizationMiddleware(models.USER), handler.GetAllProducts)
 	router.POST("/users/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.AddUser)
 	router.POST("/product/buy/:product_id/:channel", jwt.AuthorizationMiddleware(models.USER), handler.BuyProduct)
+	router.GET("/me/overview", jwt.AuthorizationMiddleware(models.USER), handler.GetUserOverview)
+	router.GET("/me/statement", jwt.AuthorizationMiddleware(models.USER), handler.GetStatement)
+	router.POST("/transfer/:from/:to", jwt.AuthorizationMiddleware(models.USER), handler.TransferFunds)
+	router.POST("/transfer/refund/:channel/:lock_id", jwt.AuthorizationMiddleware(models.USER), handler.RefundTransfer)
+	router.POST("/product/reserve/:product_id/:channel", jwt.AuthorizationMiddleware(models.USER), handler.ReserveProduct)
+	router.POST("/reservations/:reservation_id/confirm/:channel", jwt.AuthorizationMiddleware(models.USER), handler.ConfirmReservation)
+	router.POST("/reservations/:reservation_id/release/:channel", jwt.AuthorizationMiddleware(models.USER), handler.ReleaseReservation)
+	router.POST("/exchange-rates/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.SetExchangeRate)
+	router.GET("/traders/:trader_id/sales/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.GetTraderSalesReport)
+	router.PUT("/traders/:trader_id/verification/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.SetTraderVerification)
+	router.PUT("/traders/:trader_id/owner/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.TransferTraderOwnership)
+	router.PUT("/traders/:trader_id/endorsers/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.SetTraderEndorsers)
+	router.POST("/traders/compact/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.CompactTraderDeltas)
+	router.GET("/categories/:channel", jwt.AuthorizationMiddleware(models.USER), handler.GetAllCategories)
+	router.POST("/categories/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.CreateCategory)
+	router.PUT("/categories/:category_id/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.UpdateCategory)
+	router.GET("/validation/rules/:channel", jwt.AuthorizationMiddleware(models.USER), handler.GetValidationRules)
+	router.POST("/products/import/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.ImportProducts)
+	router.GET("/snapshot/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.ExportState)
+	router.POST("/snapshot/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.ImportState)
+	router.POST("/users/:user_id/deposit/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.DepositFunds)
+	router.PUT("/users/:user_id/limits/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.SetSpendingLimits)
+	router.POST("/users/:user_id/freeze/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.FreezeAccount)
+	router.POST("/users/:user_id/unfreeze/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.UnfreezeAccount)
+	router.POST("/receipts/:receipt_id/refund/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.RefundReceipt)
+	router.GET("/receipts/number/:number/:channel", jwt.AuthorizationMiddleware(models.USER), handler.GetReceiptByNumber)
+	router.GET("/traders/:trader_id/receipts/sequence/:year/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.CheckReceiptSequence)
+	router.GET("/audit/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.AuditLedger)
+	router.GET("/journal/trial-balance/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.GetTrialBalance)
+	router.POST("/migrations/:entity_type/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.MigrateState)
+	router.GET("/archive/:entity_type/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.GetArchivedRecords)
+	router.POST("/archive/:entity_type/:id/restore/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.RestoreRecord)
+	router.DELETE("/archive/:entity_type/:id/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.PurgeRecord)
+	router.GET("/integrity/rules/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.GetIntegrityRules)
+	router.PUT("/integrity/rules/:relation/:channel", jwt.AuthorizationMiddleware(models.ADMIN), handler.SetDeleteAction)
 	s.Router = router
 	return nil
 }
@@ -0,0 +1,157 @@
+// Package snapshot reads and writes ledger snapshot files. A snapshot file
+// is NDJSON: a header line, the lines returned by ExportState and a trailer
+// line holding the number of entries and the hex SHA-256 of their lines.
+package snapshot
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+const Format = "ledger-snapshot"
+
+type Header struct {
+	Format        string `json:"format"`
+	FormatVersion int    `json:"format_version"`
+	Channel       string `json:"channel"`
+	ExportedAt    string `json:"exported_at"`
+}
+
+type Trailer struct {
+	Count    int    `json:"count"`
+	Checksum string `json:"checksum"`
+}
+
+// Writer writes a snapshot file and checksums its entries on the way.
+type Writer struct {
+	writer io.Writer
+	hash   hash.Hash
+	count  int
+}
+
+func NewWriter(writer io.Writer, header Header) (*Writer, error) {
+	header.Format = Format
+	if err := writeLine(writer, header); err != nil {
+		return nil, err
+	}
+
+	return &Writer{writer: writer, hash: sha256.New()}, nil
+}
+
+// WriteData writes the entry lines of an export page.
+func (w *Writer) WriteData(data string) error {
+	if data == "" {
+		return nil
+	}
+
+	if !strings.HasSuffix(data, "\n") {
+		data += "\n"
+	}
+
+	w.hash.Write([]byte(data))
+	w.count += strings.Count(data, "\n")
+
+	_, err := io.WriteString(w.writer, data)
+	return err
+}
+
+// Close writes the trailer.
+func (w *Writer) Close() (Trailer, error) {
+	trailer := Trailer{Count: w.count, Checksum: hex.EncodeToString(w.hash.Sum(nil))}
+	return trailer, writeLine(w.writer, trailer)
+}
+
+func writeLine(writer io.Writer, value any) error {
+	line, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(append(line, '\n'))
+	return err
+}
+
+// Read reads a snapshot file and verifies its entries against the checksum
+// of the trailer. It returns the entry lines.
+func Read(reader io.Reader) (Header, []string, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	lines := make([]string, 0)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return Header{}, nil, err
+	}
+
+	if len(lines) < 2 {
+		return Header{}, nil, fmt.Errorf("the snapshot needs a header and a trailer line")
+	}
+
+	var header Header
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil || header.Format != Format {
+		return Header{}, nil, fmt.Errorf("the first line isn't a %s header", Format)
+	}
+
+	var trailer Trailer
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &trailer); err != nil || trailer.Checksum == "" {
+		return Header{}, nil, fmt.Errorf("the snapshot has no trailer, it may be truncated")
+	}
+
+	entries := lines[1 : len(lines)-1]
+	if len(entries) != trailer.Count {
+		return Header{}, nil, fmt.Errorf("the snapshot has %d entries but its trailer counts %d", len(entries), trailer.Count)
+	}
+
+	hash := sha256.New()
+	for _, entry := range entries {
+		hash.Write([]byte(entry + "\n"))
+	}
+
+	if hex.EncodeToString(hash.Sum(nil)) != trailer.Checksum {
+		return Header{}, nil, fmt.Errorf("the snapshot doesn't match its checksum")
+	}
+
+	return header, entries, nil
+}
+
+// Chunk joins the entry lines into the data of ImportState transactions of
+// at most maxLines lines and about maxBytes bytes.
+func Chunk(entries []string, maxLines int, maxBytes int) []string {
+	chunks := make([]string, 0)
+	var chunk strings.Builder
+	lines := 0
+
+	for _, entry := range entries {
+		if lines > 0 && (lines == maxLines || chunk.Len()+len(entry)+1 > maxBytes) {
+			chunks = append(chunks, chunk.String())
+			chunk.Reset()
+			lines = 0
+		}
+
+		chunk.WriteString(entry)
+		chunk.WriteByte('\n')
+		lines++
+	}
+
+	if lines > 0 {
+		chunks = append(chunks, chunk.String())
+	}
+
+	return chunks
+}
+
+func Checksum(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
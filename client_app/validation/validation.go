@@ -0,0 +1,210 @@
+package validation
+
+import (
+	"clientapp/models"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var currencyPattern = regexp.MustCompile("^[A-Z]{3}$")
+
+// Validate checks the model against the rules the chaincode returns from
+// GetValidationRules, so requests it would reject aren't submitted. Rules
+// other than required only apply to values that are set, rules this client
+// doesn't know are left to the chaincode.
+func Validate(rules models.ModelRules, model any) ([]models.FieldError, error) {
+	bytes, err := json.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+
+	var document any
+	if err := json.Unmarshal(bytes, &document); err != nil {
+		return nil, err
+	}
+
+	errs := make([]models.FieldError, 0)
+	for _, field := range rules.Fields {
+		for _, value := range resolve(document, field.Field, "") {
+			for _, rule := range field.Rules {
+				if message := checkRule(rule, value.value); message != "" {
+					errs = append(errs, models.FieldError{Field: value.path, Rule: rule.Name, Message: message})
+				}
+			}
+		}
+	}
+
+	return errs, nil
+}
+
+type fieldValue struct {
+	path  string
+	value any
+}
+
+// resolve finds the values of the field path in the document. A path such as
+// "tiers[].min_quantity" has a value for every tier, named "tiers[0].min_quantity".
+func resolve(document any, path string, prefix string) []fieldValue {
+	name, rest, nested := strings.Cut(path, ".")
+	object, _ := document.(map[string]any)
+
+	if list, ok := strings.CutSuffix(name, "[]"); ok {
+		items, _ := object[list].([]any)
+		values := make([]fieldValue, 0)
+		for i, item := range items {
+			values = append(values, resolve(item, rest, fmt.Sprintf("%s%s[%d].", prefix, list, i))...)
+		}
+		return values
+	}
+
+	if nested {
+		if _, ok := object[name].(map[string]any); !ok {
+			return nil
+		}
+		return resolve(object[name], rest, prefix+name+".")
+	}
+
+	return []fieldValue{{path: prefix + name, value: object[name]}}
+}
+
+func checkRule(rule models.Rule, value any) string {
+	if rule.Name == "required" {
+		if isBlank(value) {
+			return "is required"
+		}
+		return ""
+	}
+
+	if isZero(value) {
+		return ""
+	}
+
+	switch rule.Name {
+	case "email":
+		text := fmt.Sprint(value)
+		address, err := mail.ParseAddress(text)
+		if err != nil || address.Address != text {
+			return "must be an e-mail address"
+		}
+	case "min", "max":
+		return checkBound(rule, value)
+	case "oneof":
+		if !slices.Contains(strings.Fields(rule.Param), fmt.Sprint(value)) {
+			return fmt.Sprintf("must be one of %s", strings.Join(strings.Fields(rule.Param), ", "))
+		}
+	case "currency":
+		if !currencyPattern.MatchString(fmt.Sprint(value)) {
+			return "must be an ISO 4217 currency code"
+		}
+	case "pib":
+		if !validPIB(fmt.Sprint(value)) {
+			return "must be a valid PIB"
+		}
+	}
+
+	return ""
+}
+
+// isBlank reports whether a required value is missing. Money is missing
+// when its amount is zero, as in the chaincode.
+func isBlank(value any) bool {
+	if object, ok := value.(map[string]any); ok {
+		if amount, ok := object["amount"]; ok {
+			if _, ok := object["currency"]; ok {
+				return isZero(amount)
+			}
+		}
+	}
+
+	if text, ok := value.(string); ok {
+		return strings.TrimSpace(text) == ""
+	}
+
+	return isZero(value)
+}
+
+func isZero(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case float64:
+		return v == 0
+	case bool:
+		return !v
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	}
+
+	return false
+}
+
+func checkBound(rule models.Rule, value any) string {
+	bound, err := strconv.ParseFloat(rule.Param, 64)
+	if err != nil {
+		return ""
+	}
+
+	var actual float64
+	unit := ""
+	switch v := value.(type) {
+	case string:
+		actual = float64(utf8.RuneCountInString(v))
+		unit = " characters"
+	case []any:
+		actual = float64(len(v))
+		unit = " items"
+	case map[string]any:
+		actual = float64(len(v))
+		unit = " items"
+	case float64:
+		actual = v
+	default:
+		return ""
+	}
+
+	if rule.Name == "min" && actual < bound {
+		return fmt.Sprintf("must be at least %s%s", rule.Param, unit)
+	}
+
+	if rule.Name == "max" && actual > bound {
+		return fmt.Sprintf("must be at most %s%s", rule.Param, unit)
+	}
+
+	return ""
+}
+
+// validPIB checks the length and the ISO 7064 MOD 11,10 check digit of a PIB.
+func validPIB(pib string) bool {
+	if len(pib) != 9 {
+		return false
+	}
+
+	product := 10
+	for i, digit := range pib {
+		if digit < '0' || digit > '9' {
+			return false
+		}
+
+		if i == len(pib)-1 {
+			return (11-product)%10 == int(digit-'0')
+		}
+
+		sum := (int(digit-'0') + product) % 10
+		if sum == 0 {
+			sum = 10
+		}
+		product = (2 * sum) % 11
+	}
+
+	return false
+}
@@ -65,46 +65,50 @@ sleep 1
 
 
 infoln "Testing users"
-invoke_function DeleteUser raw u1
-USER_JSON='{"id":"u1","name":"Alice","last_name":"Alicee","email":"a@gmail.com","receipts_ids":[],"account_balance":100}'
-invoke_function CreateUser json "$USER_JSON"
-query_function ReadUser u1
+invoke_function UserContract:DeleteUser raw u1
+USER_JSON='{"id":"u1","name":"Alice","last_name":"Alicee","email":"a@gmail.com","receipts_ids":[],"balances":{"RSD":{"amount":10000,"currency":"RSD"}}}'
+invoke_function UserContract:CreateUser json "$USER_JSON"
+query_function UserContract:ReadUser u1
 
-query_function GetAllUsers
+query_function UserContract:GetAllUsers
 
 
 infoln "Testing rich queries"
 QUERY_JSON='{"price":"2"}'
-invoke_function QueryProducts json "$QUERY_JSON"  
+invoke_function ProductContract:QueryProducts json "$QUERY_JSON"  
 
-query_function GetUsersGTEBalance 100
+invoke_function UserContract:GetUsersGTEBalance json '{"amount":10000,"currency":"RSD"}'
 query_function SearchUsersByLastName Alicee
 query_function SearchUsersByName Alice
 
 
 infoln "Testing products"
-invoke_function DeleteProduct raw pppp1
-PRODUCT_JSON='{"id":"pppp1","name":"p1","expiration_date":"", "price":2,"quantity":2,"trader_id":"tt1"}'
-invoke_function CreateProduct json "$PRODUCT_JSON"
-query_function ReadProduct pppp1
+invoke_function ProductContract:DeleteProduct raw pppp1
+PRODUCT_JSON='{"id":"pppp1","name":"p1","expiration_date":"", "price":{"amount":200,"currency":"RSD"},"quantity":2,"trader_id":"tt1"}'
+invoke_function ProductContract:CreateProduct json "$PRODUCT_JSON"
+query_function ProductContract:ReadProduct pppp1
 
-invoke_function BuyProduct raw pppp1 u1
-query_function GetAllProducts
-query_function ReadUser u1
+invoke_function ProductContract:BuyProduct raw pppp1 u1
+invoke_function ProductContract:BuyProductWithCurrency raw sw1 u1 RSD
+invoke_function ReserveProduct raw rr1 pppp1 u1 1
+query_function ProductContract:ReadProduct pppp1
+invoke_function ReleaseReservation raw rr1
+query_function ProductContract:GetAllProducts
+query_function UserContract:ReadUser u1
 
 
 
 infoln "Testing traders"
-invoke_function DeleteTrader raw tt111
+invoke_function TraderContract:DeleteTrader raw tt111
 
-TRADER_JSON='{"id":"tt111","trader_type":"MARKET","pib":"pppiiiibbb1","products":["br1"],"receipts":[],"account_balance":100}'
-invoke_function CreateTrader json "$TRADER_JSON"
+TRADER_JSON='{"id":"tt111","trader_type":"MARKET","pib":"100000032","currency":"RSD","products":["br1"],"receipts":[],"account_balance":{"amount":10000,"currency":"RSD"}}'
+invoke_function TraderContract:CreateTrader json "$TRADER_JSON"
 
-query_function GetAllTraders
-query_function ReadTrader tt111
+query_function TraderContract:GetAllTraders
+query_function TraderContract:ReadTrader tt111
 
 infoln "Testing receipts"
-query_function GetAllReceips
+query_function ReceiptContract:GetAllReceips
  
 successln "Success: $successNum"
 warnln "Failed: $failNum"
\ No newline at end of file